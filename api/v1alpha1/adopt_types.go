@@ -0,0 +1,14 @@
+package v1alpha1
+
+// AdoptSpec selects an existing PagerDuty object the controller takes ownership of instead of creating a new one.
+// The adopted object is then reconciled to the spec.
+// +kubebuilder:validation:MinProperties=1
+// +kubebuilder:validation:MaxProperties=1
+type AdoptSpec struct {
+	// ID of the upstream object to adopt. The resource fails to become ready if no object has this ID.
+	ID string `json:"id,omitempty"`
+
+	// ByName adopts the upstream object with the same name as the spec, if there is one.
+	// A new object is created when none matches, and several matches are an error.
+	ByName bool `json:"by_name,omitempty"`
+}
@@ -0,0 +1,113 @@
+package v1alpha1
+
+import (
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"gitlab.share-now.com/platform/pagerduty-operator/api/v1beta1"
+)
+
+// ConvertTo converts the Business Service to the v1beta1 hub version
+func (src *BusinessService) ConvertTo(hub conversion.Hub) error {
+	dst := hub.(*v1beta1.BusinessService)
+
+	dst.ObjectMeta = src.ObjectMeta
+
+	var supportingServices []v1beta1.SupportingServiceReference
+	for _, ref := range src.Spec.SupportingServices {
+		// v1alpha1 references other namespaces with namespace/name
+		namespace, name, found := strings.Cut(ref.Name, "/")
+		if !found {
+			namespace, name = "", ref.Name
+		}
+		supportingServices = append(supportingServices, v1beta1.SupportingServiceReference{Kind: ref.Kind, Namespace: namespace, Name: name})
+	}
+
+	dst.Spec = v1beta1.BusinessServiceSpec{
+		Name:               src.Spec.Name,
+		Description:        src.Spec.Description,
+		PointOfContact:     src.Spec.PointOfContact,
+		Team:               teamReferenceTo(src.Spec.TeamID, src.Spec.TeamRef),
+		AccountRef:         localRefTo(src.Spec.AccountRef),
+		SupportingServices: supportingServices,
+		Adopt:              adoptTo(src.Spec.Adopt),
+		DeletionPolicy:     v1beta1.DeletionPolicy(src.Spec.DeletionPolicy),
+	}
+
+	var dependencies []v1beta1.ServiceDependency
+	for _, dependency := range src.Status.ServiceDependencies {
+		dependencies = append(dependencies, v1beta1.ServiceDependency{
+			ID:                    dependency.ID,
+			SupportingServiceID:   dependency.SupportingServiceID,
+			SupportingServiceType: dependency.SupportingServiceType,
+		})
+	}
+
+	businessServiceID := src.Status.BusinessServiceID
+	if businessServiceID == "" {
+		businessServiceID = src.Status.LegacyBusinessServiceID
+	}
+
+	dst.Status = v1beta1.BusinessServiceStatus{
+		BusinessServiceID:    businessServiceID,
+		PointOfContactUserID: src.Status.PointOfContactUserID,
+		ServiceDependencies:  dependencies,
+		ObservedGeneration:   src.Status.ObservedGeneration,
+		HTMLURL:              src.Status.HTMLURL,
+		Upstream:             upstreamTo(src.Status.Upstream),
+		LastSyncedTime:       src.Status.LastSyncedTime,
+		Conditions:           src.Status.Conditions,
+	}
+	return nil
+}
+
+// ConvertFrom converts the Business Service from the v1beta1 hub version
+func (dst *BusinessService) ConvertFrom(hub conversion.Hub) error {
+	src := hub.(*v1beta1.BusinessService)
+
+	dst.ObjectMeta = src.ObjectMeta
+
+	var supportingServices []SupportingServiceReference
+	for _, ref := range src.Spec.SupportingServices {
+		name := ref.Name
+		if ref.Namespace != "" {
+			name = ref.Namespace + "/" + ref.Name
+		}
+		supportingServices = append(supportingServices, SupportingServiceReference{Kind: ref.Kind, Name: name})
+	}
+
+	teamID, teamRef := teamReferenceFrom(src.Spec.Team)
+	dst.Spec = BusinessServiceSpec{
+		Name:               src.Spec.Name,
+		Description:        src.Spec.Description,
+		PointOfContact:     src.Spec.PointOfContact,
+		TeamID:             teamID,
+		TeamRef:            teamRef,
+		AccountRef:         localRefFrom(src.Spec.AccountRef),
+		SupportingServices: supportingServices,
+		Adopt:              adoptFrom(src.Spec.Adopt),
+		DeletionPolicy:     DeletionPolicy(src.Spec.DeletionPolicy),
+	}
+
+	var dependencies []ServiceDependency
+	for _, dependency := range src.Status.ServiceDependencies {
+		dependencies = append(dependencies, ServiceDependency{
+			ID:                    dependency.ID,
+			SupportingServiceID:   dependency.SupportingServiceID,
+			SupportingServiceType: dependency.SupportingServiceType,
+		})
+	}
+
+	dst.Status = BusinessServiceStatus{
+		BusinessServiceID:    src.Status.BusinessServiceID,
+		PointOfContactUserID: src.Status.PointOfContactUserID,
+		ServiceDependencies:  dependencies,
+		ObservedGeneration:   src.Status.ObservedGeneration,
+		HTMLURL:              src.Status.HTMLURL,
+		Upstream:             upstreamFrom(src.Status.Upstream),
+		LastSyncedTime:       src.Status.LastSyncedTime,
+		Conditions:           src.Status.Conditions,
+	}
+	return nil
+}
@@ -20,6 +20,39 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+const (
+	// SupportingServiceKindPagerdutyService references a PagerdutyService as supporting service
+	SupportingServiceKindPagerdutyService = "PagerdutyService"
+	// SupportingServiceKindBusinessService references a BusinessService as supporting service
+	SupportingServiceKindBusinessService = "BusinessService"
+)
+
+// SupportingServiceReference references a PagerdutyService or BusinessService the Business Service depends on
+type SupportingServiceReference struct {
+	// Kind of the referenced resource
+	// +kubebuilder:validation:Enum=PagerdutyService;BusinessService
+	// +kubebuilder:default=PagerdutyService
+	Kind string `json:"kind,omitempty"`
+
+	// Name of the referenced resource, in the form name or namespace/name.
+	// The namespace of the Business Service is used when omitted.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// ServiceDependency is a dependency of the Business Service observed in PagerDuty
+type ServiceDependency struct {
+	// ID of the service dependency
+	ID string `json:"id,omitempty"`
+
+	// SupportingServiceID is the ID of the service the Business Service depends on
+	SupportingServiceID string `json:"supporting_service_id"`
+
+	// SupportingServiceType is either service or business_service
+	SupportingServiceType string `json:"supporting_service_type"`
+}
+
 // BusinessServiceSpec defines the desired state of BusinessService
 type BusinessServiceSpec struct {
 
@@ -34,6 +67,7 @@ type BusinessServiceSpec struct {
 	Description string `json:"description,omitempty"`
 
 	// PointOfContact defines the owner of the Business Service.
+	// A PagerDuty user can be referenced by email with email:<address>, in which case the user ID is sent upstream.
 	// +operator-sdk:csv:customresourcedefinitions:type=spec
 	// +kubebuilder:default=""
 	PointOfContact string `json:"point_of_contact,omitempty"`
@@ -42,13 +76,68 @@ type BusinessServiceSpec struct {
 	// +operator-sdk:csv:customresourcedefinitions:type=spec
 	// +kubebuilder:default=""
 	TeamID string `json:"team,omitempty"`
+
+	// TeamRef references the Team that owns the Business Service by the name of its Team custom resource
+	// in the same namespace. It takes precedence over TeamID.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:default=""
+	TeamRef string `json:"team_ref,omitempty"`
+
+	// AccountRef defines the name of the PagerDutyAccount used to manage the Business Service.
+	// The operator's default API token is used when empty.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:default=""
+	AccountRef string `json:"account_ref,omitempty"`
+
+	// SupportingServices defines the services the Business Service depends on.
+	// Each entry becomes a service dependency in PagerDuty.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	SupportingServices []SupportingServiceReference `json:"supporting_services,omitempty"`
+
+	// Adopt takes ownership of an existing business service instead of creating a new one
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Adopt *AdoptSpec `json:"adopt,omitempty"`
+
+	// DeletionPolicy defines what happens to the upstream business service when the resource is deleted.
+	// Delete removes it, Orphan leaves it untouched and Retain leaves it renamed with a "(retained)" suffix.
+	// The operator's --default-deletion-policy applies when empty.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	DeletionPolicy DeletionPolicy `json:"deletion_policy,omitempty"`
 }
 
 // BusinessServiceStatus defines the observed state of BusinessService
 type BusinessServiceStatus struct {
 	// +operator-sdk:csv:customresourcedefinitions:type=status
 	// BusinessServiceID stores the ID of the Business Service
-	BusinessServiceID string `json:"business_service_id ,omitempty"`
+	BusinessServiceID string `json:"business_service_id,omitempty"`
+
+	// LegacyBusinessServiceID holds the ID written by operator versions whose json tag had a trailing space.
+	// It is moved to BusinessServiceID by the next reconcile, and never written.
+	LegacyBusinessServiceID string `json:"business_service_id ,omitempty"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// PointOfContactUserID stores the ID of the PagerDuty user referenced by email in the point of contact
+	PointOfContactUserID string `json:"point_of_contact_user_id,omitempty"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// ServiceDependencies stores the service dependencies of the Business Service observed in PagerDuty
+	ServiceDependencies []ServiceDependency `json:"service_dependencies,omitempty"`
+
+	// ObservedGeneration is the generation of the spec last reconciled with upstream
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	ObservedGeneration int64 `json:"observed_generation,omitempty"`
+
+	// HTMLURL is the address of the upstream business service in the PagerDuty web UI
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	HTMLURL string `json:"html_url,omitempty"`
+
+	// Upstream stores the managed fields of the upstream business service, as last read from PagerDuty
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	Upstream *UpstreamSnapshot `json:"upstream,omitempty"`
+
+	// LastSyncedTime stores when the spec was last successfully compared with the upstream business service
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	LastSyncedTime *metav1.Time `json:"last_synced_time,omitempty"`
 
 	//	Conditions stores the conditions of the Business Service
 	Conditions []metav1.Condition `json:"conditions"`
@@ -56,6 +145,10 @@ type BusinessServiceStatus struct {
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="ID",type=string,JSONPath=`.status.business_service_id`
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+//+kubebuilder:printcolumn:name="URL",type=string,JSONPath=`.status.html_url`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // BusinessService is the Schema for the businessservices API
 type BusinessService struct {
@@ -8,8 +8,23 @@ const (
 	ConditionReady ConditionType = "Ready"
 	// ConditionPending is set when a pagerduty custom resource is still waiting for the API calls to finish
 	ConditionPending ConditionType = "Pending"
-	// ConditionError is set when a pagerduty custom resource is unable to complete the API calls
+	// ConditionError is set when a pagerduty custom resource is unable to complete the API calls, with the kind of error as reason.
+	// It is cleared once the API calls succeed again.
 	ConditionError ConditionType = "Error"
+	// ConditionCredentialsValid is set to false when the PagerDuty API token is missing or rejected by the API
+	ConditionCredentialsValid ConditionType = "CredentialsValid"
+	// ConditionDeletionBlocked is set when a pagerduty custom resource cannot be deleted because other resources still reference it,
+	// or because it is paused and its deletion policy would change upstream
+	ConditionDeletionBlocked ConditionType = "DeletionBlocked"
+	// ConditionSynced is set when a pagerduty custom resource was successfully compared with its upstream object
+	ConditionSynced ConditionType = "Synced"
+	// ConditionDriftDetected is set in observe mode when the spec of a pagerduty custom resource differs from its upstream object
+	ConditionDriftDetected ConditionType = "DriftDetected"
+	// ConditionPaused is set when the reconciliation of a pagerduty custom resource is paused by the pause annotation
+	ConditionPaused ConditionType = "Paused"
+	// ConditionInvalidSpec is set when PagerDuty rejects the spec of a pagerduty custom resource as invalid.
+	// The resource is not reconciled again until its spec changes.
+	ConditionInvalidSpec ConditionType = "InvalidSpec"
 )
 
 func (c ConditionType) String() string {
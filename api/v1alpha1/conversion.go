@@ -0,0 +1,91 @@
+package v1alpha1
+
+import (
+	"gitlab.share-now.com/platform/pagerduty-operator/api/v1beta1"
+)
+
+// The v1alpha1 types convert to and from v1beta1, the storage version of the API.
+// Fields without a v1alpha1 counterpart are kept in annotations, so that a round trip does not lose them.
+
+// TeamsAnnotation stores the teams of a v1beta1 Escalation Policy that v1alpha1, with a single team, cannot hold
+const TeamsAnnotation = "pagerduty.platform.share-now.com/v1beta1-teams"
+
+func localRefTo(account string) *v1beta1.LocalObjectReference {
+	if account == "" {
+		return nil
+	}
+	return &v1beta1.LocalObjectReference{Name: account}
+}
+
+func localRefFrom(ref *v1beta1.LocalObjectReference) string {
+	if ref == nil {
+		return ""
+	}
+	return ref.Name
+}
+
+func teamReferenceTo(id, name string) *v1beta1.TeamReference {
+	if id == "" && name == "" {
+		return nil
+	}
+	return &v1beta1.TeamReference{ID: id, Name: name}
+}
+
+func teamReferenceFrom(ref *v1beta1.TeamReference) (id, name string) {
+	if ref == nil {
+		return "", ""
+	}
+	return ref.ID, ref.Name
+}
+
+func adoptTo(adopt *AdoptSpec) *v1beta1.AdoptSpec {
+	if adopt == nil {
+		return nil
+	}
+	return &v1beta1.AdoptSpec{ID: adopt.ID, ByName: adopt.ByName}
+}
+
+func adoptFrom(adopt *v1beta1.AdoptSpec) *AdoptSpec {
+	if adopt == nil {
+		return nil
+	}
+	return &AdoptSpec{ID: adopt.ID, ByName: adopt.ByName}
+}
+
+func upstreamTo(upstream *UpstreamSnapshot) *v1beta1.UpstreamSnapshot {
+	if upstream == nil {
+		return nil
+	}
+	return &v1beta1.UpstreamSnapshot{Name: upstream.Name, Description: upstream.Description, Fields: upstream.Fields}
+}
+
+func upstreamFrom(upstream *v1beta1.UpstreamSnapshot) *UpstreamSnapshot {
+	if upstream == nil {
+		return nil
+	}
+	return &UpstreamSnapshot{Name: upstream.Name, Description: upstream.Description, Fields: upstream.Fields}
+}
+
+// withoutAnnotation returns a copy of the annotations without key, so the source object is left untouched
+func withoutAnnotation(annotations map[string]string, key string) map[string]string {
+	if _, found := annotations[key]; !found {
+		return annotations
+	}
+	out := make(map[string]string, len(annotations)-1)
+	for k, v := range annotations {
+		if k != key {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// withAnnotation returns a copy of the annotations with key set to value, so the source object is left untouched
+func withAnnotation(annotations map[string]string, key, value string) map[string]string {
+	out := make(map[string]string, len(annotations)+1)
+	for k, v := range annotations {
+		out[k] = v
+	}
+	out[key] = value
+	return out
+}
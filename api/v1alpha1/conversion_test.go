@@ -0,0 +1,194 @@
+package v1alpha1
+
+import (
+	"strings"
+
+	"github.com/google/go-cmp/cmp/cmpopts"
+	fuzz "github.com/google/gofuzz"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+	"sigs.k8s.io/yaml"
+
+	"gitlab.share-now.com/platform/pagerduty-operator/api/v1beta1"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/typeinfo"
+)
+
+const fuzzIterations = 200
+
+// newFuzzer returns a fuzzer filling the objects with values the API server accepts
+func newFuzzer() *fuzz.Fuzzer {
+	return fuzz.New().NilChance(0.2).NumElements(0, 3).Funcs(
+		// the conversion only receives the type meta of the target version
+		func(meta *metav1.TypeMeta, c fuzz.Continue) {},
+		func(meta *metav1.ObjectMeta, c fuzz.Continue) {
+			meta.Name = c.RandString()
+			meta.Namespace = c.RandString()
+			meta.Generation = c.Int63()
+			c.Fuzz(&meta.Labels)
+			c.Fuzz(&meta.Annotations)
+			delete(meta.Annotations, TeamsAnnotation)
+		},
+		func(t *metav1.Time, c fuzz.Continue) {
+			*t = metav1.Unix(c.Int63n(1<<32), 0)
+		},
+		func(status *BusinessServiceStatus, c fuzz.Continue) {
+			c.FuzzNoCustom(status)
+			// the legacy ID only exists in v1alpha1, it is folded into the business service ID
+			status.LegacyBusinessServiceID = ""
+		},
+		func(ref *SupportingServiceReference, c fuzz.Continue) {
+			ref.Kind = c.RandString()
+			ref.Name = strings.ReplaceAll(c.RandString(), "/", "")
+			if c.RandBool() {
+				ref.Name = "ns-" + strings.ReplaceAll(c.RandString(), "/", "") + "/" + ref.Name
+			}
+		},
+		func(ref *v1beta1.SupportingServiceReference, c fuzz.Continue) {
+			c.FuzzNoCustom(ref)
+			ref.Namespace = strings.ReplaceAll(ref.Namespace, "/", "")
+			ref.Name = strings.ReplaceAll(ref.Name, "/", "")
+		},
+		func(ref *v1beta1.LocalObjectReference, c fuzz.Continue) {
+			ref.Name = "ref-" + c.RandString()
+		},
+		func(ref *v1beta1.TeamReference, c fuzz.Continue) {
+			c.FuzzNoCustom(ref)
+			if ref.ID == "" && ref.Name == "" {
+				ref.Name = "team-" + c.RandString()
+			}
+		},
+	)
+}
+
+var _ = Describe("Conversion", func() {
+	fuzzer := newFuzzer()
+
+	DescribeTable("round trips from v1alpha1 through v1beta1",
+		func(newSpoke func() conversion.Convertible, newHub func() conversion.Hub) {
+			for i := 0; i < fuzzIterations; i++ {
+				original := newSpoke()
+				fuzzer.Fuzz(original)
+
+				hub := newHub()
+				Expect(original.DeepCopyObject().(conversion.Convertible).ConvertTo(hub)).To(Succeed())
+				converted := newSpoke()
+				Expect(converted.ConvertFrom(hub)).To(Succeed())
+
+				Expect(converted).To(BeComparableTo(original, cmpopts.EquateEmpty()))
+			}
+		},
+		Entry("EscalationPolicy", func() conversion.Convertible { return &EscalationPolicy{} }, func() conversion.Hub { return &v1beta1.EscalationPolicy{} }),
+		Entry("PagerdutyService", func() conversion.Convertible { return &PagerdutyService{} }, func() conversion.Hub { return &v1beta1.PagerdutyService{} }),
+		Entry("BusinessService", func() conversion.Convertible { return &BusinessService{} }, func() conversion.Hub { return &v1beta1.BusinessService{} }),
+		Entry("Schedule", func() conversion.Convertible { return &Schedule{} }, func() conversion.Hub { return &v1beta1.Schedule{} }),
+		Entry("Team", func() conversion.Convertible { return &Team{} }, func() conversion.Hub { return &v1beta1.Team{} }),
+		Entry("PagerDutyAccount", func() conversion.Convertible { return &PagerDutyAccount{} }, func() conversion.Hub { return &v1beta1.PagerDutyAccount{} }),
+	)
+
+	DescribeTable("round trips from v1beta1 through v1alpha1",
+		func(newSpoke func() conversion.Convertible, newHub func() conversion.Hub) {
+			for i := 0; i < fuzzIterations; i++ {
+				original := newHub()
+				fuzzer.Fuzz(original)
+
+				spoke := newSpoke()
+				Expect(spoke.ConvertFrom(original.DeepCopyObject().(conversion.Hub))).To(Succeed())
+				converted := newHub()
+				Expect(spoke.ConvertTo(converted)).To(Succeed())
+
+				Expect(converted).To(BeComparableTo(original, cmpopts.EquateEmpty()))
+			}
+		},
+		Entry("EscalationPolicy", func() conversion.Convertible { return &EscalationPolicy{} }, func() conversion.Hub { return &v1beta1.EscalationPolicy{} }),
+		Entry("PagerdutyService", func() conversion.Convertible { return &PagerdutyService{} }, func() conversion.Hub { return &v1beta1.PagerdutyService{} }),
+		Entry("BusinessService", func() conversion.Convertible { return &BusinessService{} }, func() conversion.Hub { return &v1beta1.BusinessService{} }),
+		Entry("Schedule", func() conversion.Convertible { return &Schedule{} }, func() conversion.Hub { return &v1beta1.Schedule{} }),
+		Entry("Team", func() conversion.Convertible { return &Team{} }, func() conversion.Hub { return &v1beta1.Team{} }),
+		Entry("PagerDutyAccount", func() conversion.Convertible { return &PagerDutyAccount{} }, func() conversion.Hub { return &v1beta1.PagerDutyAccount{} }),
+	)
+
+	Context("EscalationPolicy teams", func() {
+		It("converts the team and team_ref to a single team reference", func() {
+			policy := &EscalationPolicy{Spec: EscalationPolicySpec{Team: typeinfo.TeamID("PTEAM01"), TeamRef: "platform"}}
+
+			hub := &v1beta1.EscalationPolicy{}
+			Expect(policy.ConvertTo(hub)).To(Succeed())
+			Expect(hub.Spec.Teams).To(Equal([]v1beta1.TeamReference{{ID: "PTEAM01", Name: "platform"}}))
+		})
+
+		It("keeps the other teams in an annotation", func() {
+			hub := &v1beta1.EscalationPolicy{Spec: v1beta1.EscalationPolicySpec{Teams: []v1beta1.TeamReference{{ID: "PTEAM01"}, {Name: "platform"}}}}
+
+			policy := &EscalationPolicy{}
+			Expect(policy.ConvertFrom(hub)).To(Succeed())
+			Expect(policy.Spec.Team).To(Equal(typeinfo.TeamID("PTEAM01")))
+			Expect(policy.Spec.TeamRef).To(BeEmpty())
+			Expect(policy.Annotations).To(HaveKey(TeamsAnnotation))
+			Expect(hub.Annotations).To(BeEmpty())
+
+			converted := &v1beta1.EscalationPolicy{}
+			Expect(policy.ConvertTo(converted)).To(Succeed())
+			Expect(converted.Spec.Teams).To(Equal(hub.Spec.Teams))
+			Expect(converted.Annotations).NotTo(HaveKey(TeamsAnnotation))
+		})
+
+		It("drops the annotation when the team is changed in v1alpha1", func() {
+			hub := &v1beta1.EscalationPolicy{Spec: v1beta1.EscalationPolicySpec{Teams: []v1beta1.TeamReference{{ID: "PTEAM01"}, {Name: "platform"}}}}
+
+			policy := &EscalationPolicy{}
+			Expect(policy.ConvertFrom(hub)).To(Succeed())
+			policy.Spec.Team = "PTEAM02"
+
+			converted := &v1beta1.EscalationPolicy{}
+			Expect(policy.ConvertTo(converted)).To(Succeed())
+			Expect(converted.Spec.Teams).To(Equal([]v1beta1.TeamReference{{ID: "PTEAM02"}}))
+			Expect(converted.Annotations).NotTo(HaveKey(TeamsAnnotation))
+		})
+	})
+
+	Context("EscalationPolicy targets", func() {
+		It("migrates the bare user IDs stored by older versions", func() {
+			policy := &EscalationPolicy{}
+			Expect(yaml.Unmarshal([]byte(`
+spec:
+  name: Platform
+  escalation_rules:
+    - escalation_delay_in_minutes: 10
+      targets: [P1NKFZC, {schedule_ref: platform-on-call}]
+`), policy)).To(Succeed())
+
+			hub := &v1beta1.EscalationPolicy{}
+			Expect(policy.ConvertTo(hub)).To(Succeed())
+			Expect(hub.Spec.EscalationRules[0].Targets).To(Equal([]v1beta1.EscalationTarget{
+				{UserID: "P1NKFZC"},
+				{ScheduleRef: &v1beta1.LocalObjectReference{Name: "platform-on-call"}},
+			}))
+		})
+	})
+
+	Context("BusinessService", func() {
+		It("splits the namespace of the supporting services", func() {
+			businessService := &BusinessService{Spec: BusinessServiceSpec{SupportingServices: []SupportingServiceReference{
+				{Kind: SupportingServiceKindPagerdutyService, Name: "checkout"},
+				{Kind: SupportingServiceKindBusinessService, Name: "payments/billing"},
+			}}}
+
+			hub := &v1beta1.BusinessService{}
+			Expect(businessService.ConvertTo(hub)).To(Succeed())
+			Expect(hub.Spec.SupportingServices).To(Equal([]v1beta1.SupportingServiceReference{
+				{Kind: SupportingServiceKindPagerdutyService, Name: "checkout"},
+				{Kind: SupportingServiceKindBusinessService, Namespace: "payments", Name: "billing"},
+			}))
+		})
+
+		It("migrates the legacy business service ID", func() {
+			businessService := &BusinessService{Status: BusinessServiceStatus{LegacyBusinessServiceID: "PBS0001"}}
+
+			hub := &v1beta1.BusinessService{}
+			Expect(businessService.ConvertTo(hub)).To(Succeed())
+			Expect(hub.Status.BusinessServiceID).To(Equal("PBS0001"))
+		})
+	})
+})
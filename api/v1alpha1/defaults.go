@@ -0,0 +1,64 @@
+package v1alpha1
+
+// Defaults of the spec fields, also declared by the kubebuilder:default markers of the fields.
+const (
+	DefaultOnCallHandoffNotifications      = "if_has_services"
+	DefaultServiceStatus                   = "active"
+	DefaultAlertCreation                   = "create_incidents"
+	DefaultAutoResolveTimeout         uint = 14400
+	DefaultAcknowledgementTimeout     uint = 1800
+	DefaultIntegrationType                 = IntegrationTypeEventsAPIV2
+	DefaultIntegrationSecretKey            = "integration_key"
+	DefaultSupportingServiceKind           = SupportingServiceKindPagerdutyService
+)
+
+// Default sets the defaults of the Escalation Policy spec, the same way the CRD schema does
+func (spec *EscalationPolicySpec) Default() {
+	if spec.OnCallHandoffNotifications == "" {
+		spec.OnCallHandoffNotifications = DefaultOnCallHandoffNotifications
+	}
+	if spec.NumLoops == 0 {
+		// num_loops is omitted when 0, so the schema always turns it into its default of 1
+		spec.NumLoops = 1
+	}
+}
+
+// Default sets the defaults of the PagerDuty service spec, the same way the CRD schema does
+func (spec *PagerdutyServiceSpec) Default() {
+	if spec.Status == "" {
+		spec.Status = DefaultServiceStatus
+	}
+	if spec.AlertCreation == "" {
+		spec.AlertCreation = DefaultAlertCreation
+	}
+	if spec.AutoResolveTimeout == nil {
+		timeout := DefaultAutoResolveTimeout
+		spec.AutoResolveTimeout = &timeout
+	}
+	if spec.AcknowledgementTimeout == nil {
+		timeout := DefaultAcknowledgementTimeout
+		spec.AcknowledgementTimeout = &timeout
+	}
+	for i := range spec.Integrations {
+		spec.Integrations[i].Default()
+	}
+}
+
+// Default sets the defaults of the integration, the same way the CRD schema does
+func (integration *ServiceIntegration) Default() {
+	if integration.Type == "" {
+		integration.Type = DefaultIntegrationType
+	}
+	if integration.SecretKey == "" {
+		integration.SecretKey = DefaultIntegrationSecretKey
+	}
+}
+
+// Default sets the defaults of the Business Service spec, the same way the CRD schema does
+func (spec *BusinessServiceSpec) Default() {
+	for i := range spec.SupportingServices {
+		if spec.SupportingServices[i].Kind == "" {
+			spec.SupportingServices[i].Kind = DefaultSupportingServiceKind
+		}
+	}
+}
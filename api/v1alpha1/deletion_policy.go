@@ -0,0 +1,34 @@
+package v1alpha1
+
+// DeletionPolicy defines what happens to the upstream PagerDuty object when its custom resource is deleted
+// +kubebuilder:validation:Enum=Delete;Orphan;Retain
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyDelete deletes the upstream object
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+	// DeletionPolicyOrphan leaves the upstream object untouched
+	DeletionPolicyOrphan DeletionPolicy = "Orphan"
+	// DeletionPolicyRetain leaves the upstream object renamed with a "(retained)" suffix, so it can be told apart
+	DeletionPolicyRetain DeletionPolicy = "Retain"
+)
+
+// IsValid returns true for the known deletion policies
+func (p DeletionPolicy) IsValid() bool {
+	switch p {
+	case DeletionPolicyDelete, DeletionPolicyOrphan, DeletionPolicyRetain:
+		return true
+	}
+	return false
+}
+
+// OrDefault returns the deletion policy, or defaultPolicy when it is not set. Delete applies when neither is set.
+func (p DeletionPolicy) OrDefault(defaultPolicy DeletionPolicy) DeletionPolicy {
+	if p != "" {
+		return p
+	}
+	if defaultPolicy != "" {
+		return defaultPolicy
+	}
+	return DeletionPolicyDelete
+}
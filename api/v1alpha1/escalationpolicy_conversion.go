@@ -0,0 +1,126 @@
+package v1alpha1
+
+import (
+	"encoding/json"
+
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"gitlab.share-now.com/platform/pagerduty-operator/api/v1beta1"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/typeinfo"
+)
+
+// ConvertTo converts the Escalation Policy to the v1beta1 hub version
+func (src *EscalationPolicy) ConvertTo(hub conversion.Hub) error {
+	dst := hub.(*v1beta1.EscalationPolicy)
+
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Annotations = withoutAnnotation(src.Annotations, TeamsAnnotation)
+
+	var rules []v1beta1.EscalationRule
+	for _, rule := range src.Spec.EscalationRules {
+		var targets []v1beta1.EscalationTarget
+		for _, target := range rule.Targets {
+			targets = append(targets, v1beta1.EscalationTarget{
+				UserID:      target.UserID,
+				ScheduleID:  target.ScheduleID,
+				ScheduleRef: localRefTo(target.ScheduleRef),
+			})
+		}
+		rules = append(rules, v1beta1.EscalationRule{EscalationDelayInMinutes: rule.Delay, Targets: targets})
+	}
+
+	dst.Spec = v1beta1.EscalationPolicySpec{
+		Name:                       src.Spec.Name,
+		Description:                src.Spec.Description,
+		OnCallHandoffNotifications: src.Spec.OnCallHandoffNotifications,
+		NumLoops:                   src.Spec.NumLoops,
+		EscalationRules:            rules,
+		Teams:                      teamsTo(src),
+		AccountRef:                 localRefTo(src.Spec.AccountRef),
+		Adopt:                      adoptTo(src.Spec.Adopt),
+		DeletionPolicy:             v1beta1.DeletionPolicy(src.Spec.DeletionPolicy),
+	}
+
+	dst.Status = v1beta1.EscalationPolicyStatus{
+		PolicyID:           src.Status.PolicyID,
+		ResolvedUserIDs:    src.Status.ResolvedUserIDs,
+		ObservedGeneration: src.Status.ObservedGeneration,
+		HTMLURL:            src.Status.HTMLURL,
+		Upstream:           upstreamTo(src.Status.Upstream),
+		LastSyncedTime:     src.Status.LastSyncedTime,
+		Conditions:         src.Status.Conditions,
+	}
+	return nil
+}
+
+// ConvertFrom converts the Escalation Policy from the v1beta1 hub version
+func (dst *EscalationPolicy) ConvertFrom(hub conversion.Hub) error {
+	src := hub.(*v1beta1.EscalationPolicy)
+
+	dst.ObjectMeta = src.ObjectMeta
+	var team, teamRef string
+	if len(src.Spec.Teams) > 0 {
+		team, teamRef = src.Spec.Teams[0].ID, src.Spec.Teams[0].Name
+	}
+	if len(src.Spec.Teams) > 1 {
+		teams, err := json.Marshal(src.Spec.Teams)
+		if err != nil {
+			return err
+		}
+		dst.Annotations = withAnnotation(src.Annotations, TeamsAnnotation, string(teams))
+	}
+
+	var rules typeinfo.K8sEscalationRuleList
+	for _, rule := range src.Spec.EscalationRules {
+		var targets []typeinfo.EscalationTarget
+		for _, target := range rule.Targets {
+			targets = append(targets, typeinfo.EscalationTarget{
+				UserID:      target.UserID,
+				ScheduleID:  target.ScheduleID,
+				ScheduleRef: localRefFrom(target.ScheduleRef),
+			})
+		}
+		rules = append(rules, typeinfo.K8sEscalationRule{Delay: rule.EscalationDelayInMinutes, Targets: targets})
+	}
+
+	dst.Spec = EscalationPolicySpec{
+		Name:                       src.Spec.Name,
+		Description:                src.Spec.Description,
+		OnCallHandoffNotifications: src.Spec.OnCallHandoffNotifications,
+		NumLoops:                   src.Spec.NumLoops,
+		EscalationRules:            rules,
+		Team:                       typeinfo.TeamID(team),
+		TeamRef:                    teamRef,
+		AccountRef:                 localRefFrom(src.Spec.AccountRef),
+		Adopt:                      adoptFrom(src.Spec.Adopt),
+		DeletionPolicy:             DeletionPolicy(src.Spec.DeletionPolicy),
+	}
+
+	dst.Status = EscalationPolicyStatus{
+		PolicyID:           src.Status.PolicyID,
+		ResolvedUserIDs:    src.Status.ResolvedUserIDs,
+		ObservedGeneration: src.Status.ObservedGeneration,
+		HTMLURL:            src.Status.HTMLURL,
+		Upstream:           upstreamFrom(src.Status.Upstream),
+		LastSyncedTime:     src.Status.LastSyncedTime,
+		Conditions:         src.Status.Conditions,
+	}
+	return nil
+}
+
+// teamsTo returns the teams of the Escalation Policy in v1beta1. The teams kept in the annotation are restored
+// as long as the v1alpha1 team still matches the first of them, i.e. it was not changed through v1alpha1.
+func teamsTo(policy *EscalationPolicy) []v1beta1.TeamReference {
+	first := teamReferenceTo(string(policy.Spec.Team), policy.Spec.TeamRef)
+
+	var stashed []v1beta1.TeamReference
+	if value, found := policy.Annotations[TeamsAnnotation]; found && json.Unmarshal([]byte(value), &stashed) == nil &&
+		len(stashed) > 0 && first != nil && stashed[0] == *first {
+		return stashed
+	}
+
+	if first == nil {
+		return nil
+	}
+	return []v1beta1.TeamReference{*first}
+}
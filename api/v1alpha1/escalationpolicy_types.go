@@ -69,6 +69,28 @@ type EscalationPolicySpec struct {
 	// Only one team may be associated with the policy.
 	// +kubebuilder:default=""
 	Team typeinfo.TeamID `json:"teams,omitempty"`
+
+	// TeamRef references the Team of the policy by the name of its Team custom resource in the same namespace.
+	// It takes precedence over Team.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:default=""
+	TeamRef string `json:"team_ref,omitempty"`
+
+	// AccountRef defines the name of the PagerDutyAccount used to manage the Escalation Policy.
+	// The operator's default API token is used when empty.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:default=""
+	AccountRef string `json:"account_ref,omitempty"`
+
+	// Adopt takes ownership of an existing escalation policy instead of creating a new one
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Adopt *AdoptSpec `json:"adopt,omitempty"`
+
+	// DeletionPolicy defines what happens to the upstream escalation policy when the resource is deleted.
+	// Delete removes it, Orphan leaves it untouched and Retain leaves it renamed with a "(retained)" suffix.
+	// The operator's --default-deletion-policy applies when empty.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	DeletionPolicy DeletionPolicy `json:"deletion_policy,omitempty"`
 }
 
 // EscalationPolicyStatus defines the observed state of EscalationPolicy
@@ -80,6 +102,26 @@ type EscalationPolicyStatus struct {
 	// PolicyID stores the ID of the Escalation Policy
 	PolicyID string `json:"policy_id,omitempty"`
 
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// ResolvedUserIDs stores the PagerDuty user ID of every email used as escalation rule target
+	ResolvedUserIDs map[string]string `json:"resolved_user_ids,omitempty"`
+
+	// ObservedGeneration is the generation of the spec last reconciled with upstream
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	ObservedGeneration int64 `json:"observed_generation,omitempty"`
+
+	// HTMLURL is the address of the upstream escalation policy in the PagerDuty web UI
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	HTMLURL string `json:"html_url,omitempty"`
+
+	// Upstream stores the managed fields of the upstream escalation policy, as last read from PagerDuty
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	Upstream *UpstreamSnapshot `json:"upstream,omitempty"`
+
+	// LastSyncedTime stores when the spec was last successfully compared with the upstream escalation policy
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	LastSyncedTime *metav1.Time `json:"last_synced_time,omitempty"`
+
 	//	Conditions stores the conditions of the Escalation Policy
 	// +kubebuilder:default={}
 	Conditions []metav1.Condition `json:"conditions"`
@@ -87,6 +129,10 @@ type EscalationPolicyStatus struct {
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="ID",type=string,JSONPath=`.status.policy_id`
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+//+kubebuilder:printcolumn:name="URL",type=string,JSONPath=`.status.html_url`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // EscalationPolicy is the Schema for the escalationpolicies API
 type EscalationPolicy struct {
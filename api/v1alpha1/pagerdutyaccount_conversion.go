@@ -0,0 +1,33 @@
+package v1alpha1
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"gitlab.share-now.com/platform/pagerduty-operator/api/v1beta1"
+)
+
+// ConvertTo converts the PagerDuty account to the v1beta1 hub version
+func (src *PagerDutyAccount) ConvertTo(hub conversion.Hub) error {
+	dst := hub.(*v1beta1.PagerDutyAccount)
+
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = v1beta1.PagerDutyAccountSpec{
+		TokenSecretRef: v1beta1.SecretKeyReference(src.Spec.TokenSecretRef),
+		APIURL:         src.Spec.APIURL,
+		DefaultFrom:    src.Spec.DefaultFrom,
+	}
+	return nil
+}
+
+// ConvertFrom converts the PagerDuty account from the v1beta1 hub version
+func (dst *PagerDutyAccount) ConvertFrom(hub conversion.Hub) error {
+	src := hub.(*v1beta1.PagerDutyAccount)
+
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = PagerDutyAccountSpec{
+		TokenSecretRef: SecretKeyReference(src.Spec.TokenSecretRef),
+		APIURL:         src.Spec.APIURL,
+		DefaultFrom:    src.Spec.DefaultFrom,
+	}
+	return nil
+}
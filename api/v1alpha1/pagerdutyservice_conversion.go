@@ -0,0 +1,115 @@
+package v1alpha1
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"gitlab.share-now.com/platform/pagerduty-operator/api/v1beta1"
+)
+
+// ConvertTo converts the PagerDuty service to the v1beta1 hub version
+func (src *PagerdutyService) ConvertTo(hub conversion.Hub) error {
+	dst := hub.(*v1beta1.PagerdutyService)
+
+	dst.ObjectMeta = src.ObjectMeta
+
+	var integrations []v1beta1.ServiceIntegration
+	for _, integration := range src.Spec.Integrations {
+		integrations = append(integrations, v1beta1.ServiceIntegration{
+			Name:       integration.Name,
+			Type:       integration.Type,
+			VendorID:   integration.VendorID,
+			SecretName: integration.SecretName,
+			SecretKey:  integration.SecretKey,
+		})
+	}
+
+	dst.Spec = v1beta1.PagerdutyServiceSpec{
+		Name:                   src.Spec.Name,
+		Description:            src.Spec.Description,
+		AutoResolveTimeout:     src.Spec.AutoResolveTimeout,
+		AcknowledgementTimeout: src.Spec.AcknowledgementTimeout,
+		Status:                 src.Spec.Status,
+		EscalationPolicyRef:    v1beta1.LocalObjectReference{Name: src.Spec.EscalationPolicyName},
+		AlertCreation:          src.Spec.AlertCreation,
+		AccountRef:             localRefTo(src.Spec.AccountRef),
+		Integrations:           integrations,
+		Adopt:                  adoptTo(src.Spec.Adopt),
+		DeletionPolicy:         v1beta1.DeletionPolicy(src.Spec.DeletionPolicy),
+	}
+
+	var statuses []v1beta1.IntegrationStatus
+	for _, status := range src.Status.Integrations {
+		statuses = append(statuses, v1beta1.IntegrationStatus{
+			Name:       status.Name,
+			ID:         status.ID,
+			Vendor:     status.Vendor,
+			SecretName: status.SecretName,
+		})
+	}
+
+	dst.Status = v1beta1.PagerdutyServiceStatus{
+		ServiceID:          src.Status.ServiceID,
+		EscalationPolicyID: src.Status.EscalationPolicyID,
+		Integrations:       statuses,
+		ObservedGeneration: src.Status.ObservedGeneration,
+		HTMLURL:            src.Status.HTMLURL,
+		Upstream:           upstreamTo(src.Status.Upstream),
+		LastSyncedTime:     src.Status.LastSyncedTime,
+		Conditions:         src.Status.Conditions,
+	}
+	return nil
+}
+
+// ConvertFrom converts the PagerDuty service from the v1beta1 hub version
+func (dst *PagerdutyService) ConvertFrom(hub conversion.Hub) error {
+	src := hub.(*v1beta1.PagerdutyService)
+
+	dst.ObjectMeta = src.ObjectMeta
+
+	var integrations []ServiceIntegration
+	for _, integration := range src.Spec.Integrations {
+		integrations = append(integrations, ServiceIntegration{
+			Name:       integration.Name,
+			Type:       integration.Type,
+			VendorID:   integration.VendorID,
+			SecretName: integration.SecretName,
+			SecretKey:  integration.SecretKey,
+		})
+	}
+
+	dst.Spec = PagerdutyServiceSpec{
+		Name:                   src.Spec.Name,
+		Description:            src.Spec.Description,
+		AutoResolveTimeout:     src.Spec.AutoResolveTimeout,
+		AcknowledgementTimeout: src.Spec.AcknowledgementTimeout,
+		Status:                 src.Spec.Status,
+		EscalationPolicyName:   src.Spec.EscalationPolicyRef.Name,
+		AlertCreation:          src.Spec.AlertCreation,
+		AccountRef:             localRefFrom(src.Spec.AccountRef),
+		Integrations:           integrations,
+		Adopt:                  adoptFrom(src.Spec.Adopt),
+		DeletionPolicy:         DeletionPolicy(src.Spec.DeletionPolicy),
+	}
+
+	var statuses []IntegrationStatus
+	for _, status := range src.Status.Integrations {
+		statuses = append(statuses, IntegrationStatus{
+			Name:       status.Name,
+			ID:         status.ID,
+			Vendor:     status.Vendor,
+			SecretName: status.SecretName,
+		})
+	}
+
+	dst.Status = PagerdutyServiceStatus{
+		ServiceID:          src.Status.ServiceID,
+		EscalationPolicyID: src.Status.EscalationPolicyID,
+		Integrations:       statuses,
+		ObservedGeneration: src.Status.ObservedGeneration,
+		HTMLURL:            src.Status.HTMLURL,
+		Upstream:           upstreamFrom(src.Status.Upstream),
+		LastSyncedTime:     src.Status.LastSyncedTime,
+		Conditions:         src.Status.Conditions,
+	}
+	return nil
+}
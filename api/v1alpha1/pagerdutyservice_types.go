@@ -23,6 +23,70 @@ import (
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
+const (
+	// IntegrationTypeEventsAPIV2 integrates through the PagerDuty Events API v2
+	IntegrationTypeEventsAPIV2 = "events_api_v2"
+	// IntegrationTypePrometheus integrates through the Prometheus Alertmanager vendor
+	IntegrationTypePrometheus = "prometheus"
+	// IntegrationTypeVendor integrates through the vendor with the given VendorID
+	IntegrationTypeVendor = "vendor"
+)
+
+// PrometheusVendorID is the ID of the Prometheus vendor, which is the same in every PagerDuty account
+const PrometheusVendorID = "PAM4FGS"
+
+// ServiceIntegration declares an integration of the PagerDuty service whose key is published in a Secret
+type ServiceIntegration struct {
+	// Name of the integration, unique within the service
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Type of the integration
+	// +kubebuilder:validation:Enum=events_api_v2;prometheus;vendor
+	// +kubebuilder:default=events_api_v2
+	Type string `json:"type,omitempty"`
+
+	// VendorID defines the PagerDuty vendor of the integration. Only used by the vendor type.
+	VendorID string `json:"vendor_id,omitempty"`
+
+	// SecretName defines the Secret in the namespace of the service the integration key is written to
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	SecretName string `json:"secret_name"`
+
+	// SecretKey defines the key of the Secret holding the integration key
+	// +kubebuilder:default=integration_key
+	SecretKey string `json:"secret_key,omitempty"`
+}
+
+// Vendor returns the ID of the vendor of the integration, or an empty string for Events API v2 integrations
+func (integration ServiceIntegration) Vendor() string {
+	switch integration.Type {
+	case IntegrationTypePrometheus:
+		return PrometheusVendorID
+	case IntegrationTypeVendor:
+		return integration.VendorID
+	default:
+		return ""
+	}
+}
+
+// IntegrationStatus is an integration of the PagerDuty service created upstream
+type IntegrationStatus struct {
+	// Name of the integration
+	Name string `json:"name"`
+
+	// ID of the integration
+	ID string `json:"id"`
+
+	// Vendor of the integration, empty for Events API v2 integrations
+	Vendor string `json:"vendor,omitempty"`
+
+	// SecretName is the Secret the integration key was written to
+	SecretName string `json:"secret_name"`
+}
+
 // PagerdutyServiceSpec defines the desired state of PagerdutyService
 type PagerdutyServiceSpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
@@ -73,6 +137,27 @@ type PagerdutyServiceSpec struct {
 	// +kubebuilder:validation:Enum=create_incidents;create_alerts_and_incidents
 	// +kubebuilder:default=create_incidents
 	AlertCreation string `json:"alert_creation,omitempty"`
+
+	// AccountRef defines the name of the PagerDutyAccount used to manage the PagerDuty service.
+	// The operator's default API token is used when empty.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:default=""
+	AccountRef string `json:"account_ref,omitempty"`
+
+	// Integrations defines the integrations of the PagerDuty service. The key of each integration
+	// is written into a Secret in the namespace of the service.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Integrations []ServiceIntegration `json:"integrations,omitempty"`
+
+	// Adopt takes ownership of an existing PagerDuty service instead of creating a new one
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Adopt *AdoptSpec `json:"adopt,omitempty"`
+
+	// DeletionPolicy defines what happens to the upstream PagerDuty service when the resource is deleted.
+	// Delete removes it, Orphan leaves it untouched and Retain leaves it renamed with a "(retained)" suffix.
+	// The operator's --default-deletion-policy applies when empty.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	DeletionPolicy DeletionPolicy `json:"deletion_policy,omitempty"`
 }
 
 // PagerdutyServiceStatus defines the observed state of PagerdutyService
@@ -96,12 +181,36 @@ type PagerdutyServiceStatus struct {
 	// +kubebuilder:default=""
 	EscalationPolicyID string `json:"escalation_policy_id,omitempty"`
 
+	// Integrations stores the integrations of the service created upstream
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	Integrations []IntegrationStatus `json:"integrations,omitempty"`
+
+	// ObservedGeneration is the generation of the spec last reconciled with upstream
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	ObservedGeneration int64 `json:"observed_generation,omitempty"`
+
+	// HTMLURL is the address of the upstream PagerDuty service in the PagerDuty web UI
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	HTMLURL string `json:"html_url,omitempty"`
+
+	// Upstream stores the managed fields of the upstream PagerDuty service, as last read from PagerDuty
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	Upstream *UpstreamSnapshot `json:"upstream,omitempty"`
+
+	// LastSyncedTime stores when the spec was last successfully compared with the upstream PagerDuty service
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	LastSyncedTime *metav1.Time `json:"last_synced_time,omitempty"`
+
 	// // Conditions store the status conditions of the Service
 	Conditions []metav1.Condition `json:"conditions"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="ID",type=string,JSONPath=`.status.service_id`
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+//+kubebuilder:printcolumn:name="URL",type=string,JSONPath=`.status.html_url`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // PagerdutyService is the Schema for the pagerdutyservices API
 type PagerdutyService struct {
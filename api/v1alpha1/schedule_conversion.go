@@ -0,0 +1,102 @@
+package v1alpha1
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"gitlab.share-now.com/platform/pagerduty-operator/api/v1beta1"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/typeinfo"
+)
+
+// ConvertTo converts the Schedule to the v1beta1 hub version
+func (src *Schedule) ConvertTo(hub conversion.Hub) error {
+	dst := hub.(*v1beta1.Schedule)
+
+	dst.ObjectMeta = src.ObjectMeta
+
+	var layers []v1beta1.ScheduleLayer
+	for _, layer := range src.Spec.ScheduleLayers {
+		var users []string
+		for _, user := range layer.Users {
+			users = append(users, string(user))
+		}
+		var restrictions []v1beta1.ScheduleRestriction
+		for _, restriction := range layer.Restrictions {
+			restrictions = append(restrictions, v1beta1.ScheduleRestriction(restriction))
+		}
+		layers = append(layers, v1beta1.ScheduleLayer{
+			Name:                      layer.Name,
+			Start:                     layer.Start,
+			End:                       layer.End,
+			RotationVirtualStart:      layer.RotationVirtualStart,
+			RotationTurnLengthSeconds: layer.RotationTurnLengthSeconds,
+			Users:                     users,
+			Restrictions:              restrictions,
+		})
+	}
+
+	dst.Spec = v1beta1.ScheduleSpec{
+		Name:           src.Spec.Name,
+		Description:    src.Spec.Description,
+		TimeZone:       src.Spec.TimeZone,
+		ScheduleLayers: layers,
+		AccountRef:     localRefTo(src.Spec.AccountRef),
+		DeletionPolicy: v1beta1.DeletionPolicy(src.Spec.DeletionPolicy),
+	}
+
+	dst.Status = v1beta1.ScheduleStatus{
+		ScheduleID:         src.Status.ScheduleID,
+		ObservedGeneration: src.Status.ObservedGeneration,
+		HTMLURL:            src.Status.HTMLURL,
+		Upstream:           upstreamTo(src.Status.Upstream),
+		LastSyncedTime:     src.Status.LastSyncedTime,
+		Conditions:         src.Status.Conditions,
+	}
+	return nil
+}
+
+// ConvertFrom converts the Schedule from the v1beta1 hub version
+func (dst *Schedule) ConvertFrom(hub conversion.Hub) error {
+	src := hub.(*v1beta1.Schedule)
+
+	dst.ObjectMeta = src.ObjectMeta
+
+	var layers []ScheduleLayer
+	for _, layer := range src.Spec.ScheduleLayers {
+		var users []typeinfo.UserID
+		for _, user := range layer.Users {
+			users = append(users, typeinfo.UserID(user))
+		}
+		var restrictions []ScheduleRestriction
+		for _, restriction := range layer.Restrictions {
+			restrictions = append(restrictions, ScheduleRestriction(restriction))
+		}
+		layers = append(layers, ScheduleLayer{
+			Name:                      layer.Name,
+			Start:                     layer.Start,
+			End:                       layer.End,
+			RotationVirtualStart:      layer.RotationVirtualStart,
+			RotationTurnLengthSeconds: layer.RotationTurnLengthSeconds,
+			Users:                     users,
+			Restrictions:              restrictions,
+		})
+	}
+
+	dst.Spec = ScheduleSpec{
+		Name:           src.Spec.Name,
+		Description:    src.Spec.Description,
+		TimeZone:       src.Spec.TimeZone,
+		ScheduleLayers: layers,
+		AccountRef:     localRefFrom(src.Spec.AccountRef),
+		DeletionPolicy: DeletionPolicy(src.Spec.DeletionPolicy),
+	}
+
+	dst.Status = ScheduleStatus{
+		ScheduleID:         src.Status.ScheduleID,
+		ObservedGeneration: src.Status.ObservedGeneration,
+		HTMLURL:            src.Status.HTMLURL,
+		Upstream:           upstreamFrom(src.Status.Upstream),
+		LastSyncedTime:     src.Status.LastSyncedTime,
+		Conditions:         src.Status.Conditions,
+	}
+	return nil
+}
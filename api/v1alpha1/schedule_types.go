@@ -0,0 +1,168 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/typeinfo"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ScheduleRestriction limits on-call responsibility for a layer to certain times of the day or week
+type ScheduleRestriction struct {
+	// Type of the restriction
+	// +kubebuilder:validation:Enum=daily_restriction;weekly_restriction
+	Type string `json:"type"`
+
+	// StartTimeOfDay defines when the restriction starts, in the format HH:mm:ss
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]:[0-5][0-9]$`
+	StartTimeOfDay string `json:"start_time_of_day"`
+
+	// StartDayOfWeek defines the day the restriction starts, from 1 (Monday) to 7 (Sunday).
+	// Only used by weekly restrictions.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=7
+	StartDayOfWeek uint `json:"start_day_of_week,omitempty"`
+
+	// DurationSeconds defines how long the restriction lasts
+	// +kubebuilder:validation:Minimum=1
+	DurationSeconds uint `json:"duration_seconds"`
+}
+
+// ScheduleLayer puts users on call for a schedule
+type ScheduleLayer struct {
+	// Name of the layer. PagerDuty names it "Layer <position>" when empty.
+	// +kubebuilder:default=""
+	Name string `json:"name,omitempty"`
+
+	// Start defines when the layer starts, in RFC 3339 format
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Format=date-time
+	Start string `json:"start"`
+
+	// End defines when the layer ends, in RFC 3339 format. The layer never ends when empty.
+	// +kubebuilder:validation:Format=date-time
+	End string `json:"end,omitempty"`
+
+	// RotationVirtualStart defines the effective start time of the layer, used to compute the rotation
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Format=date-time
+	RotationVirtualStart string `json:"rotation_virtual_start"`
+
+	// RotationTurnLengthSeconds defines how long each user is on call
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum=1
+	RotationTurnLengthSeconds uint `json:"rotation_turn_length_seconds"`
+
+	// Users defines the ordered list of users that rotate on call
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	Users []typeinfo.UserID `json:"users"`
+
+	// Restrictions limit the times of the day or week the layer is on call
+	Restrictions []ScheduleRestriction `json:"restrictions,omitempty"`
+}
+
+// ScheduleSpec defines the desired state of Schedule
+type ScheduleSpec struct {
+	// Name defines the name of the Schedule that will be created
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:validation:Required
+	Name string `json:"name,omitempty"`
+
+	// Description defines the description of the Schedule that will be created
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:default=""
+	Description string `json:"description,omitempty"`
+
+	// TimeZone defines the time zone of the Schedule, e.g. Europe/Berlin
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:default=UTC
+	TimeZone string `json:"time_zone,omitempty"`
+
+	// ScheduleLayers defines the layers of the Schedule
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	ScheduleLayers []ScheduleLayer `json:"schedule_layers"`
+
+	// AccountRef defines the name of the PagerDutyAccount used to manage the Schedule.
+	// The operator's default API token is used when empty.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:default=""
+	AccountRef string `json:"account_ref,omitempty"`
+
+	// DeletionPolicy defines what happens to the upstream schedule when the resource is deleted.
+	// Delete removes it, Orphan leaves it untouched and Retain leaves it renamed with a "(retained)" suffix.
+	// The operator's --default-deletion-policy applies when empty.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	DeletionPolicy DeletionPolicy `json:"deletion_policy,omitempty"`
+}
+
+// ScheduleStatus defines the observed state of Schedule
+type ScheduleStatus struct {
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// ScheduleID stores the ID of the Schedule
+	ScheduleID string `json:"schedule_id,omitempty"`
+
+	// ObservedGeneration is the generation of the spec last reconciled with upstream
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	ObservedGeneration int64 `json:"observed_generation,omitempty"`
+
+	// HTMLURL is the address of the upstream schedule in the PagerDuty web UI
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	HTMLURL string `json:"html_url,omitempty"`
+
+	// Upstream stores the managed fields of the upstream schedule, as last read from PagerDuty
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	Upstream *UpstreamSnapshot `json:"upstream,omitempty"`
+
+	// LastSyncedTime stores when the spec was last successfully compared with the upstream schedule
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	LastSyncedTime *metav1.Time `json:"last_synced_time,omitempty"`
+
+	//	Conditions stores the conditions of the Schedule
+	Conditions []metav1.Condition `json:"conditions"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="ID",type=string,JSONPath=`.status.schedule_id`
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+//+kubebuilder:printcolumn:name="URL",type=string,JSONPath=`.status.html_url`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// Schedule is the Schema for the schedules API
+type Schedule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ScheduleSpec   `json:"spec,omitempty"`
+	Status ScheduleStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ScheduleList contains a list of Schedule
+type ScheduleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Schedule `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Schedule{}, &ScheduleList{})
+}
@@ -0,0 +1,83 @@
+package v1alpha1
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"gitlab.share-now.com/platform/pagerduty-operator/api/v1beta1"
+)
+
+// ConvertTo converts the Team to the v1beta1 hub version
+func (src *Team) ConvertTo(hub conversion.Hub) error {
+	dst := hub.(*v1beta1.Team)
+
+	dst.ObjectMeta = src.ObjectMeta
+
+	var members []v1beta1.TeamMember
+	for _, member := range src.Spec.Members {
+		members = append(members, v1beta1.TeamMember(member))
+	}
+
+	dst.Spec = v1beta1.TeamSpec{
+		Name:           src.Spec.Name,
+		Description:    src.Spec.Description,
+		Parent:         teamReferenceTo(src.Spec.ParentID, src.Spec.ParentRef),
+		Members:        members,
+		AccountRef:     localRefTo(src.Spec.AccountRef),
+		DeletionPolicy: v1beta1.DeletionPolicy(src.Spec.DeletionPolicy),
+	}
+
+	var memberships []v1beta1.TeamMembership
+	for _, membership := range src.Status.Memberships {
+		memberships = append(memberships, v1beta1.TeamMembership(membership))
+	}
+
+	dst.Status = v1beta1.TeamStatus{
+		TeamID:             src.Status.TeamID,
+		Memberships:        memberships,
+		ObservedGeneration: src.Status.ObservedGeneration,
+		HTMLURL:            src.Status.HTMLURL,
+		Upstream:           upstreamTo(src.Status.Upstream),
+		LastSyncedTime:     src.Status.LastSyncedTime,
+		Conditions:         src.Status.Conditions,
+	}
+	return nil
+}
+
+// ConvertFrom converts the Team from the v1beta1 hub version
+func (dst *Team) ConvertFrom(hub conversion.Hub) error {
+	src := hub.(*v1beta1.Team)
+
+	dst.ObjectMeta = src.ObjectMeta
+
+	var members []TeamMember
+	for _, member := range src.Spec.Members {
+		members = append(members, TeamMember(member))
+	}
+
+	parentID, parentRef := teamReferenceFrom(src.Spec.Parent)
+	dst.Spec = TeamSpec{
+		Name:           src.Spec.Name,
+		Description:    src.Spec.Description,
+		ParentID:       parentID,
+		ParentRef:      parentRef,
+		Members:        members,
+		AccountRef:     localRefFrom(src.Spec.AccountRef),
+		DeletionPolicy: DeletionPolicy(src.Spec.DeletionPolicy),
+	}
+
+	var memberships []TeamMembership
+	for _, membership := range src.Status.Memberships {
+		memberships = append(memberships, TeamMembership(membership))
+	}
+
+	dst.Status = TeamStatus{
+		TeamID:             src.Status.TeamID,
+		Memberships:        memberships,
+		ObservedGeneration: src.Status.ObservedGeneration,
+		HTMLURL:            src.Status.HTMLURL,
+		Upstream:           upstreamFrom(src.Status.Upstream),
+		LastSyncedTime:     src.Status.LastSyncedTime,
+		Conditions:         src.Status.Conditions,
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TeamMember declares a PagerDuty user as member of the Team
+type TeamMember struct {
+	// User references a PagerDuty user by ID, or by email with email:<address>
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	User string `json:"user"`
+
+	// Role of the user in the Team
+	// +kubebuilder:validation:Enum=observer;responder;manager
+	// +kubebuilder:default=responder
+	Role string `json:"role,omitempty"`
+}
+
+// TeamMembership is a membership of the Team observed in PagerDuty
+type TeamMembership struct {
+	// UserID is the ID of the member
+	UserID string `json:"user_id"`
+
+	// Role of the member in the Team
+	Role string `json:"role"`
+}
+
+// TeamSpec defines the desired state of Team
+type TeamSpec struct {
+	// Name defines the name of the Team that will be created
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:validation:Required
+	Name string `json:"name,omitempty"`
+
+	// Description defines the description of the Team that will be created
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:default=""
+	Description string `json:"description,omitempty"`
+
+	// ParentID defines the ID of the parent Team in PagerDuty
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:default=""
+	ParentID string `json:"parent_id,omitempty"`
+
+	// ParentRef references the parent Team by the name of its Team custom resource in the same namespace.
+	// It takes precedence over ParentID.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:default=""
+	ParentRef string `json:"parent_ref,omitempty"`
+
+	// Members defines the users of the Team and their roles. Memberships not listed here are removed.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Members []TeamMember `json:"members,omitempty"`
+
+	// AccountRef defines the name of the PagerDutyAccount used to manage the Team.
+	// The operator's default API token is used when empty.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:default=""
+	AccountRef string `json:"account_ref,omitempty"`
+
+	// DeletionPolicy defines what happens to the upstream team when the resource is deleted.
+	// Delete removes it, Orphan leaves it untouched and Retain leaves it renamed with a "(retained)" suffix.
+	// The operator's --default-deletion-policy applies when empty.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	DeletionPolicy DeletionPolicy `json:"deletion_policy,omitempty"`
+}
+
+// TeamStatus defines the observed state of Team
+type TeamStatus struct {
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// TeamID stores the ID of the Team
+	TeamID string `json:"team_id,omitempty"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// Memberships stores the memberships of the Team observed in PagerDuty
+	Memberships []TeamMembership `json:"memberships,omitempty"`
+
+	// ObservedGeneration is the generation of the spec last reconciled with upstream
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	ObservedGeneration int64 `json:"observed_generation,omitempty"`
+
+	// HTMLURL is the address of the upstream team in the PagerDuty web UI
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	HTMLURL string `json:"html_url,omitempty"`
+
+	// Upstream stores the managed fields of the upstream team, as last read from PagerDuty
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	Upstream *UpstreamSnapshot `json:"upstream,omitempty"`
+
+	// LastSyncedTime stores when the spec was last successfully compared with the upstream team
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	LastSyncedTime *metav1.Time `json:"last_synced_time,omitempty"`
+
+	//	Conditions stores the conditions of the Team
+	Conditions []metav1.Condition `json:"conditions"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="ID",type=string,JSONPath=`.status.team_id`
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+//+kubebuilder:printcolumn:name="URL",type=string,JSONPath=`.status.html_url`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// Team is the Schema for the teams API
+type Team struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   TeamSpec   `json:"spec,omitempty"`
+	Status TeamStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// TeamList contains a list of Team
+type TeamList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Team `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Team{}, &TeamList{})
+}
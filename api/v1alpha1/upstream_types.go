@@ -0,0 +1,27 @@
+package v1alpha1
+
+// UpstreamSnapshot is a compact view of the upstream fields managed by the operator, as last read from PagerDuty
+type UpstreamSnapshot struct {
+	// Name of the upstream object
+	Name string `json:"name"`
+
+	// Description of the upstream object
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// Fields holds the other managed fields of the upstream object, keyed by the json name of their spec field.
+	// References to other PagerDuty objects are given by ID, lists by their length.
+	// +optional
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+// SetField records a managed field of the upstream object, empty values are left out
+func (s *UpstreamSnapshot) SetField(field string, value string) {
+	if value == "" {
+		return
+	}
+	if s.Fields == nil {
+		s.Fields = map[string]string{}
+	}
+	s.Fields[field] = value
+}
@@ -27,12 +27,27 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdoptSpec) DeepCopyInto(out *AdoptSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdoptSpec.
+func (in *AdoptSpec) DeepCopy() *AdoptSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AdoptSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BusinessService) DeepCopyInto(out *BusinessService) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
@@ -89,6 +104,16 @@ func (in *BusinessServiceList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BusinessServiceSpec) DeepCopyInto(out *BusinessServiceSpec) {
 	*out = *in
+	if in.SupportingServices != nil {
+		in, out := &in.SupportingServices, &out.SupportingServices
+		*out = make([]SupportingServiceReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Adopt != nil {
+		in, out := &in.Adopt, &out.Adopt
+		*out = new(AdoptSpec)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BusinessServiceSpec.
@@ -104,6 +129,20 @@ func (in *BusinessServiceSpec) DeepCopy() *BusinessServiceSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BusinessServiceStatus) DeepCopyInto(out *BusinessServiceStatus) {
 	*out = *in
+	if in.ServiceDependencies != nil {
+		in, out := &in.ServiceDependencies, &out.ServiceDependencies
+		*out = make([]ServiceDependency, len(*in))
+		copy(*out, *in)
+	}
+	if in.Upstream != nil {
+		in, out := &in.Upstream, &out.Upstream
+		*out = new(UpstreamSnapshot)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastSyncedTime != nil {
+		in, out := &in.LastSyncedTime, &out.LastSyncedTime
+		*out = (*in).DeepCopy()
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]v1.Condition, len(*in))
@@ -192,6 +231,11 @@ func (in *EscalationPolicySpec) DeepCopyInto(out *EscalationPolicySpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Adopt != nil {
+		in, out := &in.Adopt, &out.Adopt
+		*out = new(AdoptSpec)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EscalationPolicySpec.
@@ -207,6 +251,22 @@ func (in *EscalationPolicySpec) DeepCopy() *EscalationPolicySpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EscalationPolicyStatus) DeepCopyInto(out *EscalationPolicyStatus) {
 	*out = *in
+	if in.ResolvedUserIDs != nil {
+		in, out := &in.ResolvedUserIDs, &out.ResolvedUserIDs
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Upstream != nil {
+		in, out := &in.Upstream, &out.Upstream
+		*out = new(UpstreamSnapshot)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastSyncedTime != nil {
+		in, out := &in.LastSyncedTime, &out.LastSyncedTime
+		*out = (*in).DeepCopy()
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]v1.Condition, len(*in))
@@ -226,6 +286,95 @@ func (in *EscalationPolicyStatus) DeepCopy() *EscalationPolicyStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IntegrationStatus) DeepCopyInto(out *IntegrationStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntegrationStatus.
+func (in *IntegrationStatus) DeepCopy() *IntegrationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(IntegrationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PagerDutyAccount) DeepCopyInto(out *PagerDutyAccount) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PagerDutyAccount.
+func (in *PagerDutyAccount) DeepCopy() *PagerDutyAccount {
+	if in == nil {
+		return nil
+	}
+	out := new(PagerDutyAccount)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PagerDutyAccount) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PagerDutyAccountList) DeepCopyInto(out *PagerDutyAccountList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PagerDutyAccount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PagerDutyAccountList.
+func (in *PagerDutyAccountList) DeepCopy() *PagerDutyAccountList {
+	if in == nil {
+		return nil
+	}
+	out := new(PagerDutyAccountList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PagerDutyAccountList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PagerDutyAccountSpec) DeepCopyInto(out *PagerDutyAccountSpec) {
+	*out = *in
+	out.TokenSecretRef = in.TokenSecretRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PagerDutyAccountSpec.
+func (in *PagerDutyAccountSpec) DeepCopy() *PagerDutyAccountSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PagerDutyAccountSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PagerdutyService) DeepCopyInto(out *PagerdutyService) {
 	*out = *in
@@ -298,6 +447,16 @@ func (in *PagerdutyServiceSpec) DeepCopyInto(out *PagerdutyServiceSpec) {
 		*out = new(uint)
 		**out = **in
 	}
+	if in.Integrations != nil {
+		in, out := &in.Integrations, &out.Integrations
+		*out = make([]ServiceIntegration, len(*in))
+		copy(*out, *in)
+	}
+	if in.Adopt != nil {
+		in, out := &in.Adopt, &out.Adopt
+		*out = new(AdoptSpec)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PagerdutyServiceSpec.
@@ -313,6 +472,20 @@ func (in *PagerdutyServiceSpec) DeepCopy() *PagerdutyServiceSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PagerdutyServiceStatus) DeepCopyInto(out *PagerdutyServiceStatus) {
 	*out = *in
+	if in.Integrations != nil {
+		in, out := &in.Integrations, &out.Integrations
+		*out = make([]IntegrationStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Upstream != nil {
+		in, out := &in.Upstream, &out.Upstream
+		*out = new(UpstreamSnapshot)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastSyncedTime != nil {
+		in, out := &in.LastSyncedTime, &out.LastSyncedTime
+		*out = (*in).DeepCopy()
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]v1.Condition, len(*in))
@@ -331,3 +504,382 @@ func (in *PagerdutyServiceStatus) DeepCopy() *PagerdutyServiceStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Schedule) DeepCopyInto(out *Schedule) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Schedule.
+func (in *Schedule) DeepCopy() *Schedule {
+	if in == nil {
+		return nil
+	}
+	out := new(Schedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Schedule) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduleLayer) DeepCopyInto(out *ScheduleLayer) {
+	*out = *in
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = make([]typeinfo.UserID, len(*in))
+		copy(*out, *in)
+	}
+	if in.Restrictions != nil {
+		in, out := &in.Restrictions, &out.Restrictions
+		*out = make([]ScheduleRestriction, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleLayer.
+func (in *ScheduleLayer) DeepCopy() *ScheduleLayer {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduleLayer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduleList) DeepCopyInto(out *ScheduleList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Schedule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleList.
+func (in *ScheduleList) DeepCopy() *ScheduleList {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduleList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ScheduleList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduleRestriction) DeepCopyInto(out *ScheduleRestriction) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleRestriction.
+func (in *ScheduleRestriction) DeepCopy() *ScheduleRestriction {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduleRestriction)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduleSpec) DeepCopyInto(out *ScheduleSpec) {
+	*out = *in
+	if in.ScheduleLayers != nil {
+		in, out := &in.ScheduleLayers, &out.ScheduleLayers
+		*out = make([]ScheduleLayer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleSpec.
+func (in *ScheduleSpec) DeepCopy() *ScheduleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduleStatus) DeepCopyInto(out *ScheduleStatus) {
+	*out = *in
+	if in.Upstream != nil {
+		in, out := &in.Upstream, &out.Upstream
+		*out = new(UpstreamSnapshot)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastSyncedTime != nil {
+		in, out := &in.LastSyncedTime, &out.LastSyncedTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleStatus.
+func (in *ScheduleStatus) DeepCopy() *ScheduleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretKeyReference) DeepCopyInto(out *SecretKeyReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretKeyReference.
+func (in *SecretKeyReference) DeepCopy() *SecretKeyReference {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretKeyReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceDependency) DeepCopyInto(out *ServiceDependency) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceDependency.
+func (in *ServiceDependency) DeepCopy() *ServiceDependency {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceDependency)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceIntegration) DeepCopyInto(out *ServiceIntegration) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceIntegration.
+func (in *ServiceIntegration) DeepCopy() *ServiceIntegration {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceIntegration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SupportingServiceReference) DeepCopyInto(out *SupportingServiceReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SupportingServiceReference.
+func (in *SupportingServiceReference) DeepCopy() *SupportingServiceReference {
+	if in == nil {
+		return nil
+	}
+	out := new(SupportingServiceReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Team) DeepCopyInto(out *Team) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Team.
+func (in *Team) DeepCopy() *Team {
+	if in == nil {
+		return nil
+	}
+	out := new(Team)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Team) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TeamList) DeepCopyInto(out *TeamList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Team, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TeamList.
+func (in *TeamList) DeepCopy() *TeamList {
+	if in == nil {
+		return nil
+	}
+	out := new(TeamList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TeamList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TeamMember) DeepCopyInto(out *TeamMember) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TeamMember.
+func (in *TeamMember) DeepCopy() *TeamMember {
+	if in == nil {
+		return nil
+	}
+	out := new(TeamMember)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TeamMembership) DeepCopyInto(out *TeamMembership) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TeamMembership.
+func (in *TeamMembership) DeepCopy() *TeamMembership {
+	if in == nil {
+		return nil
+	}
+	out := new(TeamMembership)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TeamSpec) DeepCopyInto(out *TeamSpec) {
+	*out = *in
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]TeamMember, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TeamSpec.
+func (in *TeamSpec) DeepCopy() *TeamSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TeamSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TeamStatus) DeepCopyInto(out *TeamStatus) {
+	*out = *in
+	if in.Memberships != nil {
+		in, out := &in.Memberships, &out.Memberships
+		*out = make([]TeamMembership, len(*in))
+		copy(*out, *in)
+	}
+	if in.Upstream != nil {
+		in, out := &in.Upstream, &out.Upstream
+		*out = new(UpstreamSnapshot)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastSyncedTime != nil {
+		in, out := &in.LastSyncedTime, &out.LastSyncedTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TeamStatus.
+func (in *TeamStatus) DeepCopy() *TeamStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TeamStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpstreamSnapshot) DeepCopyInto(out *UpstreamSnapshot) {
+	*out = *in
+	if in.Fields != nil {
+		in, out := &in.Fields, &out.Fields
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpstreamSnapshot.
+func (in *UpstreamSnapshot) DeepCopy() *UpstreamSnapshot {
+	if in == nil {
+		return nil
+	}
+	out := new(UpstreamSnapshot)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SupportingServiceReference references a PagerdutyService or BusinessService the Business Service depends on
+type SupportingServiceReference struct {
+	// Kind of the referenced resource
+	// +kubebuilder:validation:Enum=PagerdutyService;BusinessService
+	// +kubebuilder:default=PagerdutyService
+	Kind string `json:"kind,omitempty"`
+
+	// Namespace of the referenced resource. The namespace of the Business Service is used when empty.
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name of the referenced resource
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// ServiceDependency is a dependency of the Business Service observed in PagerDuty
+type ServiceDependency struct {
+	// ID of the service dependency
+	ID string `json:"id,omitempty"`
+
+	// SupportingServiceID is the ID of the service the Business Service depends on
+	SupportingServiceID string `json:"supportingServiceID"`
+
+	// SupportingServiceType is either service or business_service
+	SupportingServiceType string `json:"supportingServiceType"`
+}
+
+// BusinessServiceSpec defines the desired state of BusinessService
+type BusinessServiceSpec struct {
+	// Name defines the name of the Business Service that will be created
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Description defines the description of the Business Service that will be created
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:default=""
+	Description string `json:"description,omitempty"`
+
+	// PointOfContact defines the owner of the Business Service.
+	// A PagerDuty user can be referenced by email with email:<address>, in which case the user ID is sent upstream.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:default=""
+	PointOfContact string `json:"pointOfContact,omitempty"`
+
+	// Team references the team that owns the Business Service
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Team *TeamReference `json:"team,omitempty"`
+
+	// AccountRef references the PagerDutyAccount used to manage the Business Service.
+	// The operator's default API token is used when empty.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	AccountRef *LocalObjectReference `json:"accountRef,omitempty"`
+
+	// SupportingServices defines the services the Business Service depends on.
+	// Each entry becomes a service dependency in PagerDuty.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	SupportingServices []SupportingServiceReference `json:"supportingServices,omitempty"`
+
+	// Adopt takes ownership of an existing business service instead of creating a new one
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Adopt *AdoptSpec `json:"adopt,omitempty"`
+
+	// DeletionPolicy defines what happens to the upstream business service when the resource is deleted.
+	// The operator's --default-deletion-policy applies when empty.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+}
+
+// BusinessServiceStatus defines the observed state of BusinessService
+type BusinessServiceStatus struct {
+	// BusinessServiceID stores the ID of the Business Service
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	BusinessServiceID string `json:"businessServiceID,omitempty"`
+
+	// PointOfContactUserID stores the ID of the PagerDuty user referenced by email in the point of contact
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	PointOfContactUserID string `json:"pointOfContactUserID,omitempty"`
+
+	// ServiceDependencies stores the service dependencies of the Business Service observed in PagerDuty
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	ServiceDependencies []ServiceDependency `json:"serviceDependencies,omitempty"`
+
+	// ObservedGeneration is the generation of the spec last reconciled with upstream
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// HTMLURL is the address of the upstream business service in the PagerDuty web UI
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	HTMLURL string `json:"htmlURL,omitempty"`
+
+	// Upstream stores the managed fields of the upstream business service, as last read from PagerDuty
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	Upstream *UpstreamSnapshot `json:"upstream,omitempty"`
+
+	// LastSyncedTime stores when the spec was last successfully compared with the upstream business service
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	LastSyncedTime *metav1.Time `json:"lastSyncedTime,omitempty"`
+
+	// Conditions stores the conditions of the Business Service
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:storageversion
+//+kubebuilder:printcolumn:name="ID",type=string,JSONPath=`.status.businessServiceID`
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+//+kubebuilder:printcolumn:name="URL",type=string,JSONPath=`.status.htmlURL`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// BusinessService is the Schema for the businessservices API
+type BusinessService struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   BusinessServiceSpec   `json:"spec,omitempty"`
+	Status BusinessServiceStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// BusinessServiceList contains a list of BusinessService
+type BusinessServiceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BusinessService `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&BusinessService{}, &BusinessServiceList{})
+}
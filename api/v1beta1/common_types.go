@@ -0,0 +1,61 @@
+package v1beta1
+
+// LocalObjectReference references a custom resource by name, in the namespace of the referencing resource
+type LocalObjectReference struct {
+	// Name of the referenced resource
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// TeamReference references a PagerDuty team by ID, or a Team custom resource in the same namespace by name.
+// The name takes precedence over the ID.
+// +kubebuilder:validation:MinProperties=1
+type TeamReference struct {
+	// ID of the PagerDuty team
+	ID string `json:"id,omitempty"`
+
+	// Name of the Team custom resource
+	Name string `json:"name,omitempty"`
+}
+
+// AdoptSpec selects an existing PagerDuty object the controller takes ownership of instead of creating a new one.
+// The adopted object is then reconciled to the spec.
+// +kubebuilder:validation:MinProperties=1
+// +kubebuilder:validation:MaxProperties=1
+type AdoptSpec struct {
+	// ID of the upstream object to adopt. The resource fails to become ready if no object has this ID.
+	ID string `json:"id,omitempty"`
+
+	// ByName adopts the upstream object with the same name as the spec, if there is one.
+	// A new object is created when none matches, and several matches are an error.
+	ByName bool `json:"byName,omitempty"`
+}
+
+// DeletionPolicy defines what happens to the upstream PagerDuty object when its custom resource is deleted
+// +kubebuilder:validation:Enum=Delete;Orphan;Retain
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyDelete deletes the upstream object
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+	// DeletionPolicyOrphan leaves the upstream object untouched
+	DeletionPolicyOrphan DeletionPolicy = "Orphan"
+	// DeletionPolicyRetain leaves the upstream object renamed with a "(retained)" suffix, so it can be told apart
+	DeletionPolicyRetain DeletionPolicy = "Retain"
+)
+
+// UpstreamSnapshot is a compact view of the upstream fields managed by the operator, as last read from PagerDuty
+type UpstreamSnapshot struct {
+	// Name of the upstream object
+	Name string `json:"name"`
+
+	// Description of the upstream object
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// Fields holds the other managed fields of the upstream object.
+	// References to other PagerDuty objects are given by ID, lists by their length.
+	// +optional
+	Fields map[string]string `json:"fields,omitempty"`
+}
@@ -0,0 +1,21 @@
+package v1beta1
+
+// v1beta1 is the hub of the conversions: the storage version, which every other version converts to and from
+
+// Hub marks EscalationPolicy as a conversion hub.
+func (*EscalationPolicy) Hub() {}
+
+// Hub marks PagerdutyService as a conversion hub.
+func (*PagerdutyService) Hub() {}
+
+// Hub marks BusinessService as a conversion hub.
+func (*BusinessService) Hub() {}
+
+// Hub marks Schedule as a conversion hub.
+func (*Schedule) Hub() {}
+
+// Hub marks Team as a conversion hub.
+func (*Team) Hub() {}
+
+// Hub marks PagerDutyAccount as a conversion hub.
+func (*PagerDutyAccount) Hub() {}
@@ -0,0 +1,156 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EscalationTarget is a target of an escalation rule. Exactly one of its fields must be set.
+// +kubebuilder:validation:MinProperties=1
+// +kubebuilder:validation:MaxProperties=1
+type EscalationTarget struct {
+	// UserID references a PagerDuty user by ID, or by email in the form email:<address>
+	UserID string `json:"userID,omitempty"`
+
+	// ScheduleID references a PagerDuty schedule by ID
+	ScheduleID string `json:"scheduleID,omitempty"`
+
+	// ScheduleRef references a Schedule custom resource in the namespace of the policy
+	ScheduleRef *LocalObjectReference `json:"scheduleRef,omitempty"`
+}
+
+// EscalationRule is a rule for an escalation policy to trigger
+type EscalationRule struct {
+	// EscalationDelayInMinutes defines how long an unacknowledged incident stays on this rule
+	// +kubebuilder:validation:Minimum=1
+	EscalationDelayInMinutes uint `json:"escalationDelayInMinutes,omitempty"`
+
+	// The targets an incident should be assigned to upon reaching this rule.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=10
+	Targets []EscalationTarget `json:"targets"`
+}
+
+// EscalationPolicySpec defines the desired state of EscalationPolicy
+type EscalationPolicySpec struct {
+	// Name defines the name of the Escalation Policy that will be created
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Description defines the description of the Escalation Policy that will be created
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:default=""
+	Description string `json:"description,omitempty"`
+
+	// Determines how on call handoff notifications will be sent for users on the escalation policy.
+	// Defaults to "if_has_services".
+	// +kubebuilder:default=if_has_services
+	// +kubebuilder:validation:Enum=if_has_services;always
+	OnCallHandoffNotifications string `json:"onCallHandoffNotifications,omitempty"`
+
+	// NumLoops defines the number of times the escalation policy will repeat after reaching the end of its escalation.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=1
+	NumLoops uint `json:"numLoops,omitempty"`
+
+	// EscalationRules defines the rules of the Escalation Policy
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	EscalationRules []EscalationRule `json:"escalationRules"`
+
+	// Teams associated with the policy. Account must have the teams ability to use this parameter.
+	// The operator only manages a single team so far, the validating webhook rejects several.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Teams []TeamReference `json:"teams,omitempty"`
+
+	// AccountRef references the PagerDutyAccount used to manage the Escalation Policy.
+	// The operator's default API token is used when empty.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	AccountRef *LocalObjectReference `json:"accountRef,omitempty"`
+
+	// Adopt takes ownership of an existing escalation policy instead of creating a new one
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Adopt *AdoptSpec `json:"adopt,omitempty"`
+
+	// DeletionPolicy defines what happens to the upstream escalation policy when the resource is deleted.
+	// The operator's --default-deletion-policy applies when empty.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+}
+
+// EscalationPolicyStatus defines the observed state of EscalationPolicy
+type EscalationPolicyStatus struct {
+	// PolicyID stores the ID of the Escalation Policy
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	PolicyID string `json:"policyID,omitempty"`
+
+	// ResolvedUserIDs stores the PagerDuty user ID of every email used as escalation rule target
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	ResolvedUserIDs map[string]string `json:"resolvedUserIDs,omitempty"`
+
+	// ObservedGeneration is the generation of the spec last reconciled with upstream
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// HTMLURL is the address of the upstream escalation policy in the PagerDuty web UI
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	HTMLURL string `json:"htmlURL,omitempty"`
+
+	// Upstream stores the managed fields of the upstream escalation policy, as last read from PagerDuty
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	Upstream *UpstreamSnapshot `json:"upstream,omitempty"`
+
+	// LastSyncedTime stores when the spec was last successfully compared with the upstream escalation policy
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	LastSyncedTime *metav1.Time `json:"lastSyncedTime,omitempty"`
+
+	// Conditions stores the conditions of the Escalation Policy
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:storageversion
+//+kubebuilder:printcolumn:name="ID",type=string,JSONPath=`.status.policyID`
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+//+kubebuilder:printcolumn:name="URL",type=string,JSONPath=`.status.htmlURL`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// EscalationPolicy is the Schema for the escalationpolicies API
+type EscalationPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   EscalationPolicySpec   `json:"spec,omitempty"`
+	Status EscalationPolicyStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// EscalationPolicyList contains a list of EscalationPolicy
+type EscalationPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []EscalationPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&EscalationPolicy{}, &EscalationPolicyList{})
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SecretKeyReference points to a key of a Secret in a given namespace
+type SecretKeyReference struct {
+	// Namespace of the Secret
+	// +kubebuilder:validation:Required
+	Namespace string `json:"namespace"`
+
+	// Name of the Secret
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Key of the Secret that holds the value
+	// +kubebuilder:default=token
+	Key string `json:"key,omitempty"`
+}
+
+// PagerDutyAccountSpec defines the desired state of PagerDutyAccount
+type PagerDutyAccountSpec struct {
+	// TokenSecretRef references the Secret key holding the API token of the account
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:validation:Required
+	TokenSecretRef SecretKeyReference `json:"tokenSecretRef"`
+
+	// APIURL defines the base URL of the PagerDuty REST API.
+	// Accounts in the EU service region use https://api.eu.pagerduty.com
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:default="https://api.pagerduty.com"
+	// +kubebuilder:validation:Pattern=`^https?://`
+	APIURL string `json:"apiURL,omitempty"`
+
+	// DefaultFrom defines the email of a user of the account, sent in the From header
+	// of the API calls that require one.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:default=""
+	DefaultFrom string `json:"defaultFrom,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Cluster
+//+kubebuilder:storageversion
+
+// PagerDutyAccount is the Schema for the pagerdutyaccounts API
+type PagerDutyAccount struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec PagerDutyAccountSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// PagerDutyAccountList contains a list of PagerDutyAccount
+type PagerDutyAccountList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PagerDutyAccount `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PagerDutyAccount{}, &PagerDutyAccountList{})
+}
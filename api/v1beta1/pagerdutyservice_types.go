@@ -0,0 +1,185 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ServiceIntegration declares an integration of the PagerDuty service whose key is published in a Secret
+type ServiceIntegration struct {
+	// Name of the integration, unique within the service
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Type of the integration
+	// +kubebuilder:validation:Enum=events_api_v2;prometheus;vendor
+	// +kubebuilder:default=events_api_v2
+	Type string `json:"type,omitempty"`
+
+	// VendorID defines the PagerDuty vendor of the integration. Only used by the vendor type.
+	VendorID string `json:"vendorID,omitempty"`
+
+	// SecretName defines the Secret in the namespace of the service the integration key is written to
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	SecretName string `json:"secretName"`
+
+	// SecretKey defines the key of the Secret holding the integration key
+	// +kubebuilder:default=integration_key
+	SecretKey string `json:"secretKey,omitempty"`
+}
+
+// IntegrationStatus is an integration of the PagerDuty service created upstream
+type IntegrationStatus struct {
+	// Name of the integration
+	Name string `json:"name"`
+
+	// ID of the integration
+	ID string `json:"id"`
+
+	// Vendor of the integration, empty for Events API v2 integrations
+	Vendor string `json:"vendor,omitempty"`
+
+	// SecretName is the Secret the integration key was written to
+	SecretName string `json:"secretName"`
+}
+
+// PagerdutyServiceSpec defines the desired state of PagerdutyService
+type PagerdutyServiceSpec struct {
+	// Name defines the name of the PagerDuty service that will be created
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Description defines the description of the PagerDuty service that will be created
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:default=""
+	Description string `json:"description,omitempty"`
+
+	// Time in seconds that an incident is automatically resolved if left open for that long.
+	// Setting this field to 0 disables the feature.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=14400
+	AutoResolveTimeout *uint `json:"autoResolveTimeout,omitempty"`
+
+	// Time in seconds that an incident changes to the Triggered State after being Acknowledged.
+	// Setting this field to 0 disables the feature.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=1800
+	AcknowledgementTimeout *uint `json:"acknowledgementTimeout,omitempty"`
+
+	// The current state of the Service.
+	// +kubebuilder:validation:Enum=active;warning;critical;maintenance;disabled
+	// +kubebuilder:default=active
+	Status string `json:"status,omitempty"`
+
+	// EscalationPolicyRef references the EscalationPolicy of the service in the same namespace
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:validation:Required
+	EscalationPolicyRef LocalObjectReference `json:"escalationPolicyRef"`
+
+	// Whether a service creates only incidents, or both alerts and incidents.
+	// A service must create alerts in order to enable incident merging.
+	// +kubebuilder:validation:Enum=create_incidents;create_alerts_and_incidents
+	// +kubebuilder:default=create_incidents
+	AlertCreation string `json:"alertCreation,omitempty"`
+
+	// AccountRef references the PagerDutyAccount used to manage the PagerDuty service.
+	// The operator's default API token is used when empty.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	AccountRef *LocalObjectReference `json:"accountRef,omitempty"`
+
+	// Integrations defines the integrations of the PagerDuty service. The key of each integration
+	// is written into a Secret in the namespace of the service.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Integrations []ServiceIntegration `json:"integrations,omitempty"`
+
+	// Adopt takes ownership of an existing PagerDuty service instead of creating a new one
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Adopt *AdoptSpec `json:"adopt,omitempty"`
+
+	// DeletionPolicy defines what happens to the upstream PagerDuty service when the resource is deleted.
+	// The operator's --default-deletion-policy applies when empty.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+}
+
+// PagerdutyServiceStatus defines the observed state of PagerdutyService
+type PagerdutyServiceStatus struct {
+	// ServiceID stores the ID of the created service
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	ServiceID string `json:"serviceID,omitempty"`
+
+	// EscalationPolicyID stores the ID of the escalation policy that is attributed to the service
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	EscalationPolicyID string `json:"escalationPolicyID,omitempty"`
+
+	// Integrations stores the integrations of the service created upstream
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	Integrations []IntegrationStatus `json:"integrations,omitempty"`
+
+	// ObservedGeneration is the generation of the spec last reconciled with upstream
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// HTMLURL is the address of the upstream PagerDuty service in the PagerDuty web UI
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	HTMLURL string `json:"htmlURL,omitempty"`
+
+	// Upstream stores the managed fields of the upstream PagerDuty service, as last read from PagerDuty
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	Upstream *UpstreamSnapshot `json:"upstream,omitempty"`
+
+	// LastSyncedTime stores when the spec was last successfully compared with the upstream PagerDuty service
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	LastSyncedTime *metav1.Time `json:"lastSyncedTime,omitempty"`
+
+	// Conditions stores the conditions of the PagerDuty service
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:storageversion
+//+kubebuilder:printcolumn:name="ID",type=string,JSONPath=`.status.serviceID`
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+//+kubebuilder:printcolumn:name="URL",type=string,JSONPath=`.status.htmlURL`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// PagerdutyService is the Schema for the pagerdutyservices API
+type PagerdutyService struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PagerdutyServiceSpec   `json:"spec,omitempty"`
+	Status PagerdutyServiceStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// PagerdutyServiceList contains a list of PagerdutyService
+type PagerdutyServiceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PagerdutyService `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PagerdutyService{}, &PagerdutyServiceList{})
+}
@@ -18,11 +18,12 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"os"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
-	"github.com/PagerDuty/go-pagerduty"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
 	"k8s.io/apimachinery/pkg/runtime"
@@ -31,12 +32,24 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 
 	pagerdutyv1alpha1 "gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
+	pagerdutyv1beta1 "gitlab.share-now.com/platform/pagerduty-operator/api/v1beta1"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/alertmanager"
 	"gitlab.share-now.com/platform/pagerduty-operator/internal/business_service"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/credentials"
 	"gitlab.share-now.com/platform/pagerduty-operator/internal/escalation_policy"
 	ep "gitlab.share-now.com/platform/pagerduty-operator/internal/escalation_policy"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/observe"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_metrics"
 	"gitlab.share-now.com/platform/pagerduty-operator/internal/pdservice"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/ratelimit"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/schedule"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/team"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/typeinfo"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/webhooks"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/workload"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -49,6 +62,7 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
 	utilruntime.Must(pagerdutyv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(pagerdutyv1beta1.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
@@ -56,17 +70,65 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var tokenSecret string
+	var userCacheTTL time.Duration
+	var enableAlertmanagerConfig bool
+	var enableWorkloadServices bool
+	var defaultDeletionPolicy string
+	var resyncPeriod time.Duration
+	var mode string
+	var apiLimits ratelimit.Options
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.StringVar(&tokenSecret, "pagerduty-token-secret", "pagerduty-operator-system/pagerduty-token/token",
+		"The Secret key holding the API token of the default PagerDuty account, in the form namespace/name/key.")
+	flag.DurationVar(&userCacheTTL, "user-cache-ttl", 5*time.Minute,
+		"How long the PagerDuty user IDs resolved from email selectors are cached.")
+	flag.BoolVar(&enableAlertmanagerConfig, "enable-alertmanager-config", false,
+		"Generate prometheus-operator AlertmanagerConfigs for annotated PagerdutyServices. "+
+			"Requires the prometheus-operator CRDs to be installed.")
+	flag.BoolVar(&enableWorkloadServices, "enable-workload-services", false,
+		"Provision PagerdutyServices for Deployments and StatefulSets annotated with an escalation policy.")
+	flag.StringVar(&defaultDeletionPolicy, "default-deletion-policy", string(pagerdutyv1alpha1.DeletionPolicyDelete),
+		"What happens to the upstream PagerDuty objects of deleted resources that do not set a deletion policy: Delete, Orphan or Retain.")
+	flag.DurationVar(&resyncPeriod, "resync-period", 10*time.Minute,
+		"How often healthy resources are compared with PagerDuty to detect changes made upstream. 0 disables the resync.")
+	flag.StringVar(&mode, "mode", string(observe.ModeManage),
+		"manage creates, updates and deletes PagerDuty objects. observe never changes PagerDuty and only reports the drift "+
+			"of resources without a mode annotation.")
+	flag.Float64Var(&apiLimits.QPS, "pagerduty-qps", 10,
+		"The number of PagerDuty API requests per second allowed for each account. 0 disables the rate limit.")
+	flag.IntVar(&apiLimits.Burst, "pagerduty-burst", 20,
+		"The number of PagerDuty API requests allowed at once for each account, above --pagerduty-qps.")
+	flag.IntVar(&apiLimits.MaxRetries, "pagerduty-max-retries", 5,
+		"How often a PagerDuty API request is retried when it is rate limited or fails with a server error.")
 	opts := zap.Options{}
 	opts.BindFlags(flag.CommandLine)
 	flag.Parse()
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	tokenSecretRef, err := credentials.ParseSecretRef(tokenSecret)
+	if err != nil {
+		setupLog.Error(err, "invalid --pagerduty-token-secret")
+		os.Exit(1)
+	}
+
+	deletionPolicy := pagerdutyv1alpha1.DeletionPolicy(defaultDeletionPolicy)
+	if !deletionPolicy.IsValid() {
+		setupLog.Error(fmt.Errorf("unknown deletion policy %q", defaultDeletionPolicy), "invalid --default-deletion-policy")
+		os.Exit(1)
+	}
+
+	defaultMode := observe.Mode(mode)
+	if !defaultMode.IsValid() {
+		setupLog.Error(fmt.Errorf("unknown mode %q", mode), "invalid --mode")
+		os.Exit(1)
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Logger:                 setupLog.WithName("PDOperator"),
 		Scheme:                 scheme,
@@ -92,35 +154,111 @@ func main() {
 		os.Exit(1)
 	}
 
+	clientProvider := credentials.NewClientFactory(mgr.GetClient(), mgr.GetAPIReader(), tokenSecretRef, apiLimits)
+	userResolver := typeinfo.NewUserResolver(userCacheTTL)
+
 	if err = (&pdservice.PagerdutyServiceReconciler{
-		Client:   mgr.GetClient(),
-		Scheme:   mgr.GetScheme(),
-		Recorder: mgr.GetEventRecorderFor("pagerduty-service-controller"),
+		Client:                mgr.GetClient(),
+		Scheme:                mgr.GetScheme(),
+		Recorder:              mgr.GetEventRecorderFor("pagerduty-service-controller"),
+		ClientProvider:        clientProvider,
+		NewAdapter:            pdservice.NewPDServiceAdapter,
+		DefaultDeletionPolicy: deletionPolicy,
+		ResyncPeriod:          resyncPeriod,
+		DefaultMode:           defaultMode,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "PagerdutyService")
 		os.Exit(1)
 	}
 	if err = (&escalation_policy.EscalationPolicyReconciler{
-		Client:   mgr.GetClient(),
-		Scheme:   mgr.GetScheme(),
-		Recorder: mgr.GetEventRecorderFor("pagerduty-escalation-policy-controller"),
-		Adapter: ep.EPAdapter{
-			Logger:    mgr.GetLogger().WithName("EP Adapter"),
-			PD_Client: pagerduty.NewClient(""),
-		},
+		Client:                mgr.GetClient(),
+		Scheme:                mgr.GetScheme(),
+		Recorder:              mgr.GetEventRecorderFor("pagerduty-escalation-policy-controller"),
+		ClientProvider:        clientProvider,
+		NewAdapter:            ep.NewEPAdapter,
+		UserResolver:          userResolver,
+		DefaultDeletionPolicy: deletionPolicy,
+		ResyncPeriod:          resyncPeriod,
+		DefaultMode:           defaultMode,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "EscalationPolicy")
 		os.Exit(1)
 	}
 	if err = (&business_service.BusinessServiceReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:                mgr.GetClient(),
+		Scheme:                mgr.GetScheme(),
+		Recorder:              mgr.GetEventRecorderFor("pagerduty-business-service-controller"),
+		ClientProvider:        clientProvider,
+		NewAdapter:            business_service.NewBSAdapter,
+		UserResolver:          userResolver,
+		DefaultDeletionPolicy: deletionPolicy,
+		ResyncPeriod:          resyncPeriod,
+		DefaultMode:           defaultMode,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "BusinessService")
 		os.Exit(1)
 	}
+	if err = (&schedule.ScheduleReconciler{
+		Client:                mgr.GetClient(),
+		Scheme:                mgr.GetScheme(),
+		Recorder:              mgr.GetEventRecorderFor("pagerduty-schedule-controller"),
+		ClientProvider:        clientProvider,
+		NewAdapter:            schedule.NewScheduleAdapter,
+		DefaultDeletionPolicy: deletionPolicy,
+		ResyncPeriod:          resyncPeriod,
+		DefaultMode:           defaultMode,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Schedule")
+		os.Exit(1)
+	}
+	if err = (&team.TeamReconciler{
+		Client:                mgr.GetClient(),
+		Scheme:                mgr.GetScheme(),
+		Recorder:              mgr.GetEventRecorderFor("pagerduty-team-controller"),
+		ClientProvider:        clientProvider,
+		NewAdapter:            team.NewTeamAdapter,
+		UserResolver:          userResolver,
+		DefaultDeletionPolicy: deletionPolicy,
+		ResyncPeriod:          resyncPeriod,
+		DefaultMode:           defaultMode,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Team")
+		os.Exit(1)
+	}
+	if enableAlertmanagerConfig {
+		if err = (&alertmanager.AlertmanagerConfigReconciler{
+			Client:   mgr.GetClient(),
+			Scheme:   mgr.GetScheme(),
+			Recorder: mgr.GetEventRecorderFor("pagerduty-alertmanager-controller"),
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "AlertmanagerConfig")
+			os.Exit(1)
+		}
+	}
+	if enableWorkloadServices {
+		if err = workload.NewDeploymentReconciler(mgr.GetClient(), mgr.GetScheme(),
+			mgr.GetEventRecorderFor("pagerduty-workload-controller")).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "Deployment")
+			os.Exit(1)
+		}
+		if err = workload.NewStatefulSetReconciler(mgr.GetClient(), mgr.GetScheme(),
+			mgr.GetEventRecorderFor("pagerduty-workload-controller")).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "StatefulSet")
+			os.Exit(1)
+		}
+	}
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err = webhooks.SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhooks")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
+	if err := metrics.Registry.Register(pd_metrics.NewResourceCollector(mgr.GetClient())); err != nil {
+		setupLog.Error(err, "unable to register the resource metrics")
+		os.Exit(1)
+	}
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
@@ -0,0 +1,87 @@
+package alertmanager
+
+import (
+	"gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// ReceiverAnnotation enables the AlertmanagerConfig of a PagerdutyService and names its receiver
+	ReceiverAnnotation = "pagerduty.platform.share-now.com/alertmanager-receiver"
+	// IntegrationAnnotation selects the integration whose key the receiver uses, by name.
+	// The first Prometheus integration, or else the first Events API v2 integration, is used when it is not set.
+	IntegrationAnnotation = "pagerduty.platform.share-now.com/alertmanager-integration"
+)
+
+// AlertmanagerConfigGVK is the prometheus-operator AlertmanagerConfig kind. It is handled as unstructured,
+// so the operator does not depend on the prometheus-operator API.
+var AlertmanagerConfigGVK = schema.GroupVersionKind{
+	Group:   "monitoring.coreos.com",
+	Version: "v1alpha1",
+	Kind:    "AlertmanagerConfig",
+}
+
+// configName returns the name of the AlertmanagerConfig generated for the service
+func configName(service *v1alpha1.PagerdutyService) string {
+	return service.Name + "-pagerduty"
+}
+
+// receiverIntegration returns the integration of the service whose key the receiver uses
+func receiverIntegration(service *v1alpha1.PagerdutyService) (v1alpha1.ServiceIntegration, bool) {
+	if name, ok := service.Annotations[IntegrationAnnotation]; ok {
+		for _, integration := range service.Spec.Integrations {
+			if integration.Name == name {
+				return integration, true
+			}
+		}
+		return v1alpha1.ServiceIntegration{}, false
+	}
+
+	for _, integrationType := range []string{v1alpha1.IntegrationTypePrometheus, v1alpha1.IntegrationTypeEventsAPIV2} {
+		for _, integration := range service.Spec.Integrations {
+			if integration.Type == integrationType {
+				return integration, true
+			}
+		}
+	}
+	return v1alpha1.ServiceIntegration{}, false
+}
+
+// newConfig returns an empty AlertmanagerConfig object for the service
+func newConfig(service *v1alpha1.PagerdutyService) *unstructured.Unstructured {
+	config := &unstructured.Unstructured{}
+	config.SetGroupVersionKind(AlertmanagerConfigGVK)
+	config.SetName(configName(service))
+	config.SetNamespace(service.Namespace)
+	return config
+}
+
+// configSpec renders the spec of the AlertmanagerConfig: a single receiver with a PagerDuty config
+// that reads the routing key from the integration Secret, and a route sending every alert to it
+func configSpec(receiver string, integration v1alpha1.ServiceIntegration) map[string]interface{} {
+	secretKey := integration.SecretKey
+	if secretKey == "" {
+		secretKey = v1alpha1.DefaultIntegrationSecretKey
+	}
+
+	return map[string]interface{}{
+		"route": map[string]interface{}{
+			"receiver": receiver,
+		},
+		"receivers": []interface{}{
+			map[string]interface{}{
+				"name": receiver,
+				"pagerdutyConfigs": []interface{}{
+					map[string]interface{}{
+						"routingKey": map[string]interface{}{
+							"name": integration.SecretName,
+							"key":  secretKey,
+						},
+						"sendResolved": true,
+					},
+				},
+			},
+		},
+	}
+}
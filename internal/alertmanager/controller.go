@@ -0,0 +1,113 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alertmanager
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	pagerdutyalpha1 "gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/k8s_utils"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_utils"
+)
+
+// AlertmanagerConfigReconciler keeps an AlertmanagerConfig in sync for every PagerdutyService
+// carrying the receiver annotation
+type AlertmanagerConfigReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+}
+
+type Subroutines interface {
+	RemoveConfig() (pd_utils.OperationResult, error)
+	EnsureIntegration() (pd_utils.OperationResult, error)
+	ReconcileConfig() (pd_utils.OperationResult, error)
+}
+
+//+kubebuilder:rbac:groups=pagerduty.platform.share-now.com,resources=pagerdutyservices,verbs=get;list;watch
+//+kubebuilder:rbac:groups=monitoring.coreos.com,resources=alertmanagerconfigs,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+
+// Reconcile renders the AlertmanagerConfig of a PagerdutyService from its integrations.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.14.1/pkg/reconcile
+func (r *AlertmanagerConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+	log.Info("Starting AlertmanagerConfig reconcile...")
+
+	pdService := &pagerdutyalpha1.PagerdutyService{}
+	err := r.Get(ctx, req.NamespacedName, pdService)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			// the AlertmanagerConfig is garbage collected together with its owner
+			log.Info("pagerdutyservice resource not found. Ignoring since object must be deleted")
+			return k8s_utils.DoNotRequeue()
+		}
+		log.Info("Failed to get pagerdutyservice")
+
+		return k8s_utils.RequeueWithError(err)
+	}
+
+	subroutineHandler := &SubroutineHandler{
+		Logger:           log.WithName("alertmanager controller"),
+		K8sClient:        r.Client,
+		Recorder:         r.Recorder,
+		PagerdutyService: pdService,
+	}
+
+	return r.ReconcileHandler(subroutineHandler)
+}
+
+type ReconcileOperation func() (pd_utils.OperationResult, error)
+
+func (r *AlertmanagerConfigReconciler) ReconcileHandler(subroutines Subroutines) (ctrl.Result, error) {
+	operations := []ReconcileOperation{
+		subroutines.RemoveConfig,
+		subroutines.EnsureIntegration,
+		subroutines.ReconcileConfig,
+	}
+	for _, operation := range operations {
+		result, err := operation()
+		if err != nil || result.RequeueRequest {
+			return ctrl.Result{RequeueAfter: result.RequeueDelay}, err
+		}
+		if result.CancelRequest {
+			return ctrl.Result{}, nil
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+// It requires the prometheus-operator CRDs to be installed, since it watches the AlertmanagerConfigs it owns.
+func (r *AlertmanagerConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	config := newConfig(&pagerdutyalpha1.PagerdutyService{})
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("alertmanagerconfig").
+		For(&pagerdutyalpha1.PagerdutyService{}).
+		Owns(config).
+		Complete(r)
+}
@@ -0,0 +1,130 @@
+package alertmanager
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
+)
+
+var _ = Describe("AlertmanagerConfig controller", func() {
+	var (
+		k8sClient  client.Client
+		reconciler *AlertmanagerConfigReconciler
+		service    *v1alpha1.PagerdutyService
+	)
+
+	reconcile := func() (ctrl.Result, error) {
+		return reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(service)})
+	}
+
+	getConfig := func() (*unstructured.Unstructured, error) {
+		config := newConfig(service)
+		err := k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(config), config)
+		return config, err
+	}
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+
+		service = &v1alpha1.PagerdutyService{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "checkout",
+				Namespace:   "shop",
+				UID:         "checkout-uid",
+				Annotations: map[string]string{ReceiverAnnotation: "checkout-pagerduty"},
+			},
+			Spec: v1alpha1.PagerdutyServiceSpec{
+				Integrations: []v1alpha1.ServiceIntegration{
+					{Name: "events", Type: v1alpha1.IntegrationTypeEventsAPIV2, SecretName: "checkout-events"},
+					{Name: "alertmanager", Type: v1alpha1.IntegrationTypePrometheus, SecretName: "checkout-alertmanager", SecretKey: "routing_key"},
+				},
+			},
+			Status: v1alpha1.PagerdutyServiceStatus{
+				Integrations: []v1alpha1.IntegrationStatus{
+					{Name: "events", ID: "PEVENTS", SecretName: "checkout-events"},
+					{Name: "alertmanager", ID: "PALERTMANAGER", Vendor: "PAM4FGS", SecretName: "checkout-alertmanager"},
+				},
+			},
+		}
+
+		k8sClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(service).Build()
+		reconciler = &AlertmanagerConfigReconciler{
+			Client:   k8sClient,
+			Scheme:   scheme,
+			Recorder: record.NewFakeRecorder(10),
+		}
+	})
+
+	It("Should render a receiver reading the routing key of the Prometheus integration", func() {
+		_, err := reconcile()
+		Expect(err).NotTo(HaveOccurred())
+
+		config, err := getConfig()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(metav1.IsControlledBy(config, service)).To(BeTrue())
+
+		receiver, found, err := unstructured.NestedString(config.Object, "spec", "route", "receiver")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeTrue())
+		Expect(receiver).To(Equal("checkout-pagerduty"))
+
+		receivers, _, _ := unstructured.NestedSlice(config.Object, "spec", "receivers")
+		Expect(receivers).To(HaveLen(1))
+		pagerdutyConfigs, _, _ := unstructured.NestedSlice(receivers[0].(map[string]interface{}), "pagerdutyConfigs")
+		routingKey, _, _ := unstructured.NestedStringMap(pagerdutyConfigs[0].(map[string]interface{}), "routingKey")
+		Expect(routingKey).To(Equal(map[string]string{"name": "checkout-alertmanager", "key": "routing_key"}))
+	})
+
+	It("Should use the integration selected by annotation", func() {
+		service.Annotations[IntegrationAnnotation] = "events"
+		Expect(k8sClient.Update(context.TODO(), service)).To(Succeed())
+
+		_, err := reconcile()
+		Expect(err).NotTo(HaveOccurred())
+
+		config, err := getConfig()
+		Expect(err).NotTo(HaveOccurred())
+		receivers, _, _ := unstructured.NestedSlice(config.Object, "spec", "receivers")
+		pagerdutyConfigs, _, _ := unstructured.NestedSlice(receivers[0].(map[string]interface{}), "pagerdutyConfigs")
+		routingKey, _, _ := unstructured.NestedStringMap(pagerdutyConfigs[0].(map[string]interface{}), "routingKey")
+		Expect(routingKey).To(Equal(map[string]string{"name": "checkout-events", "key": "integration_key"}))
+	})
+
+	It("Should wait until the integration key has been written", func() {
+		service.Status.Integrations = nil
+		Expect(k8sClient.Update(context.TODO(), service)).To(Succeed())
+
+		result, err := reconcile()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(RequeWaitTime))
+
+		_, err = getConfig()
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Should delete the AlertmanagerConfig when the annotation is removed", func() {
+		_, err := reconcile()
+		Expect(err).NotTo(HaveOccurred())
+		_, err = getConfig()
+		Expect(err).NotTo(HaveOccurred())
+
+		delete(service.Annotations, ReceiverAnnotation)
+		Expect(k8sClient.Update(context.TODO(), service)).To(Succeed())
+
+		_, err = reconcile()
+		Expect(err).NotTo(HaveOccurred())
+		_, err = getConfig()
+		Expect(err).To(HaveOccurred())
+	})
+})
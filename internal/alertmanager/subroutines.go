@@ -0,0 +1,99 @@
+package alertmanager
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const RequeWaitTime = time.Second * 20
+
+type SubroutineHandler struct {
+	PagerdutyService *v1alpha1.PagerdutyService
+	Logger           logr.Logger
+	K8sClient        client.Client
+	Recorder         record.EventRecorder
+	integration      v1alpha1.ServiceIntegration
+}
+
+// RemoveConfig deletes the AlertmanagerConfig of services that are being deleted or no longer carry the
+// receiver annotation
+func (e *SubroutineHandler) RemoveConfig() (pd_utils.OperationResult, error) {
+	if _, ok := e.PagerdutyService.Annotations[ReceiverAnnotation]; ok && e.PagerdutyService.GetDeletionTimestamp().IsZero() {
+		return pd_utils.ContinueProcessing()
+	}
+
+	config := newConfig(e.PagerdutyService)
+	err := e.K8sClient.Get(context.TODO(), client.ObjectKeyFromObject(config), config)
+	if err != nil {
+		return pd_utils.RequeueOnErrorOrStop(client.IgnoreNotFound(err))
+	}
+
+	if !metav1.IsControlledBy(config, e.PagerdutyService) {
+		e.Logger.Info("AlertmanagerConfig not owned by the PagerDuty Service, keeping it...", "config", config.GetName())
+		return pd_utils.StopProcessing()
+	}
+
+	e.Logger.Info("Receiver annotation removed, deleting AlertmanagerConfig...", "config", config.GetName())
+	err = client.IgnoreNotFound(e.K8sClient.Delete(context.TODO(), config))
+	return pd_utils.RequeueOnErrorOrStop(err)
+}
+
+// EnsureIntegration waits until the integration used by the receiver has its key written into a Secret
+func (e *SubroutineHandler) EnsureIntegration() (pd_utils.OperationResult, error) {
+	integration, ok := receiverIntegration(e.PagerdutyService)
+	if !ok {
+		e.Logger.Info("No integration usable by Alertmanager found")
+		e.Recorder.Event(e.PagerdutyService, corev1.EventTypeWarning, "AlertmanagerIntegrationMissing",
+			"No Prometheus or Events API v2 integration found for the Alertmanager receiver")
+		return pd_utils.StopProcessing()
+	}
+
+	for _, created := range e.PagerdutyService.Status.Integrations {
+		if created.Name == integration.Name && created.SecretName == integration.SecretName {
+			e.integration = integration
+			return pd_utils.ContinueProcessing()
+		}
+	}
+
+	e.Logger.Info("Integration key not written yet, waiting...", "integration", integration.Name)
+	return pd_utils.RequeueAfter(RequeWaitTime, nil)
+}
+
+// ReconcileConfig creates or updates the AlertmanagerConfig of the service
+func (e *SubroutineHandler) ReconcileConfig() (pd_utils.OperationResult, error) {
+	receiver := e.PagerdutyService.Annotations[ReceiverAnnotation]
+	if receiver == "" {
+		receiver = e.PagerdutyService.Name
+	}
+
+	config := newConfig(e.PagerdutyService)
+	result, err := controllerutil.CreateOrUpdate(context.TODO(), e.K8sClient, config, func() error {
+		if owner := metav1.GetControllerOf(config); owner != nil && owner.UID != e.PagerdutyService.UID {
+			return fmt.Errorf("AlertmanagerConfig %s is not owned by PagerDuty Service %s", config.GetName(), e.PagerdutyService.Name)
+		}
+
+		config.Object["spec"] = configSpec(receiver, e.integration)
+		return controllerutil.SetControllerReference(e.PagerdutyService, config, e.K8sClient.Scheme())
+	})
+	if err != nil {
+		e.Logger.Error(err, "Failed to sync AlertmanagerConfig", "config", config.GetName())
+		return pd_utils.RequeueAfter(RequeWaitTime, err)
+	}
+
+	if result != controllerutil.OperationResultNone {
+		e.Logger.Info("AlertmanagerConfig synced", "config", config.GetName(), "result", result)
+		e.Recorder.Eventf(e.PagerdutyService, corev1.EventTypeNormal, "AlertmanagerConfigSynced",
+			"AlertmanagerConfig %s %s", config.GetName(), result)
+	}
+	return pd_utils.StopProcessing()
+}
@@ -0,0 +1,14 @@
+package alertmanager
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestAlertmanager(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "Alertmanager Suite")
+}
@@ -7,23 +7,46 @@ import (
 	"github.com/PagerDuty/go-pagerduty"
 	"github.com/go-logr/logr"
 	"gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/observe"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_metrics"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_utils"
 )
 
 type Adapter interface {
-	convert(*v1alpha1.BusinessServiceSpec) pagerduty.BusinessService
-	CreateBusinessService(k8sPDEscalationPolicy *v1alpha1.BusinessServiceSpec) (string, error)
+	CreateBusinessService(*v1alpha1.BusinessServiceSpec) (string, error)
 	GetBusinessService(string) (*pagerduty.BusinessService, error)
 	DeleteBusinessService(string) error
 	UpdateBusinessService(*v1alpha1.BusinessService) error
-	EqualToUpstream(v1alpha1.BusinessService) (bool, error)
+	UpstreamDrift(v1alpha1.BusinessService) (observe.Diff, error)
+	GetSupportingServices(string) ([]*pagerduty.ServiceDependency, error)
+	AssociateSupportingServices(string, []*pagerduty.ServiceObj) error
+	DisassociateSupportingServices([]*pagerduty.ServiceDependency) error
+	FindBusinessService(*v1alpha1.BusinessServiceSpec) (string, error)
+	RetainBusinessService(string) error
+}
+
+// AdapterFactory builds the Adapter used by a single reconcile on top of the given PagerDuty client
+type AdapterFactory func(ctx context.Context, logger logr.Logger, pdClient *pagerduty.Client) Adapter
+
+// NewBSAdapter is the AdapterFactory that talks to the PagerDuty API
+func NewBSAdapter(ctx context.Context, logger logr.Logger, pdClient *pagerduty.Client) Adapter {
+	return &BSAdapter{
+		Ctx:       ctx,
+		Logger:    logger,
+		PD_Client: pdClient,
+	}
 }
 
 type BSAdapter struct {
+	// Ctx is the context of the reconcile, cancelling it cancels the API calls of the adapter
+	Ctx       context.Context
 	Logger    logr.Logger
 	PD_Client *pagerduty.Client
 }
 
 var business_service_reference_type = "business_service"
+var service_dependency_business_service_type = "business_service"
+var service_dependency_technical_service_type = "service"
 
 func (adapter *BSAdapter) convert(bsService *v1alpha1.BusinessService) *pagerduty.BusinessService {
 	if bsService.Spec.TeamID == "" {
@@ -71,7 +94,7 @@ func (spec *BSAdapter) convertSpec(bsService *v1alpha1.BusinessServiceSpec) *pag
 
 func (adapter *BSAdapter) CreateBusinessService(k8sBusinessService *v1alpha1.BusinessServiceSpec) (string, error) {
 
-	res, err := adapter.PD_Client.CreateBusinessServiceWithContext(context.TODO(), adapter.convertSpec(k8sBusinessService))
+	res, err := adapter.PD_Client.CreateBusinessServiceWithContext(pd_metrics.Method(adapter.Ctx, "CreateBusinessService"), adapter.convertSpec(k8sBusinessService))
 	if err != nil {
 		adapter.Logger.Error(err, "Business Service creation unsuccessfull...")
 		return "", err
@@ -83,7 +106,7 @@ func (adapter *BSAdapter) CreateBusinessService(k8sBusinessService *v1alpha1.Bus
 func (adapter *BSAdapter) DeleteBusinessService(id string) error {
 	adapter.Logger.Info("Deleting policy...")
 
-	err := adapter.PD_Client.DeleteBusinessServiceWithContext(context.TODO(), id)
+	err := adapter.PD_Client.DeleteBusinessServiceWithContext(pd_metrics.Method(adapter.Ctx, "DeleteBusinessService"), id)
 	if err != nil {
 		adapter.Logger.Error(err, "ERROR: Failed to delete Business Service")
 		return err
@@ -93,11 +116,30 @@ func (adapter *BSAdapter) DeleteBusinessService(id string) error {
 	return nil
 }
 
+// RetainBusinessService renames the upstream Business Service with the retained suffix instead of deleting it
+func (adapter *BSAdapter) RetainBusinessService(id string) error {
+	adapter.Logger.Info("Retaining Business Service...")
+
+	businessService, err := adapter.GetBusinessService(id)
+	if err != nil {
+		return err
+	}
+
+	businessService.Name = pd_utils.RetainedName(businessService.Name)
+	if _, err := adapter.PD_Client.UpdateBusinessServiceWithContext(pd_metrics.Method(adapter.Ctx, "RetainBusinessService"), businessService); err != nil {
+		adapter.Logger.Error(err, "API Failed to rename retained Business Service")
+		return err
+	}
+
+	adapter.Logger.Info("Business Service retained...", "name", businessService.Name)
+	return nil
+}
+
 func (adapter *BSAdapter) UpdateBusinessService(k8sBusinessService *v1alpha1.BusinessService) error {
 
 	adapter.Logger.Info("Updating Business Service...")
 	_, err := adapter.PD_Client.UpdateBusinessServiceWithContext(
-		context.TODO(),
+		pd_metrics.Method(adapter.Ctx, "UpdateBusinessService"),
 		adapter.convert(k8sBusinessService),
 	)
 
@@ -110,27 +152,34 @@ func (adapter *BSAdapter) UpdateBusinessService(k8sBusinessService *v1alpha1.Bus
 	return nil
 }
 
-func (adapter *BSAdapter) EqualToUpstream(k8sBusinessService v1alpha1.BusinessService) (bool, error) {
+// UpstreamDrift returns the spec fields whose value differs from the upstream Business Service
+func (adapter *BSAdapter) UpstreamDrift(k8sBusinessService v1alpha1.BusinessService) (observe.Diff, error) {
 	businessService, err := adapter.GetBusinessService(k8sBusinessService.Status.BusinessServiceID)
 	if err != nil {
 		adapter.Logger.Error(err, "Failed to get upstream Business Service")
-		return false, err
+		return nil, err
 	}
 
-	if businessService.Team == nil {
-		return k8sBusinessService.Spec.Name == businessService.Name &&
-			k8sBusinessService.Spec.Description == businessService.Description &&
-			k8sBusinessService.Spec.PointOfContact == businessService.PointOfContact, nil
+	return businessServiceDiff(&k8sBusinessService.Spec, businessService), nil
+}
+
+// businessServiceDiff compares the spec with the upstream Business Service field by field.
+// The team is only compared when it is set upstream, like before drift reports existed.
+func businessServiceDiff(spec *v1alpha1.BusinessServiceSpec, businessService *pagerduty.BusinessService) observe.Diff {
+	var diff observe.Diff
+
+	diff.Compare("name", spec.Name, businessService.Name)
+	diff.Compare("description", spec.Description, businessService.Description)
+	diff.Compare("point_of_contact", spec.PointOfContact, businessService.PointOfContact)
+	if businessService.Team != nil {
+		diff.Compare("team", string(spec.TeamID), businessService.Team.ID)
 	}
 
-	return k8sBusinessService.Spec.Name == businessService.Name &&
-		k8sBusinessService.Spec.Description == businessService.Description &&
-		k8sBusinessService.Spec.PointOfContact == businessService.PointOfContact &&
-		k8sBusinessService.Spec.TeamID == businessService.Team.ID, nil
+	return diff
 }
 
 func (adapter *BSAdapter) GetBusinessService(id string) (*pagerduty.BusinessService, error) {
-	businessService, err := adapter.PD_Client.GetBusinessServiceWithContext(context.TODO(), id)
+	businessService, err := adapter.PD_Client.GetBusinessServiceWithContext(pd_metrics.Method(adapter.Ctx, "GetBusinessService"), id)
 	if err != nil {
 		adapter.Logger.Error(err, "Failed to get Business Service")
 		return nil, err
@@ -139,3 +188,140 @@ func (adapter *BSAdapter) GetBusinessService(id string) (*pagerduty.BusinessServ
 	adapter.Logger.Info("Business Service retrieved", "businessService", businessService)
 	return businessService, nil
 }
+
+// FindBusinessService returns the ID of the upstream Business Service selected by spec.adopt, or an empty ID if there is none to adopt
+func (adapter *BSAdapter) FindBusinessService(spec *v1alpha1.BusinessServiceSpec) (string, error) {
+	if spec.Adopt == nil {
+		return "", nil
+	}
+
+	if spec.Adopt.ID != "" {
+		businessService, err := adapter.GetBusinessService(spec.Adopt.ID)
+		if err != nil {
+			return "", err
+		}
+		return businessService.ID, nil
+	}
+
+	if !spec.Adopt.ByName {
+		return "", nil
+	}
+
+	businessServices, err := adapter.PD_Client.ListBusinessServicesPaginated(pd_metrics.Method(adapter.Ctx, "FindBusinessService"), pagerduty.ListBusinessServiceOptions{})
+	if err != nil {
+		adapter.Logger.Error(err, "Failed to list Business Services")
+		return "", err
+	}
+
+	var ids []string
+	for _, businessService := range businessServices {
+		if businessService.Name == spec.Name {
+			ids = append(ids, businessService.ID)
+		}
+	}
+	return pd_utils.SingleMatch("business service", spec.Name, ids)
+}
+
+// GetSupportingServices returns the dependencies in which the Business Service is the dependent service
+func (adapter *BSAdapter) GetSupportingServices(businessServiceID string) ([]*pagerduty.ServiceDependency, error) {
+	dependencies, err := adapter.PD_Client.ListBusinessServiceDependenciesWithContext(pd_metrics.Method(adapter.Ctx, "GetSupportingServices"), businessServiceID)
+	if err != nil {
+		adapter.Logger.Error(err, "Failed to list Business Service dependencies")
+		return nil, err
+	}
+
+	return supportingServicesOf(businessServiceID, dependencies.Relationships), nil
+}
+
+func (adapter *BSAdapter) AssociateSupportingServices(businessServiceID string, supportingServices []*pagerduty.ServiceObj) error {
+	adapter.Logger.Info("Associating supporting services...", "supportingServices", len(supportingServices))
+
+	_, err := adapter.PD_Client.AssociateServiceDependenciesWithContext(pd_metrics.Method(adapter.Ctx, "AssociateSupportingServices"), &pagerduty.ListServiceDependencies{
+		Relationships: newDependencies(businessServiceID, supportingServices),
+	})
+	if err != nil {
+		adapter.Logger.Error(err, "API Failed to associate Business Service dependencies")
+		return err
+	}
+
+	return nil
+}
+
+func (adapter *BSAdapter) DisassociateSupportingServices(dependencies []*pagerduty.ServiceDependency) error {
+	adapter.Logger.Info("Disassociating supporting services...", "supportingServices", len(dependencies))
+
+	_, err := adapter.PD_Client.DisassociateServiceDependenciesWithContext(pd_metrics.Method(adapter.Ctx, "DisassociateSupportingServices"), &pagerduty.ListServiceDependencies{
+		Relationships: dependencies,
+	})
+	if err != nil {
+		adapter.Logger.Error(err, "API Failed to disassociate Business Service dependencies")
+		return err
+	}
+
+	return nil
+}
+
+// supportingServicesOf filters out the dependencies in which the Business Service is the supporting service
+func supportingServicesOf(businessServiceID string, dependencies []*pagerduty.ServiceDependency) []*pagerduty.ServiceDependency {
+	var supporting []*pagerduty.ServiceDependency
+	for _, dependency := range dependencies {
+		if dependency.DependentService != nil && dependency.SupportingService != nil &&
+			dependency.DependentService.ID == businessServiceID {
+			supporting = append(supporting, dependency)
+		}
+	}
+	return supporting
+}
+
+func newDependencies(businessServiceID string, supportingServices []*pagerduty.ServiceObj) []*pagerduty.ServiceDependency {
+	dependencies := make([]*pagerduty.ServiceDependency, len(supportingServices))
+	for i, supportingService := range supportingServices {
+		dependencies[i] = &pagerduty.ServiceDependency{
+			SupportingService: supportingService,
+			DependentService: &pagerduty.ServiceObj{
+				ID:   businessServiceID,
+				Type: service_dependency_business_service_type,
+			},
+		}
+	}
+	return dependencies
+}
+
+// diffServiceDependencies returns the supporting services missing upstream and the upstream dependencies
+// that are no longer part of the spec
+func diffServiceDependencies(desired []*pagerduty.ServiceObj, observed []*pagerduty.ServiceDependency) ([]*pagerduty.ServiceObj, []*pagerduty.ServiceDependency) {
+	wanted := make(map[pagerduty.ServiceObj]bool, len(desired))
+	for _, supportingService := range desired {
+		wanted[*supportingService] = true
+	}
+
+	var toDisassociate []*pagerduty.ServiceDependency
+	existing := make(map[pagerduty.ServiceObj]bool, len(observed))
+	for _, dependency := range observed {
+		supportingService := *dependency.SupportingService
+		existing[supportingService] = true
+		if !wanted[supportingService] {
+			toDisassociate = append(toDisassociate, dependency)
+		}
+	}
+
+	var toAssociate []*pagerduty.ServiceObj
+	for _, supportingService := range desired {
+		if !existing[*supportingService] {
+			toAssociate = append(toAssociate, supportingService)
+			existing[*supportingService] = true
+		}
+	}
+
+	return toAssociate, toDisassociate
+}
+
+// businessServiceSnapshot returns the managed fields of the upstream Business Service
+func businessServiceSnapshot(businessService *pagerduty.BusinessService) *v1alpha1.UpstreamSnapshot {
+	snapshot := &v1alpha1.UpstreamSnapshot{Name: businessService.Name, Description: businessService.Description}
+	snapshot.SetField("point_of_contact", businessService.PointOfContact)
+	if businessService.Team != nil {
+		snapshot.SetField("team", businessService.Team.ID)
+	}
+	return snapshot
+}
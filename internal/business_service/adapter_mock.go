@@ -1,11 +1,14 @@
 package business_service
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/PagerDuty/go-pagerduty"
 	"github.com/go-logr/logr"
 	"gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/observe"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_utils"
 )
 
 type BSMockAdapter struct {
@@ -17,7 +20,11 @@ var Default_busService_description = "default_busService_description"
 var Default_busService_pointOfContact = "TestUser"
 var Default_busService_teamID = "MOCKTEAMID"
 
+var Default_busService_pointOfContactEmail = "mock.owner@share-now.com"
+var userIDs map[string]string = map[string]string{Default_busService_pointOfContactEmail: "MOCKOWNERID"}
+
 var busServices map[string]pagerduty.BusinessService = make(map[string]pagerduty.BusinessService)
+var busServiceDependencies map[string][]*pagerduty.ServiceDependency = make(map[string][]*pagerduty.ServiceDependency)
 
 func (adapter *BSMockAdapter) convert(bsService *v1alpha1.BusinessService) *pagerduty.BusinessService {
 	if bsService.Spec.TeamID == "" {
@@ -77,23 +84,33 @@ func (adapter *BSMockAdapter) DeleteBusinessService(id string) error {
 	return nil
 }
 
+func (adapter *BSMockAdapter) RetainBusinessService(id string) error {
+	busService, ok := busServices[id]
+	if !ok {
+		return fmt.Errorf("busService not found")
+	}
+
+	busService.Name = pd_utils.RetainedName(busService.Name)
+	busServices[id] = busService
+
+	adapter.Logger.Info("busService retained...")
+	return nil
+}
+
 func (adapter *BSMockAdapter) UpdateBusinessService(k8sPDBusinessService *v1alpha1.BusinessService) error {
 	busServices[k8sPDBusinessService.Status.BusinessServiceID] = *adapter.convert(k8sPDBusinessService)
 	adapter.Logger.Info("Upstream Escalation busService updated...")
 	return nil
 }
 
-func (adapter *BSMockAdapter) EqualToUpstream(k8sBusinessService v1alpha1.BusinessService) (bool, error) {
+func (adapter *BSMockAdapter) UpstreamDrift(k8sBusinessService v1alpha1.BusinessService) (observe.Diff, error) {
 	PDPolicy, err := adapter.GetBusinessService(k8sBusinessService.Status.BusinessServiceID)
 	if err != nil {
 		adapter.Logger.Error(err, "Failed to get Escalation busService")
-		return false, err
+		return nil, err
 	}
 
-	return k8sBusinessService.Spec.Name == PDPolicy.Name &&
-		k8sBusinessService.Spec.Description == PDPolicy.Description &&
-		k8sBusinessService.Spec.PointOfContact == PDPolicy.PointOfContact &&
-		k8sBusinessService.Spec.TeamID == PDPolicy.Team.ID, nil
+	return businessServiceDiff(&k8sBusinessService.Spec, PDPolicy), nil
 }
 
 func (adapter *BSMockAdapter) GetBusinessService(id string) (*pagerduty.BusinessService, error) {
@@ -104,3 +121,56 @@ func (adapter *BSMockAdapter) GetBusinessService(id string) (*pagerduty.Business
 
 	return &busService, nil
 }
+
+func (adapter *BSMockAdapter) GetSupportingServices(businessServiceID string) ([]*pagerduty.ServiceDependency, error) {
+	return busServiceDependencies[businessServiceID], nil
+}
+
+func (adapter *BSMockAdapter) AssociateSupportingServices(businessServiceID string, supportingServices []*pagerduty.ServiceObj) error {
+	for _, dependency := range newDependencies(businessServiceID, supportingServices) {
+		dependency.ID = businessServiceID + "-" + dependency.SupportingService.ID
+		busServiceDependencies[businessServiceID] = append(busServiceDependencies[businessServiceID], dependency)
+	}
+
+	adapter.Logger.Info("busService dependencies associated...")
+	return nil
+}
+
+func (adapter *BSMockAdapter) DisassociateSupportingServices(dependencies []*pagerduty.ServiceDependency) error {
+	for _, dependency := range dependencies {
+		businessServiceID := dependency.DependentService.ID
+		remaining := busServiceDependencies[businessServiceID][:0]
+		for _, existing := range busServiceDependencies[businessServiceID] {
+			if existing.ID != dependency.ID {
+				remaining = append(remaining, existing)
+			}
+		}
+		busServiceDependencies[businessServiceID] = remaining
+	}
+
+	adapter.Logger.Info("busService dependencies disassociated...")
+	return nil
+}
+
+// mockUserLookup finds the users of userIDs instead of calling the PagerDuty API
+func mockUserLookup(ctx context.Context, pdClient *pagerduty.Client, email string) (string, error) {
+	return userIDs[email], nil
+}
+
+func (adapter *BSMockAdapter) FindBusinessService(spec *v1alpha1.BusinessServiceSpec) (string, error) {
+	if spec.Adopt == nil {
+		return "", nil
+	}
+
+	if spec.Adopt.ID != "" {
+		if _, ok := busServices[spec.Adopt.ID]; !ok {
+			return "", fmt.Errorf("busService not found")
+		}
+		return spec.Adopt.ID, nil
+	}
+
+	if _, ok := busServices[spec.Name]; spec.Adopt.ByName && ok {
+		return spec.Name, nil
+	}
+	return "", nil
+}
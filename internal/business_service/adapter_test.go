@@ -2,8 +2,12 @@ package business_service
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 
 	"github.com/PagerDuty/go-pagerduty"
+	"github.com/go-logr/logr"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
@@ -31,6 +35,7 @@ var _ = Describe("Business service adapter tests", func() {
 	pd_client := pagerduty.NewClient("")
 
 	adapter := BSAdapter{
+		Ctx:       context.TODO(),
 		PD_Client: pd_client,
 	}
 
@@ -120,3 +125,66 @@ var _ = Describe("Business service adapter tests", func() {
 
 	})
 })
+
+var _ = Describe("Business service dependencies", func() {
+	const BusServiceID = "BUSSERVICEID"
+
+	dependency := func(id, supportingID, supportingType string) *pagerduty.ServiceDependency {
+		return &pagerduty.ServiceDependency{
+			ID:                id,
+			SupportingService: &pagerduty.ServiceObj{ID: supportingID, Type: supportingType},
+			DependentService:  &pagerduty.ServiceObj{ID: BusServiceID, Type: "business_service"},
+		}
+	}
+
+	It("should only keep the dependencies in which the business service is dependent", func() {
+		dependent := dependency("DEP1", "SERVICEA", "service")
+		supporting := &pagerduty.ServiceDependency{
+			ID:                "DEP2",
+			SupportingService: &pagerduty.ServiceObj{ID: BusServiceID, Type: "business_service"},
+			DependentService:  &pagerduty.ServiceObj{ID: "OTHERBUSSERVICE", Type: "business_service"},
+		}
+
+		Expect(supportingServicesOf(BusServiceID, []*pagerduty.ServiceDependency{dependent, supporting})).To(ConsistOf(dependent))
+	})
+
+	It("should associate missing services and disassociate removed ones", func() {
+		kept := dependency("DEP1", "SERVICEA", "service")
+		removed := dependency("DEP2", "SERVICEB", "service")
+		desired := []*pagerduty.ServiceObj{
+			{ID: "SERVICEA", Type: "service"},
+			{ID: "OTHERBUSSERVICE", Type: "business_service"},
+		}
+
+		toAssociate, toDisassociate := diffServiceDependencies(desired, []*pagerduty.ServiceDependency{kept, removed})
+		Expect(toAssociate).To(ConsistOf(&pagerduty.ServiceObj{ID: "OTHERBUSSERVICE", Type: "business_service"}))
+		Expect(toDisassociate).To(ConsistOf(removed))
+	})
+
+	It("should not change anything when upstream matches the spec", func() {
+		toAssociate, toDisassociate := diffServiceDependencies(
+			[]*pagerduty.ServiceObj{{ID: "SERVICEA", Type: "service"}},
+			[]*pagerduty.ServiceDependency{dependency("DEP1", "SERVICEA", "service")},
+		)
+		Expect(toAssociate).To(BeEmpty())
+		Expect(toDisassociate).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Business service adoption", func() {
+	It("should list the business services and adopt the one named exactly like the spec", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.URL.Path).To(Equal("/business_services"))
+			fmt.Fprint(w, `{"business_services": [{"id": "POTHER", "name": "other-business-service"}, {"id": "PADOPTED", "name": "adopted-business-service"}], "more": false}`)
+		}))
+		defer server.Close()
+
+		adapter := &BSAdapter{
+			Ctx:       context.TODO(),
+			Logger:    logr.Discard(),
+			PD_Client: pagerduty.NewClient("token", pagerduty.WithAPIEndpoint(server.URL)),
+		}
+		spec := &v1alpha1.BusinessServiceSpec{Name: "adopted-business-service", Adopt: &v1alpha1.AdoptSpec{ByName: true}}
+		Expect(adapter.FindBusinessService(spec)).To(Equal("PADOPTED"))
+	})
+})
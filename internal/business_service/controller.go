@@ -18,8 +18,8 @@ package business_service
 
 import (
 	"context"
+	"time"
 
-	"github.com/PagerDuty/go-pagerduty"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
@@ -29,15 +29,31 @@ import (
 
 	pagerdutyalpha1 "gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
 	"gitlab.share-now.com/platform/pagerduty-operator/internal/condition"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/credentials"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/events"
 	"gitlab.share-now.com/platform/pagerduty-operator/internal/k8s_utils"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/observe"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/pause"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_errors"
 	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_utils"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/resync"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/typeinfo"
 )
 
 // BusinessServiceReconciler reconciles a BusinessService object
 type BusinessServiceReconciler struct {
 	client.Client
-	Scheme   *runtime.Scheme
-	Recorder record.EventRecorder
+	Scheme         *runtime.Scheme
+	Recorder       record.EventRecorder
+	ClientProvider credentials.ClientProvider
+	NewAdapter     AdapterFactory
+	UserResolver   *typeinfo.UserResolver
+	// DefaultDeletionPolicy applies to the resources that do not set a deletion policy
+	DefaultDeletionPolicy pagerdutyalpha1.DeletionPolicy
+	// ResyncPeriod defines how often healthy resources are compared with upstream, 0 disables the resync
+	ResyncPeriod time.Duration
+	// DefaultMode applies to the resources without a mode annotation
+	DefaultMode observe.Mode
 }
 
 type Subroutines interface {
@@ -46,11 +62,21 @@ type Subroutines interface {
 	ReconcileUpdate() (pd_utils.OperationResult, error)
 	Initialization() (pd_utils.OperationResult, error)
 	AddFinalizer() (pd_utils.OperationResult, error)
+	EnsureCredentials() (pd_utils.OperationResult, error)
+	ReconcileServiceDependencies() (pd_utils.OperationResult, error)
+	ResolvePointOfContact() (pd_utils.OperationResult, error)
+	ResolveTeam() (pd_utils.OperationResult, error)
+	CheckPaused() (pd_utils.OperationResult, error)
+	CheckInvalidSpec() (pd_utils.OperationResult, error)
+	ObserveUpstream() (pd_utils.OperationResult, error)
+	MarkSynced() (pd_utils.OperationResult, error)
 }
 
 //+kubebuilder:rbac:groups=pagerduty.platform.share-now.com,resources=businessservices,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=pagerduty.platform.share-now.com,resources=businessservices/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=pagerduty.platform.share-now.com,resources=businessservices/finalizers,verbs=update
+//+kubebuilder:rbac:groups=pagerduty.platform.share-now.com,resources=pagerdutyservices,verbs=get;list;watch
+//+kubebuilder:rbac:groups=pagerduty.platform.share-now.com,resources=teams,verbs=get;list;watch
 //+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 //+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
@@ -83,22 +109,48 @@ func (r *BusinessServiceReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		return k8s_utils.RequeueWithError(err)
 	}
 
+	pdClient, credentialsErr := r.ClientProvider.Client(ctx, businessService.Spec.AccountRef)
+
+	resyncInterval, err := resync.Interval(businessService, r.ResyncPeriod)
+	if err != nil {
+		log.Error(err, "Ignoring resync interval annotation")
+	}
+
+	mode, err := observe.ModeOf(businessService, r.DefaultMode)
+	if err != nil {
+		log.Error(err, "Ignoring mode annotation")
+	}
+
+	paused := pause.IsPaused(businessService)
+	deletionPolicy := observe.DeletionPolicy(mode, businessService.Spec.DeletionPolicy.OrDefault(r.DefaultDeletionPolicy))
+	if paused || pd_errors.SpecRejected(businessService.Status.Conditions, businessService.Generation) {
+		// removing the annotation or changing the spec triggers a reconcile, there is nothing to resync until then
+		resyncInterval = 0
+	}
+
 	subroutineHandler := &SubroutineHandler{
-		Logger:    log.WithName("business-service controller"),
-		K8sClient: r.Client,
-		BSAdapter: &BSAdapter{
-			Logger:    log,
-			PD_Client: pagerduty.NewClient(""),
-		},
+		Logger:           log.WithName("business-service controller"),
+		K8sClient:        r.Client,
+		Adapter:          r.NewAdapter(ctx, log.WithName("BS Adapter"), pdClient),
 		BusinessService:  businessService,
+		PDClient:         pdClient,
+		UserResolver:     r.UserResolver,
+		Events:           events.NewRecorder(r.Recorder, businessService, "Business Service"),
+		DeletionPolicy:   deletionPolicy,
+		Paused:           paused,
+		Observe:          mode == observe.ModeObserve,
+		ResyncInterval:   resyncInterval,
+		ctx:              ctx,
 		conditionManager: condition.NewConditionManager(),
+		credentialsErr:   credentialsErr,
 	}
 
 	result, err := r.ReconcileHandler(subroutineHandler)
 	// reason := "ReconcileError"
 	// _, _ = adapter.SetProjectClaimCondition(gcpv1alpha1.ConditionError, reason, err)
 
-	return result, err
+	// healthy resources are requeued, so changes made in PagerDuty are detected without waiting for a Kubernetes event
+	return resync.RequeueHealthy(result, err, resyncInterval)
 }
 
 type ReconcileOperation func() (pd_utils.OperationResult, error)
@@ -107,9 +159,17 @@ func (r *BusinessServiceReconciler) ReconcileHandler(subroutines Subroutines) (c
 	operations := []ReconcileOperation{
 		subroutines.Initialization,
 		subroutines.AddFinalizer,
+		subroutines.EnsureCredentials,
 		subroutines.ReconcileDeletion,
+		subroutines.CheckPaused,
+		subroutines.CheckInvalidSpec,
+		subroutines.ResolvePointOfContact,
+		subroutines.ResolveTeam,
+		subroutines.ObserveUpstream,
 		subroutines.ReconcileCreation,
+		subroutines.ReconcileServiceDependencies,
 		subroutines.ReconcileUpdate,
+		subroutines.MarkSynced,
 	}
 	for _, operation := range operations {
 		result, err := operation()
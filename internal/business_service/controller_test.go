@@ -9,6 +9,7 @@ import (
 	pagerdutyv1alpha1 "gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
 	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_utils"
 	core "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 )
@@ -161,4 +162,51 @@ var _ = Describe("Business Service controller", func() {
 			Expect(testEnv.BusService.Spec.TeamID).Should(Equal(Default_busService_teamID))
 		})
 	})
+
+	Context("When deleting a BusService with a deletion policy", func() {
+		BeforeEach(func() {
+			testEnv = setupTest()
+		})
+
+		AfterEach(func() {
+			cleanUp(testEnv)
+		})
+
+		deleteWithPolicy := func(deletionPolicy pagerdutyv1alpha1.DeletionPolicy) {
+			busServiceKey := types.NamespacedName{Name: Default_busService_name, Namespace: testEnv.BusServiceNamespace}
+			Eventually(func() string {
+				if err := k8sClient.Get(ctx, busServiceKey, testEnv.BusService); err != nil {
+					return ""
+				}
+				return testEnv.BusService.Status.BusinessServiceID
+			}, timeout, interval).Should(Equal(Default_busService_name))
+
+			testEnv.BusService.Spec.DeletionPolicy = deletionPolicy
+			Expect(k8sClient.Update(ctx, testEnv.BusService)).Should(Succeed())
+			Expect(k8sClient.Delete(ctx, testEnv.BusService)).Should(Succeed())
+
+			Eventually(func() bool {
+				return apierrors.IsNotFound(k8sClient.Get(ctx, busServiceKey, testEnv.BusService))
+			}, timeout, interval).Should(BeTrue())
+		}
+
+		It("Should delete the upstream BusService with the Delete policy", func() {
+			deleteWithPolicy(pagerdutyv1alpha1.DeletionPolicyDelete)
+			Expect(busServices).ShouldNot(HaveKey(Default_busService_name))
+		})
+
+		It("Should leave the upstream BusService untouched with the Orphan policy", func() {
+			deleteWithPolicy(pagerdutyv1alpha1.DeletionPolicyOrphan)
+			Expect(busServices).Should(HaveKey(Default_busService_name))
+			Expect(busServices[Default_busService_name].Name).Should(Equal(Default_busService_name))
+			delete(busServices, Default_busService_name)
+		})
+
+		It("Should rename the upstream BusService with the Retain policy", func() {
+			deleteWithPolicy(pagerdutyv1alpha1.DeletionPolicyRetain)
+			Expect(busServices).Should(HaveKey(Default_busService_name))
+			Expect(busServices[Default_busService_name].Name).Should(Equal(Default_busService_name + pd_utils.RetainedNameSuffix))
+			delete(busServices, Default_busService_name)
+		})
+	})
 })
@@ -4,14 +4,24 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"reflect"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/PagerDuty/go-pagerduty"
 	"github.com/go-logr/logr"
 	"gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
 	"gitlab.share-now.com/platform/pagerduty-operator/internal/condition"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/events"
 	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_errors"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_metrics"
 	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_utils"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/team"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/typeinfo"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
@@ -21,11 +31,23 @@ const businessServiceReady = "PDBusinessServiceReady"
 const RequeWaitTime = time.Second * 10
 
 type SubroutineHandler struct {
-	BusinessService  *v1alpha1.BusinessService
-	Logger           logr.Logger
-	K8sClient        client.Client
-	BSAdapter        *BSAdapter
+	BusinessService *v1alpha1.BusinessService
+	Logger          logr.Logger
+	K8sClient       client.Client
+	Adapter         Adapter
+	// PDClient looks users up by email through the UserResolver
+	PDClient       *pagerduty.Client
+	UserResolver   *typeinfo.UserResolver
+	Events         *events.Recorder
+	DeletionPolicy v1alpha1.DeletionPolicy
+	Paused         bool
+	Observe        bool
+	ResyncInterval time.Duration
+	// ctx is the context of the reconcile, it cancels the user lookups
+	ctx              context.Context
 	conditionManager condition.Conditions
+	credentialsErr   error
+	resolvedTeamID   string
 }
 
 func (e *SubroutineHandler) ReconcileCreation() (pd_utils.OperationResult, error) {
@@ -33,18 +55,34 @@ func (e *SubroutineHandler) ReconcileCreation() (pd_utils.OperationResult, error
 
 	// no upstream policy has been created yet
 	if !e.BusinessServiceIDExists() {
+		adoptedID, err := e.Adapter.FindBusinessService(&e.BusinessService.Spec)
+		if err != nil {
+			e.Logger.Error(err, "Failed to find the PD Business Service to adopt")
+			e.Events.Failed("Adopt", err)
+			return e.SetEscalationPolicyCondition(v1alpha1.ConditionReady, businessServiceReady, err, err.Error())
+		}
+
+		if adoptedID != "" {
+			e.Logger.Info("Adopting upstream Business Service...", "businessServiceID", adoptedID)
+			e.BusinessService.Status.BusinessServiceID = adoptedID
+			e.Events.Adopted(adoptedID)
+			return e.SetEscalationPolicyCondition(v1alpha1.ConditionReady, businessServiceReady, nil, "Business Service adopted")
+		}
+
 		e.Logger.Info("Upstream Business Service not found. Creating...")
 
 		// Handles creation of upstream policy
-		businessServiceID, err := e.BSAdapter.CreateBusinessService(&e.BusinessService.Spec)
+		businessServiceID, err := e.Adapter.CreateBusinessService(&e.resolvedBusinessService().Spec)
 		if err != nil {
 			e.Logger.Error(err, "Failed to create PD Business Service")
+			e.Events.Failed("Create", err)
 			return e.SetEscalationPolicyCondition(v1alpha1.ConditionReady, businessServiceReady, err, err.Error())
 		}
 
 		e.Logger.Info("Updating Business Service status...")
 		// TODO: Update the rest of the policy status/spec
 		e.BusinessService.Status.BusinessServiceID = businessServiceID
+		e.Events.Created(businessServiceID)
 		return e.SetEscalationPolicyCondition(v1alpha1.ConditionReady, businessServiceReady, nil, "Business Service created")
 	}
 
@@ -57,10 +95,20 @@ func (e *SubroutineHandler) ReconcileDeletion() (pd_utils.OperationResult, error
 	if e.deletionTimestampExists() {
 		e.Logger.Info("Deletion timestamp for Business Service found. Deleting...")
 
+		// a paused upstream object is only released untouched, any other policy waits for the pause to end
+		if e.Paused && e.DeletionPolicy != v1alpha1.DeletionPolicyOrphan && e.BusinessServiceIDExists() {
+			return e.status().BlockPausedDeletion(e.DeletionPolicy)
+		}
+
 		if e.BusinessServiceIDExists() {
-			e.Logger.Info("Upstream Business Service found, making API deletion call for Business Service ...")
-			if err := e.BSAdapter.DeleteBusinessService(e.BusinessService.Status.BusinessServiceID); err != nil {
+			err := e.deleteUpstream()
+			if pd_errors.IsNotFound(err) {
+				e.Logger.Info("Upstream Business Service already deleted...", "id", e.BusinessService.Status.BusinessServiceID)
+				err = nil
+			}
+			if err != nil {
 				e.Logger.Error(err, "Failed to delete Business Service")
+				e.Events.Failed("Delete", err)
 				return e.SetEscalationPolicyCondition(v1alpha1.ConditionReady, businessServiceReady, err, err.Error())
 			}
 		}
@@ -73,6 +121,32 @@ func (e *SubroutineHandler) ReconcileDeletion() (pd_utils.OperationResult, error
 	return pd_utils.ContinueProcessing()
 }
 
+// deleteUpstream applies the deletion policy to the upstream Business Service
+func (e *SubroutineHandler) deleteUpstream() error {
+	businessServiceID := e.BusinessService.Status.BusinessServiceID
+
+	switch e.DeletionPolicy {
+	case v1alpha1.DeletionPolicyOrphan:
+		e.Logger.Info("Deletion policy is Orphan, leaving upstream Business Service untouched...", "businessServiceID", businessServiceID)
+		e.Events.Orphaned(businessServiceID)
+		return nil
+	case v1alpha1.DeletionPolicyRetain:
+		e.Logger.Info("Deletion policy is Retain, renaming upstream Business Service...", "businessServiceID", businessServiceID)
+		if err := e.Adapter.RetainBusinessService(businessServiceID); err != nil {
+			return err
+		}
+		e.Events.Retained(businessServiceID)
+		return nil
+	default:
+		e.Logger.Info("Upstream Business Service found, making API deletion call for Business Service ...")
+		if err := e.Adapter.DeleteBusinessService(businessServiceID); err != nil {
+			return err
+		}
+		e.Events.Deleted(businessServiceID)
+		return nil
+	}
+}
+
 func (e *SubroutineHandler) ReconcileUpdate() (pd_utils.OperationResult, error) {
 	e.Logger.Info("Reconcile Business Service Update...")
 
@@ -81,22 +155,33 @@ func (e *SubroutineHandler) ReconcileUpdate() (pd_utils.OperationResult, error)
 		return pd_utils.ContinueProcessing()
 	}
 
-	equal, err := e.BSAdapter.EqualToUpstream(*e.BusinessService)
+	drifted, err := e.Adapter.UpstreamDrift(*e.resolvedBusinessService())
+	if pd_errors.IsNotFound(err) {
+		return e.ForgetUpstream()
+	}
 	if err != nil {
 		e.Logger.Error(err, "Failed to compare Business Service spec with upstream")
+		e.Events.Failed("Compare", err)
 		return e.SetEscalationPolicyCondition(v1alpha1.ConditionReady, businessServiceReady, err, err.Error())
 	}
 
-	if !equal {
-		e.Logger.Info("Business Service spec does not match upstream service. Updating...")
-		err := e.BSAdapter.UpdateBusinessService(e.BusinessService)
-
+	if len(drifted) > 0 {
+		e.Logger.Info("Business Service spec does not match upstream service. Updating...", "driftedFields", drifted.Fields())
+		err := e.Adapter.UpdateBusinessService(e.resolvedBusinessService())
+		if pd_errors.IsNotFound(err) {
+			return e.ForgetUpstream()
+		}
 		if err != nil {
 			e.Logger.Error(err, "Failed to update Business Service")
+			e.Events.Failed("Update", err)
 			return e.SetEscalationPolicyCondition(v1alpha1.ConditionReady, businessServiceReady, err, err.Error())
 		}
 
 		e.Logger.Info("Business Service changed...")
+		e.Events.Updated(e.BusinessService.Status.BusinessServiceID, drifted.Fields())
+		pd_metrics.DriftCorrected("BusinessService", e.BusinessService.Namespace)
+		// read again by MarkSynced
+		e.BusinessService.Status.Upstream = nil
 		return e.SetEscalationPolicyCondition(v1alpha1.ConditionReady, businessServiceReady, nil, "PagerDuty Business Service matches upstream service")
 	}
 
@@ -104,6 +189,247 @@ func (e *SubroutineHandler) ReconcileUpdate() (pd_utils.OperationResult, error)
 	return pd_utils.ContinueProcessing()
 }
 
+// ResolvePointOfContact resolves a point of contact in the form email:<address> to the ID of the PagerDuty user
+// and records it in the status. Other values are sent upstream as they are.
+func (e *SubroutineHandler) ResolvePointOfContact() (pd_utils.OperationResult, error) {
+	e.Logger.Info("Resolving Business Service point of contact...")
+
+	userID := ""
+	if email, ok := typeinfo.ParseEmailSelector(e.BusinessService.Spec.PointOfContact); ok {
+		var err error
+		userID, err = e.UserResolver.Resolve(pd_metrics.Method(e.ctx, "FindUserByEmail"), e.PDClient, e.BusinessService.Spec.AccountRef, email)
+		if err != nil {
+			e.Logger.Error(err, "Failed to look up point of contact", "email", email)
+			return e.SetEscalationPolicyCondition(v1alpha1.ConditionReady, businessServiceReady, err, err.Error())
+		}
+		if userID == "" {
+			err := fmt.Errorf("no PagerDuty user found with email %s", email)
+			return e.SetEscalationPolicyCondition(v1alpha1.ConditionReady, businessServiceReady, err, err.Error())
+		}
+	}
+
+	if userID == e.BusinessService.Status.PointOfContactUserID {
+		return pd_utils.ContinueProcessing()
+	}
+
+	e.Logger.Info("Recording point of contact user ID...", "userID", userID)
+	e.BusinessService.Status.PointOfContactUserID = userID
+	err := e.StatusUpdate()
+	return pd_utils.RequeueOnErrorOrContinue(err)
+}
+
+// ResolveTeam resolves the Team reference of the Business Service to the ID of the upstream team
+func (e *SubroutineHandler) ResolveTeam() (pd_utils.OperationResult, error) {
+	e.resolvedTeamID = e.BusinessService.Spec.TeamID
+	if e.BusinessService.Spec.TeamRef == "" {
+		return pd_utils.ContinueProcessing()
+	}
+
+	e.Logger.Info("Resolving Business Service team...")
+	key := types.NamespacedName{Namespace: e.BusinessService.Namespace, Name: e.BusinessService.Spec.TeamRef}
+	teamID, err := team.TeamID(context.TODO(), e.K8sClient, key, e.BusinessService.Spec.AccountRef)
+	if err == nil && teamID == "" {
+		err = fmt.Errorf("team %s has not been created in PagerDuty yet", key)
+	}
+	if err != nil {
+		e.Logger.Error(err, "Failed to resolve Team", "teamRef", e.BusinessService.Spec.TeamRef)
+		return e.SetEscalationPolicyCondition(v1alpha1.ConditionReady, businessServiceReady, err, err.Error())
+	}
+
+	e.resolvedTeamID = teamID
+	return pd_utils.ContinueProcessing()
+}
+
+// resolvedBusinessService returns a copy of the Business Service whose point of contact and team are resolved to IDs
+func (e *SubroutineHandler) resolvedBusinessService() *v1alpha1.BusinessService {
+	businessService := e.BusinessService.DeepCopy()
+	if businessService.Status.PointOfContactUserID != "" {
+		businessService.Spec.PointOfContact = businessService.Status.PointOfContactUserID
+	}
+	businessService.Spec.TeamID = e.resolvedTeamID
+	return businessService
+}
+
+// ReconcileServiceDependencies associates the supporting services of the spec with the upstream
+// Business Service and disassociates the ones that were removed from it
+func (e *SubroutineHandler) ReconcileServiceDependencies() (pd_utils.OperationResult, error) {
+	e.Logger.Info("Reconcile Business Service dependencies...")
+
+	if !e.BusinessServiceIDExists() {
+		e.Logger.Info("No upstream Business Service created yet. Skipping dependencies...")
+		return pd_utils.ContinueProcessing()
+	}
+
+	desired, err := e.supportingServices()
+	if err != nil {
+		e.Logger.Error(err, "Failed to resolve supporting services")
+		return e.SetEscalationPolicyCondition(v1alpha1.ConditionReady, businessServiceReady, err, err.Error())
+	}
+
+	businessServiceID := e.BusinessService.Status.BusinessServiceID
+	observed, err := e.Adapter.GetSupportingServices(businessServiceID)
+	if err != nil {
+		e.Logger.Error(err, "Failed to get upstream Business Service dependencies")
+		return e.SetEscalationPolicyCondition(v1alpha1.ConditionReady, businessServiceReady, err, err.Error())
+	}
+
+	toAssociate, toDisassociate := diffServiceDependencies(desired, observed)
+	if len(toAssociate) == 0 && len(toDisassociate) == 0 {
+		dependencies := dependencyStatus(observed)
+		if reflect.DeepEqual(dependencies, e.BusinessService.Status.ServiceDependencies) {
+			e.Logger.Info("Business Service dependencies not changed...")
+			return pd_utils.ContinueProcessing()
+		}
+
+		e.BusinessService.Status.ServiceDependencies = dependencies
+		err := e.StatusUpdate()
+		return pd_utils.RequeueOnErrorOrContinue(err)
+	}
+
+	if len(toDisassociate) > 0 {
+		e.Logger.Info("Removing Business Service dependencies no longer in spec...", "count", len(toDisassociate))
+		if err := e.Adapter.DisassociateSupportingServices(toDisassociate); err != nil {
+			e.Logger.Error(err, "Failed to disassociate Business Service dependencies")
+			e.Events.Warning("DependenciesFailed", "Failed to remove dependencies of PagerDuty Business Service %s: %s", businessServiceID, events.DescribeError(err))
+			return e.SetEscalationPolicyCondition(v1alpha1.ConditionReady, businessServiceReady, err, err.Error())
+		}
+	}
+
+	if len(toAssociate) > 0 {
+		e.Logger.Info("Adding Business Service dependencies...", "count", len(toAssociate))
+		if err := e.Adapter.AssociateSupportingServices(businessServiceID, toAssociate); err != nil {
+			e.Logger.Error(err, "Failed to associate Business Service dependencies")
+			e.Events.Warning("DependenciesFailed", "Failed to add dependencies of PagerDuty Business Service %s: %s", businessServiceID, events.DescribeError(err))
+			return e.SetEscalationPolicyCondition(v1alpha1.ConditionReady, businessServiceReady, err, err.Error())
+		}
+	}
+
+	e.Events.Normal("DependenciesUpdated", "Updated the supporting services of PagerDuty Business Service %s: %d added, %d removed", businessServiceID, len(toAssociate), len(toDisassociate))
+
+	observed, err = e.Adapter.GetSupportingServices(businessServiceID)
+	if err != nil {
+		e.Logger.Error(err, "Failed to get upstream Business Service dependencies")
+		return e.SetEscalationPolicyCondition(v1alpha1.ConditionReady, businessServiceReady, err, err.Error())
+	}
+
+	e.BusinessService.Status.ServiceDependencies = dependencyStatus(observed)
+	return e.SetEscalationPolicyCondition(v1alpha1.ConditionReady, businessServiceReady, nil, "Business Service dependencies updated")
+}
+
+// supportingServices resolves the supporting services of the spec to their upstream IDs.
+// It fails until every referenced resource exists and has been created in PagerDuty.
+func (e *SubroutineHandler) supportingServices() ([]*pagerduty.ServiceObj, error) {
+	var supporting []*pagerduty.ServiceObj
+
+	for _, ref := range e.BusinessService.Spec.SupportingServices {
+		key := e.referenceKey(ref.Name)
+
+		var id, accountRef, dependencyType string
+		switch ref.Kind {
+		case v1alpha1.SupportingServiceKindBusinessService:
+			businessService := &v1alpha1.BusinessService{}
+			if err := e.K8sClient.Get(context.TODO(), key, businessService); err != nil {
+				return nil, supportingServiceError(ref.Kind, key, err)
+			}
+			id, accountRef, dependencyType = businessService.Status.BusinessServiceID, businessService.Spec.AccountRef, service_dependency_business_service_type
+		default:
+			service := &v1alpha1.PagerdutyService{}
+			if err := e.K8sClient.Get(context.TODO(), key, service); err != nil {
+				return nil, supportingServiceError(ref.Kind, key, err)
+			}
+			id, accountRef, dependencyType = service.Status.ServiceID, service.Spec.AccountRef, service_dependency_technical_service_type
+		}
+
+		if accountRef != e.BusinessService.Spec.AccountRef {
+			return nil, fmt.Errorf("supporting %s %s uses account %q but the Business Service uses account %q",
+				ref.Kind, key, accountRef, e.BusinessService.Spec.AccountRef)
+		}
+		if id == "" {
+			return nil, fmt.Errorf("supporting %s %s has not been created in PagerDuty yet", ref.Kind, key)
+		}
+
+		supporting = append(supporting, &pagerduty.ServiceObj{ID: id, Type: dependencyType})
+	}
+
+	return supporting, nil
+}
+
+// referenceKey parses a reference in the form name or namespace/name
+func (e *SubroutineHandler) referenceKey(ref string) types.NamespacedName {
+	if namespace, name, found := strings.Cut(ref, "/"); found {
+		return types.NamespacedName{Namespace: namespace, Name: name}
+	}
+	return types.NamespacedName{Namespace: e.BusinessService.Namespace, Name: ref}
+}
+
+func supportingServiceError(kind string, key types.NamespacedName, err error) error {
+	if apierrors.IsNotFound(err) {
+		return fmt.Errorf("supporting %s %s not found", kind, key)
+	}
+	return pd_errors.Wrap(err, fmt.Sprintf("failed to get supporting %s %s", kind, key))
+}
+
+// dependencyStatus converts the upstream dependencies to their status representation, sorted by supporting service
+func dependencyStatus(dependencies []*pagerduty.ServiceDependency) []v1alpha1.ServiceDependency {
+	var status []v1alpha1.ServiceDependency
+	for _, dependency := range dependencies {
+		status = append(status, v1alpha1.ServiceDependency{
+			ID:                    dependency.ID,
+			SupportingServiceID:   dependency.SupportingService.ID,
+			SupportingServiceType: dependency.SupportingService.Type,
+		})
+	}
+
+	sort.Slice(status, func(i, j int) bool {
+		return status[i].SupportingServiceID < status[j].SupportingServiceID
+	})
+	return status
+}
+
+// CheckPaused ends the reconcile of a paused Business Service before any PagerDuty API call
+func (e *SubroutineHandler) CheckPaused() (pd_utils.OperationResult, error) {
+	return e.status().CheckPaused(e.Paused)
+}
+
+// ObserveUpstream compares the Business Service with upstream in observe mode, without changing PagerDuty.
+// The drift is reported in the DriftDetected condition, and the reconcile ends there.
+func (e *SubroutineHandler) ObserveUpstream() (pd_utils.OperationResult, error) {
+	if !e.Observe {
+		return pd_utils.ContinueProcessing()
+	}
+	e.Logger.Info("Observe mode, comparing Business Service with upstream...")
+
+	if !e.BusinessServiceIDExists() {
+		adoptedID, err := e.Adapter.FindBusinessService(&e.BusinessService.Spec)
+		if err != nil {
+			e.Logger.Error(err, "Failed to find the PD Business Service to observe")
+			e.Events.Failed("Adopt", err)
+			return e.SetEscalationPolicyCondition(v1alpha1.ConditionReady, businessServiceReady, err, err.Error())
+		}
+		if adoptedID == "" {
+			return e.status().SetDrift(false, nil)
+		}
+
+		e.Logger.Info("Observing upstream Business Service...", "id", adoptedID)
+		e.BusinessService.Status.BusinessServiceID = adoptedID
+		e.Events.Adopted(adoptedID)
+	}
+
+	drifted, err := e.Adapter.UpstreamDrift(*e.resolvedBusinessService())
+	if pd_errors.IsNotFound(err) {
+		e.Logger.Info("Observed upstream Business Service not found...", "id", e.BusinessService.Status.BusinessServiceID)
+		e.BusinessService.Status.BusinessServiceID = ""
+		return e.status().SetDrift(false, nil)
+	}
+	if err != nil {
+		e.Logger.Error(err, "Failed to compare Business Service spec with upstream")
+		e.Events.Failed("Compare", err)
+		return e.SetEscalationPolicyCondition(v1alpha1.ConditionReady, businessServiceReady, err, err.Error())
+	}
+
+	return e.status().SetDrift(true, drifted)
+}
+
 func (e *SubroutineHandler) BusinessServiceIDExists() bool {
 	return e.BusinessService.Status.BusinessServiceID != ""
 }
@@ -142,6 +468,19 @@ func (e *SubroutineHandler) removeFinalizer() error {
 	return e.K8sClient.Update(context.TODO(), e.BusinessService)
 }
 
+// status returns the condition subroutines shared with the other reconcilers, applied to the Business Service
+func (e *SubroutineHandler) status() *condition.Status {
+	return &condition.Status{
+		Kind:         "Business Service",
+		Object:       e.BusinessService,
+		Conditions:   &e.BusinessService.Status.Conditions,
+		Logger:       e.Logger,
+		Events:       e.Events,
+		Update:       e.StatusUpdate,
+		RequeueDelay: RequeWaitTime,
+	}
+}
+
 func (e *SubroutineHandler) StatusUpdate() error {
 	e.Logger.Info("Updating status...")
 	if err := e.K8sClient.Status().Update(context.TODO(), e.BusinessService); err != nil {
@@ -159,19 +498,38 @@ func (e *SubroutineHandler) SetEscalationPolicyCondition(conditionType v1alpha1.
 	// defer e.StatusUpdate()??
 
 	if err != nil {
+		switch class := pd_errors.Classify(err); class {
+		case pd_errors.Unauthorized:
+			return e.status().SetCredentials(err)
+		case pd_errors.Invalid:
+			// the upstream object still has to be released on deletion, whatever the spec
+			if !e.deletionTimestampExists() {
+				return e.status().SetInvalidSpec(err)
+			}
+		case pd_errors.NotFound, pd_errors.Forbidden, pd_errors.RateLimited:
+			reason = string(class)
+		}
+
+		delay := RequeWaitTime
+		if backoff, ok := pd_errors.Backoff(err); ok {
+			delay = backoff
+		}
+
 		e.Logger.Info("Setting ready condition to false", "conditionType", conditionType, "status", metav1.ConditionFalse, "reason", reason, "message", message, "error", err.Error())
 		e.conditionManager.SetCondition(conditions, conditionType, metav1.ConditionFalse, reason, err.Error())
+		e.status().SetError(reason, err)
 
 		err := e.StatusUpdate()
 		if err != nil {
 			e.Logger.Error(err, "Failed to update Business Service to false ready condition, Requeing in 10 seconds... ")
 		}
 
-		return pd_utils.RequeueAfter(RequeWaitTime, err)
+		return pd_utils.RequeueAfter(delay, err)
 	}
 
 	// TODO: Can probably simplify this if by only setting condition if on of these conditions is false...
-	if len(*conditions) > 0 && (*conditions)[0].Status == metav1.ConditionTrue && (*conditions)[0].Message == message {
+	if current := e.conditionManager.GetCondition(conditions, conditionType); current != nil && current.Status == metav1.ConditionTrue && current.Message == message {
+		e.status().ClearError()
 		err = e.StatusUpdate()
 		if err != nil {
 			e.Logger.Error(err, "Failed to update Business Service to true ready condition ")
@@ -182,11 +540,30 @@ func (e *SubroutineHandler) SetEscalationPolicyCondition(conditionType v1alpha1.
 
 	e.Logger.Info("Setting ready condition to true", "conditionType", conditionType, "status", metav1.ConditionFalse, "reason", reason, "message", message)
 	e.conditionManager.SetCondition(conditions, conditionType, metav1.ConditionTrue, reason, message)
+	e.status().ClearError()
 	err = e.StatusUpdate()
 
 	return pd_utils.RequeueOnErrorOrStop(err)
 }
 
+// CheckInvalidSpec ends the reconcile of a Business Service whose spec PagerDuty rejected, before any PagerDuty API call
+func (e *SubroutineHandler) CheckInvalidSpec() (pd_utils.OperationResult, error) {
+	return e.status().CheckInvalidSpec()
+}
+
+// ForgetUpstream drops the upstream Business Service deleted outside the operator from the status,
+// so the next reconcile creates it again
+func (e *SubroutineHandler) ForgetUpstream() (pd_utils.OperationResult, error) {
+	status := &e.BusinessService.Status
+
+	return e.status().ForgetUpstream(status.BusinessServiceID, func() {
+		status.BusinessServiceID = ""
+		status.HTMLURL = ""
+		status.Upstream = nil
+		status.ServiceDependencies = nil
+	})
+}
+
 func (e *SubroutineHandler) Initialization() (pd_utils.OperationResult, error) {
 	e.Logger.Info("Starting Initialization...")
 	if e.BusinessService.Status.Conditions == nil {
@@ -196,6 +573,50 @@ func (e *SubroutineHandler) Initialization() (pd_utils.OperationResult, error) {
 		return pd_utils.RequeueOnErrorOrStop(err)
 	}
 
+	// Older versions stored the ID under a malformed json key; move it over before anything reads it.
+	status := &e.BusinessService.Status
+	if status.BusinessServiceID == "" && status.LegacyBusinessServiceID != "" {
+		e.Logger.Info("Migrating legacy Business Service ID...", "id", status.LegacyBusinessServiceID)
+		status.BusinessServiceID = status.LegacyBusinessServiceID
+		status.LegacyBusinessServiceID = ""
+
+		err := e.StatusUpdate()
+		return pd_utils.RequeueOnErrorOrStop(err)
+	}
+
 	e.Logger.Info("Initialization done...")
 	return pd_utils.ContinueProcessing()
 }
+
+func (e *SubroutineHandler) EnsureCredentials() (pd_utils.OperationResult, error) {
+	e.Logger.Info("Ensuring PagerDuty credentials...")
+	return e.status().SetCredentials(e.credentialsErr)
+}
+
+// MarkSynced records the successful comparison with upstream of the Business Service
+func (e *SubroutineHandler) MarkSynced() (pd_utils.OperationResult, error) {
+	if !e.BusinessServiceIDExists() {
+		return pd_utils.ContinueProcessing()
+	}
+
+	status := &e.BusinessService.Status
+
+	if e.status().SyncDue(status.Upstream != nil, status.ObservedGeneration, status.LastSyncedTime, e.ResyncInterval) {
+		e.Logger.Info("Recording Business Service sync with upstream...")
+		businessService, err := e.Adapter.GetBusinessService(status.BusinessServiceID)
+		if pd_errors.IsNotFound(err) {
+			return e.ForgetUpstream()
+		}
+		if err != nil {
+			e.Logger.Error(err, "Failed to read upstream Business Service")
+			e.Events.Failed("Get", err)
+			return e.SetEscalationPolicyCondition(v1alpha1.ConditionReady, businessServiceReady, err, err.Error())
+		}
+
+		status.HTMLURL = businessService.HTMLUrl
+		status.Upstream = businessServiceSnapshot(businessService)
+		return e.status().MarkSynced(&status.ObservedGeneration, &status.LastSyncedTime)
+	}
+
+	return pd_utils.ContinueProcessing()
+}
@@ -20,6 +20,11 @@ import (
 	"context"
 	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/PagerDuty/go-pagerduty"
+	"github.com/go-logr/logr"
+	ctrl "sigs.k8s.io/controller-runtime"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -32,6 +37,10 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	pagerdutyv1alpha1 "gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
+	pagerdutyv1beta1 "gitlab.share-now.com/platform/pagerduty-operator/api/v1beta1"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/credentials"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/typeinfo"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/webhooks"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -52,6 +61,7 @@ func TestAPIs(t *testing.T) {
 
 var _ = BeforeSuite(func() {
 	logf.SetLogger(zap.New(zap.WriteTo(GinkgoWriter), zap.UseDevMode(true)))
+	ctx, cancel = context.WithCancel(context.TODO())
 
 	By("bootstrapping test environment")
 	testEnv = &envtest.Environment{
@@ -59,24 +69,57 @@ var _ = BeforeSuite(func() {
 		ErrorIfCRDPathMissing: true,
 	}
 
-	var err error
-	// cfg is defined in this file globally.
-	cfg, err = testEnv.Start()
+	// the types must be registered before the start, for the CRDs to be installed with the conversion webhook
+	err := pagerdutyv1alpha1.AddToScheme(scheme.Scheme)
 	Expect(err).NotTo(HaveOccurred())
-	Expect(cfg).NotTo(BeNil())
-
-	err = pagerdutyv1alpha1.AddToScheme(scheme.Scheme)
+	err = pagerdutyv1beta1.AddToScheme(scheme.Scheme)
 	Expect(err).NotTo(HaveOccurred())
 
 	//+kubebuilder:scaffold:scheme
 
+	// cfg is defined in this file globally.
+	cfg, err = testEnv.Start()
+	Expect(err).NotTo(HaveOccurred())
+	Expect(cfg).NotTo(BeNil())
+
 	k8sClient, err = client.New(cfg, client.Options{Scheme: scheme.Scheme})
 	Expect(err).NotTo(HaveOccurred())
 	Expect(k8sClient).NotTo(BeNil())
 
+	k8sManager, err := ctrl.NewManager(cfg, ctrl.Options{
+		Scheme:  scheme.Scheme,
+		Host:    testEnv.WebhookInstallOptions.LocalServingHost,
+		Port:    testEnv.WebhookInstallOptions.LocalServingPort,
+		CertDir: testEnv.WebhookInstallOptions.LocalServingCertDir,
+	})
+	Expect(err).ToNot(HaveOccurred())
+
+	err = webhooks.SetupConversionWithManager(k8sManager)
+	Expect(err).ToNot(HaveOccurred())
+
+	err = (&BusinessServiceReconciler{
+		Client:         k8sManager.GetClient(),
+		Scheme:         k8sManager.GetScheme(),
+		Recorder:       k8sManager.GetEventRecorderFor("business-service-controller"),
+		ClientProvider: credentials.NewStaticClientProvider(pagerduty.NewClient("")),
+		NewAdapter: func(_ context.Context, logger logr.Logger, _ *pagerduty.Client) Adapter {
+			return &BSMockAdapter{
+				Logger: logger,
+			}
+		},
+		UserResolver: typeinfo.NewUserResolverWithLookup(time.Minute, mockUserLookup),
+	}).SetupWithManager(k8sManager)
+	Expect(err).ToNot(HaveOccurred())
+
+	go func() {
+		defer GinkgoRecover()
+		err = k8sManager.Start(ctx)
+		Expect(err).ToNot(HaveOccurred(), "failed to run manager")
+	}()
 })
 
 var _ = AfterSuite(func() {
+	cancel()
 	By("tearing down the test environment")
 	err := testEnv.Stop()
 	Expect(err).NotTo(HaveOccurred())
@@ -12,6 +12,7 @@ type Conditions interface {
 	SetCondition(conditions *[]metav1.Condition, conditionType pdv1alpha1.ConditionType, status metav1.ConditionStatus, reason string, message string)
 	FindCondition(conditions *[]metav1.Condition, conditionType pdv1alpha1.ConditionType) (*metav1.Condition, bool)
 	HasCondition(conditions *[]metav1.Condition, conditionType pdv1alpha1.ConditionType) bool
+	GetCondition(conditions *[]metav1.Condition, conditionType pdv1alpha1.ConditionType) *metav1.Condition
 }
 
 type ConditionManager struct {
@@ -70,3 +71,14 @@ func (c *ConditionManager) HasCondition(conditions *[]metav1.Condition, conditio
 	}
 	return false
 }
+
+// GetCondition returns the condition of the given type, or nil if it is not set.
+// Unlike FindCondition it never appends a new condition.
+func (c *ConditionManager) GetCondition(conditions *[]metav1.Condition, conditionType pdv1alpha1.ConditionType) *metav1.Condition {
+	for i, condition := range *conditions {
+		if condition.Type == conditionType.String() {
+			return &(*conditions)[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+package condition
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	pdv1alpha1 "gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/credentials"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/events"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/observe"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/pause"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_errors"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_utils"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/resync"
+)
+
+// Status holds the condition subroutines shared by the reconcilers of the pagerduty custom resources.
+// Each SubroutineHandler builds one for the resource it reconciles.
+type Status struct {
+	// Kind describes the resource in logs and condition messages, e.g. "Escalation policy"
+	Kind string
+	// Object is the reconciled resource, whose generation is recorded in the conditions
+	Object client.Object
+	// Conditions are the status conditions of Object
+	Conditions *[]metav1.Condition
+	Logger     logr.Logger
+	Events     *events.Recorder
+	// Update writes the status of Object
+	Update func() error
+	// RequeueDelay is the delay before retrying a failed status update or missing credentials
+	RequeueDelay time.Duration
+}
+
+func (s *Status) set(conditionType pdv1alpha1.ConditionType, status metav1.ConditionStatus, reason string, message string) {
+	NewConditionManager().SetCondition(s.Conditions, conditionType, status, reason, message)
+}
+
+func (s *Status) get(conditionType pdv1alpha1.ConditionType) *metav1.Condition {
+	return NewConditionManager().GetCondition(s.Conditions, conditionType)
+}
+
+// CheckPaused ends the reconcile of a paused resource before any PagerDuty API call. Once the pause annotation
+// is removed, the Paused condition is cleared and the reconcile goes on with a full comparison with upstream.
+func (s *Status) CheckPaused(paused bool) (pd_utils.OperationResult, error) {
+	current := s.get(pdv1alpha1.ConditionPaused)
+
+	if paused {
+		if current != nil && current.Status == metav1.ConditionTrue {
+			s.Logger.Info(s.Kind + " reconciliation paused, skipping...")
+			return pd_utils.StopProcessing()
+		}
+
+		s.Logger.Info("Pausing " + s.Kind + " reconciliation...")
+		s.set(pdv1alpha1.ConditionPaused, metav1.ConditionTrue, pause.ReasonPaused, "Reconciliation paused by the "+pause.Annotation+" annotation")
+		s.set(pdv1alpha1.ConditionSynced, metav1.ConditionFalse, pause.ReasonPaused, s.Kind+" is not compared with upstream while paused")
+		err := s.Update()
+
+		return pd_utils.RequeueOnErrorOrStop(err)
+	}
+
+	if current == nil || current.Status == metav1.ConditionFalse {
+		return pd_utils.ContinueProcessing()
+	}
+
+	s.Logger.Info("Resuming " + s.Kind + " reconciliation...")
+	s.set(pdv1alpha1.ConditionPaused, metav1.ConditionFalse, pause.ReasonResumed, "Reconciliation resumed")
+	err := s.Update()
+
+	return pd_utils.RequeueOnErrorOrContinue(err)
+}
+
+// BlockPausedDeletion keeps the finalizer of a paused resource whose deletion policy would change upstream.
+// The deletion goes on once the pause annotation is removed, which triggers a new reconcile.
+func (s *Status) BlockPausedDeletion(policy pdv1alpha1.DeletionPolicy) (pd_utils.OperationResult, error) {
+	message := fmt.Sprintf("%s is paused, the %s deletion policy is applied once the %s annotation is removed", s.Kind, policy, pause.Annotation)
+
+	current := s.get(pdv1alpha1.ConditionDeletionBlocked)
+	if current != nil && current.Status == metav1.ConditionTrue && current.Message == message {
+		s.Logger.Info(s.Kind + " deletion blocked while paused, skipping...")
+		return pd_utils.StopProcessing()
+	}
+
+	s.Logger.Info("Blocking "+s.Kind+" deletion while paused...", "deletionPolicy", policy)
+	s.Events.Warning("DeletionBlocked", "%s", message)
+	s.set(pdv1alpha1.ConditionPaused, metav1.ConditionTrue, pause.ReasonPaused, "Reconciliation paused by the "+pause.Annotation+" annotation")
+	s.set(pdv1alpha1.ConditionDeletionBlocked, metav1.ConditionTrue, pause.ReasonPaused, message)
+	err := s.Update()
+
+	return pd_utils.RequeueOnErrorOrStop(err)
+}
+
+// SetDrift records the result of the comparison with upstream, and emits an event when it changes
+func (s *Status) SetDrift(upstreamExists bool, drifted observe.Diff) (pd_utils.OperationResult, error) {
+	status, reason, message := observe.DriftCondition(upstreamExists, drifted)
+
+	current := s.get(pdv1alpha1.ConditionDriftDetected)
+	if current != nil && current.Status == status && current.Reason == reason && current.Message == message {
+		return pd_utils.StopProcessing()
+	}
+
+	if status == metav1.ConditionTrue {
+		s.Events.Warning(reason, "%s", message)
+	} else {
+		s.Events.Normal(reason, "%s", message)
+	}
+
+	s.Logger.Info("Setting drift condition", "status", status, "reason", reason, "message", message)
+	s.set(pdv1alpha1.ConditionDriftDetected, status, reason, message)
+	err := s.Update()
+
+	return pd_utils.RequeueOnErrorOrStop(err)
+}
+
+// SetInvalidSpec records that PagerDuty rejected the spec of the resource. Retrying cannot succeed,
+// so the resource is not reconciled again until its spec changes.
+func (s *Status) SetInvalidSpec(err error) (pd_utils.OperationResult, error) {
+	s.Logger.Info("PagerDuty rejected the "+s.Kind+" spec, waiting for it to change", "error", err.Error())
+	s.set(pdv1alpha1.ConditionInvalidSpec, metav1.ConditionTrue, pd_errors.ReasonRejected, err.Error())
+	s.get(pdv1alpha1.ConditionInvalidSpec).ObservedGeneration = s.Object.GetGeneration()
+	s.set(pdv1alpha1.ConditionReady, metav1.ConditionFalse, pd_errors.ReasonInvalidSpec, err.Error())
+	s.SetError(pd_errors.ReasonInvalidSpec, err)
+	err = s.Update()
+
+	return pd_utils.RequeueOnErrorOrStop(err)
+}
+
+// CheckInvalidSpec ends the reconcile of a resource whose spec PagerDuty rejected, before any PagerDuty API call.
+// Once the spec changes, the InvalidSpec condition is cleared and the reconcile goes on.
+func (s *Status) CheckInvalidSpec() (pd_utils.OperationResult, error) {
+	if pd_errors.SpecRejected(*s.Conditions, s.Object.GetGeneration()) {
+		s.Logger.Info(s.Kind + " spec rejected by PagerDuty, waiting for it to change...")
+		return pd_utils.StopProcessing()
+	}
+
+	current := s.get(pdv1alpha1.ConditionInvalidSpec)
+	if current == nil || current.Status == metav1.ConditionFalse {
+		return pd_utils.ContinueProcessing()
+	}
+
+	s.Logger.Info("Rejected " + s.Kind + " spec changed, retrying...")
+	s.set(pdv1alpha1.ConditionInvalidSpec, metav1.ConditionFalse, pd_errors.ReasonSpecChanged, "Spec changed since PagerDuty rejected it")
+	err := s.Update()
+
+	return pd_utils.RequeueOnErrorOrContinue(err)
+}
+
+// ForgetUpstream records that the upstream object was deleted outside the operator. forget drops it from
+// the status, so the next reconcile adopts or creates it again.
+func (s *Status) ForgetUpstream(id string, forget func()) (pd_utils.OperationResult, error) {
+	s.Logger.Info("Upstream "+s.Kind+" not found, recreating it...", "id", id)
+	s.Events.Missing(id)
+	forget()
+	s.set(pdv1alpha1.ConditionSynced, metav1.ConditionFalse, pd_errors.ReasonUpstreamMissing, s.Kind+" no longer exists upstream")
+	err := s.Update()
+
+	return pd_utils.RequeueOnErrorOrStop(err)
+}
+
+// SetError records that the PagerDuty API calls of the resource failed, with the kind of error as reason.
+// The caller updates the status.
+func (s *Status) SetError(reason string, err error) {
+	s.set(pdv1alpha1.ConditionError, metav1.ConditionTrue, reason, err.Error())
+}
+
+// ClearError records that the PagerDuty API calls of the resource succeeded. The caller updates the status.
+func (s *Status) ClearError() {
+	s.set(pdv1alpha1.ConditionError, metav1.ConditionFalse, pd_errors.ReasonNoError, "PagerDuty API calls succeeded")
+}
+
+// SetCredentials records whether a usable PagerDuty API token is available.
+// A missing or rejected token requeues the resource, since no API call can succeed until it is fixed.
+func (s *Status) SetCredentials(err error) (pd_utils.OperationResult, error) {
+	if err != nil {
+		s.Logger.Info("Setting "+s.Kind+" credentials condition to false", "reason", credentials.Reason(err), "error", err.Error())
+		s.set(pdv1alpha1.ConditionCredentialsValid, metav1.ConditionFalse, credentials.Reason(err), err.Error())
+		s.SetError(credentials.Reason(err), err)
+
+		err := s.Update()
+		if err != nil {
+			s.Logger.Error(err, "Failed to update "+s.Kind+" credentials condition")
+		}
+
+		return pd_utils.RequeueAfter(s.RequeueDelay, err)
+	}
+
+	current := s.get(pdv1alpha1.ConditionCredentialsValid)
+	if current == nil || current.Status == metav1.ConditionTrue {
+		return pd_utils.ContinueProcessing()
+	}
+
+	s.Logger.Info("PagerDuty API token available again, setting " + s.Kind + " credentials condition to true")
+	s.set(pdv1alpha1.ConditionCredentialsValid, metav1.ConditionTrue, credentials.ReasonTokenValid, "PagerDuty API token loaded")
+	err = s.Update()
+
+	return pd_utils.RequeueOnErrorOrContinue(err)
+}
+
+// SyncDue returns true when the upstream object has to be read again to refresh the status: the last sync failed,
+// the spec changed since, the status has no snapshot of upstream yet, or the resync interval elapsed
+func (s *Status) SyncDue(hasSnapshot bool, observedGeneration int64, lastSynced *metav1.Time, interval time.Duration) bool {
+	synced := s.get(pdv1alpha1.ConditionSynced)
+	return synced == nil || synced.Status != metav1.ConditionTrue || !hasSnapshot ||
+		observedGeneration != s.Object.GetGeneration() || resync.Due(lastSynced, interval, time.Now())
+}
+
+// MarkSynced records the successful comparison with upstream, once the caller stored the upstream snapshot
+func (s *Status) MarkSynced(observedGeneration *int64, lastSynced **metav1.Time) (pd_utils.OperationResult, error) {
+	now := metav1.Now()
+	*lastSynced = &now
+	*observedGeneration = s.Object.GetGeneration()
+	s.set(pdv1alpha1.ConditionSynced, metav1.ConditionTrue, resync.ReasonUpstreamInSync, s.Kind+" matches upstream")
+
+	if err := s.Update(); err != nil {
+		return pd_utils.RequeueAfter(s.RequeueDelay, err)
+	}
+	return pd_utils.ContinueProcessing()
+}
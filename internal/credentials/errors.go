@@ -0,0 +1,61 @@
+package credentials
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/PagerDuty/go-pagerduty"
+)
+
+const (
+	// ReasonTokenMissing is used when the token Secret or key does not exist or is empty
+	ReasonTokenMissing = "TokenMissing"
+	// ReasonTokenInvalid is used when the PagerDuty API rejects the token
+	ReasonTokenInvalid = "TokenInvalid"
+	// ReasonTokenUnavailable is used when the token Secret could not be read
+	ReasonTokenUnavailable = "TokenUnavailable"
+	// ReasonAccountMissing is used when the referenced PagerDutyAccount does not exist
+	ReasonAccountMissing = "AccountMissing"
+	// ReasonTokenValid is used when the token was loaded successfully
+	ReasonTokenValid = "TokenValid"
+)
+
+// TokenError is returned when no usable PagerDuty API token is available
+type TokenError struct {
+	Reason  string
+	Message string
+}
+
+func (e *TokenError) Error() string {
+	return e.Message
+}
+
+// IsCredentialsError returns true if err was caused by a missing or invalid PagerDuty API token
+func IsCredentialsError(err error) bool {
+	var tokenErr *TokenError
+	if errors.As(err, &tokenErr) {
+		return true
+	}
+
+	return IsUnauthorized(err)
+}
+
+// IsUnauthorized returns true if the PagerDuty API rejected the request's token
+func IsUnauthorized(err error) bool {
+	var apiErr pagerduty.APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized
+}
+
+// Reason returns the condition reason matching a credentials error
+func Reason(err error) string {
+	var tokenErr *TokenError
+	if errors.As(err, &tokenErr) {
+		return tokenErr.Reason
+	}
+
+	if IsUnauthorized(err) {
+		return ReasonTokenInvalid
+	}
+
+	return ReasonTokenUnavailable
+}
@@ -0,0 +1,275 @@
+package credentials
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/PagerDuty/go-pagerduty"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_metrics"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/ratelimit"
+)
+
+//+kubebuilder:rbac:groups=core,resources=secrets,verbs=get
+//+kubebuilder:rbac:groups=pagerduty.platform.share-now.com,resources=pagerdutyaccounts,verbs=get;list;watch
+
+// ClientProvider hands out PagerDuty API clients to the reconcilers.
+// An empty accountRef selects the operator's default account.
+type ClientProvider interface {
+	Client(ctx context.Context, accountRef string) (*pagerduty.Client, error)
+}
+
+// SecretRef points to the key of a Secret that holds the PagerDuty API token
+type SecretRef struct {
+	Namespace string
+	Name      string
+	Key       string
+}
+
+// ParseSecretRef parses a secret reference in the form namespace/name/key
+func ParseSecretRef(ref string) (SecretRef, error) {
+	parts := strings.Split(ref, "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return SecretRef{}, fmt.Errorf("invalid secret reference %q, expected namespace/name/key", ref)
+	}
+
+	return SecretRef{Namespace: parts[0], Name: parts[1], Key: parts[2]}, nil
+}
+
+func (ref SecretRef) NamespacedName() types.NamespacedName {
+	return types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}
+}
+
+func (ref SecretRef) String() string {
+	return ref.Namespace + "/" + ref.Name + "/" + ref.Key
+}
+
+// accountSettings holds everything needed to build a client for an account
+type accountSettings struct {
+	token  string
+	apiURL string
+	from   string
+}
+
+type cachedClient struct {
+	settings     accountSettings
+	pdClient     *pagerduty.Client
+	unauthorized bool
+}
+
+// ClientFactory builds PagerDuty clients for the default account and for PagerDutyAccount resources.
+// Tokens are read from their Secret on every call, so a rotated token is picked up by the next
+// reconcile without restarting the manager. Clients are cached per account for as long as the
+// token, API URL and From email do not change. All the clients of an account share one rate limiter,
+// which outlives token rotations.
+type ClientFactory struct {
+	reader     client.Reader
+	secrets    client.Reader
+	defaultRef SecretRef
+	limits     ratelimit.Options
+
+	mu       sync.Mutex
+	clients  map[string]*cachedClient
+	limiters map[string]*ratelimit.Limiter
+}
+
+// NewClientFactory returns a ClientFactory reading accounts through reader and token Secrets through secrets.
+// secrets should not be backed by the manager's cache, e.g. mgr.GetAPIReader(), so reading a token neither
+// starts an informer over every Secret of the cluster nor needs more than get on Secrets.
+// The API calls of every account are rate limited and retried according to limits.
+func NewClientFactory(reader client.Reader, secrets client.Reader, defaultRef SecretRef, limits ratelimit.Options) *ClientFactory {
+	return &ClientFactory{
+		reader:     reader,
+		secrets:    secrets,
+		defaultRef: defaultRef,
+		limits:     limits,
+		clients:    map[string]*cachedClient{},
+		limiters:   map[string]*ratelimit.Limiter{},
+	}
+}
+
+func (f *ClientFactory) Client(ctx context.Context, accountRef string) (*pagerduty.Client, error) {
+	settings, tokenRef, err := f.accountSettings(ctx, accountRef)
+	if err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	cached, ok := f.clients[accountRef]
+	if !ok || cached.settings != settings {
+		cached = &cachedClient{
+			settings: settings,
+			pdClient: newPDClient(settings, f.limiter(accountRef)),
+		}
+		cached.pdClient.HTTPClient = &unauthorizedObserver{
+			next:           cached.pdClient.HTTPClient,
+			onUnauthorized: f.markUnauthorized(cached),
+		}
+		f.clients[accountRef] = cached
+	}
+
+	if cached.unauthorized {
+		return nil, &TokenError{
+			Reason:  ReasonTokenInvalid,
+			Message: fmt.Sprintf("PagerDuty API rejected the token stored in secret %s", tokenRef),
+		}
+	}
+
+	return cached.pdClient, nil
+}
+
+// limiter returns the rate limiter of the account, the caller holds f.mu
+func (f *ClientFactory) limiter(accountRef string) *ratelimit.Limiter {
+	limiter, ok := f.limiters[accountRef]
+	if !ok {
+		account := accountRef
+		if account == "" {
+			account = "default"
+		}
+		limiter = ratelimit.NewLimiter(account, f.limits)
+		f.limiters[accountRef] = limiter
+	}
+	return limiter
+}
+
+func newPDClient(settings accountSettings, limiter *ratelimit.Limiter) *pagerduty.Client {
+	var options []pagerduty.ClientOptions
+	if settings.apiURL != "" {
+		options = append(options, pagerduty.WithAPIEndpoint(strings.TrimSuffix(settings.apiURL, "/")))
+	}
+
+	pdClient := pagerduty.NewClient(settings.token, options...)
+	if settings.from != "" {
+		pdClient.HTTPClient = &fromHeaderSetter{
+			next: pdClient.HTTPClient,
+			from: settings.from,
+		}
+	}
+	pdClient.HTTPClient = pd_metrics.Instrument(limiter.Wrap(pdClient.HTTPClient))
+
+	return pdClient
+}
+
+// accountSettings resolves the token, API URL and From email of the referenced account
+func (f *ClientFactory) accountSettings(ctx context.Context, accountRef string) (accountSettings, SecretRef, error) {
+	if accountRef == "" {
+		token, err := f.readToken(ctx, f.defaultRef)
+		return accountSettings{token: token}, f.defaultRef, err
+	}
+
+	account := &v1alpha1.PagerDutyAccount{}
+	if err := f.reader.Get(ctx, types.NamespacedName{Name: accountRef}, account); err != nil {
+		if apierrors.IsNotFound(err) {
+			return accountSettings{}, SecretRef{}, &TokenError{
+				Reason:  ReasonAccountMissing,
+				Message: fmt.Sprintf("PagerDutyAccount %s not found", accountRef),
+			}
+		}
+		return accountSettings{}, SecretRef{}, &TokenError{
+			Reason:  ReasonTokenUnavailable,
+			Message: fmt.Sprintf("failed to read PagerDutyAccount %s: %s", accountRef, err.Error()),
+		}
+	}
+
+	tokenRef := SecretRef{
+		Namespace: account.Spec.TokenSecretRef.Namespace,
+		Name:      account.Spec.TokenSecretRef.Name,
+		Key:       account.Spec.TokenSecretRef.Key,
+	}
+	if tokenRef.Key == "" {
+		tokenRef.Key = "token"
+	}
+
+	token, err := f.readToken(ctx, tokenRef)
+	return accountSettings{
+		token:  token,
+		apiURL: account.Spec.APIURL,
+		from:   account.Spec.DefaultFrom,
+	}, tokenRef, err
+}
+
+func (f *ClientFactory) readToken(ctx context.Context, ref SecretRef) (string, error) {
+	secret := &corev1.Secret{}
+	if err := f.secrets.Get(ctx, ref.NamespacedName(), secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", &TokenError{
+				Reason:  ReasonTokenMissing,
+				Message: fmt.Sprintf("secret %s/%s not found", ref.Namespace, ref.Name),
+			}
+		}
+		return "", &TokenError{
+			Reason:  ReasonTokenUnavailable,
+			Message: fmt.Sprintf("failed to read secret %s/%s: %s", ref.Namespace, ref.Name, err.Error()),
+		}
+	}
+
+	token := strings.TrimSpace(string(secret.Data[ref.Key]))
+	if token == "" {
+		return "", &TokenError{
+			Reason:  ReasonTokenMissing,
+			Message: fmt.Sprintf("key %q in secret %s/%s is missing or empty", ref.Key, ref.Namespace, ref.Name),
+		}
+	}
+
+	return token, nil
+}
+
+// markUnauthorized flags the cached client as rejected. A client replaced after a rotation is not affected.
+func (f *ClientFactory) markUnauthorized(cached *cachedClient) func() {
+	return func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		cached.unauthorized = true
+	}
+}
+
+// unauthorizedObserver wraps the HTTP client of a PagerDuty client to notice when the API rejects the token
+type unauthorizedObserver struct {
+	next           pagerduty.HTTPClient
+	onUnauthorized func()
+}
+
+func (o *unauthorizedObserver) Do(req *http.Request) (*http.Response, error) {
+	resp, err := o.next.Do(req)
+	if err == nil && resp.StatusCode == http.StatusUnauthorized {
+		o.onUnauthorized()
+	}
+	return resp, err
+}
+
+// fromHeaderSetter adds the account's default From email to requests that do not set one
+type fromHeaderSetter struct {
+	next pagerduty.HTTPClient
+	from string
+}
+
+func (s *fromHeaderSetter) Do(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("From") == "" {
+		req.Header.Set("From", s.from)
+	}
+	return s.next.Do(req)
+}
+
+// StaticClientProvider always returns the same client, whatever the account. Used by tests and local runs.
+type StaticClientProvider struct {
+	PDClient *pagerduty.Client
+}
+
+func NewStaticClientProvider(pdClient *pagerduty.Client) *StaticClientProvider {
+	return &StaticClientProvider{PDClient: pdClient}
+}
+
+func (p *StaticClientProvider) Client(ctx context.Context, accountRef string) (*pagerduty.Client, error) {
+	return p.PDClient, nil
+}
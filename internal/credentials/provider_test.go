@@ -0,0 +1,229 @@
+package credentials
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/PagerDuty/go-pagerduty"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/ratelimit"
+)
+
+func tokenSecret(token string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "pagerduty-token", Namespace: "pagerduty-operator-system"},
+		Data:       map[string][]byte{"token": []byte(token)},
+	}
+}
+
+var _ = Describe("PagerDuty client factory", func() {
+	ref := SecretRef{Namespace: "pagerduty-operator-system", Name: "pagerduty-token", Key: "token"}
+
+	Describe("Parsing secret references", func() {
+		It("should parse namespace/name/key", func() {
+			parsed, err := ParseSecretRef("pagerduty-operator-system/pagerduty-token/token")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(parsed).To(Equal(ref))
+		})
+
+		It("should reject incomplete references", func() {
+			_, err := ParseSecretRef("pagerduty-token/token")
+			Expect(err).To(HaveOccurred())
+			_, err = ParseSecretRef("pagerduty-operator-system//token")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("When the Secret does not exist", func() {
+		It("should return a TokenMissing error", func() {
+			provider := NewClientFactory(fake.NewClientBuilder().Build(), fake.NewClientBuilder().Build(), ref, ratelimit.Options{})
+
+			pdClient, err := provider.Client(context.TODO(), "")
+			Expect(pdClient).To(BeNil())
+			Expect(IsCredentialsError(err)).To(BeTrue())
+			Expect(Reason(err)).To(Equal(ReasonTokenMissing))
+		})
+
+		It("should read it through the secrets reader only", func() {
+			cached := fake.NewClientBuilder().WithObjects(tokenSecret("token")).Build()
+			provider := NewClientFactory(cached, fake.NewClientBuilder().Build(), ref, ratelimit.Options{})
+
+			_, err := provider.Client(context.TODO(), "")
+			Expect(Reason(err)).To(Equal(ReasonTokenMissing))
+		})
+	})
+
+	Context("When the Secret key is empty", func() {
+		It("should return a TokenMissing error", func() {
+			k8sClient := fake.NewClientBuilder().WithObjects(tokenSecret("")).Build()
+			provider := NewClientFactory(k8sClient, k8sClient, ref, ratelimit.Options{})
+
+			_, err := provider.Client(context.TODO(), "")
+			Expect(Reason(err)).To(Equal(ReasonTokenMissing))
+		})
+	})
+
+	Context("When the token is rotated", func() {
+		It("should build a new client and keep reusing it until the next rotation", func() {
+			secret := tokenSecret("first-token")
+			k8sClient := fake.NewClientBuilder().WithObjects(secret).Build()
+			provider := NewClientFactory(k8sClient, k8sClient, ref, ratelimit.Options{})
+
+			first, err := provider.Client(context.TODO(), "")
+			Expect(err).NotTo(HaveOccurred())
+			again, err := provider.Client(context.TODO(), "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(again).To(BeIdenticalTo(first))
+
+			Expect(k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(secret), secret)).To(Succeed())
+			secret.Data["token"] = []byte("second-token")
+			Expect(k8sClient.Update(context.TODO(), secret)).To(Succeed())
+
+			rotated, err := provider.Client(context.TODO(), "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rotated).NotTo(BeIdenticalTo(first))
+		})
+	})
+
+	Context("When the API rejects the token", func() {
+		It("should report TokenInvalid until the token is rotated", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusUnauthorized)
+			}))
+			defer server.Close()
+
+			secret := tokenSecret("revoked-token")
+			k8sClient := fake.NewClientBuilder().WithObjects(secret).Build()
+			provider := NewClientFactory(k8sClient, k8sClient, ref, ratelimit.Options{})
+
+			pdClient, err := provider.Client(context.TODO(), "")
+			Expect(err).NotTo(HaveOccurred())
+
+			req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+			Expect(err).NotTo(HaveOccurred())
+			resp, err := pdClient.HTTPClient.Do(req)
+			Expect(err).NotTo(HaveOccurred())
+			resp.Body.Close()
+
+			_, err = provider.Client(context.TODO(), "")
+			Expect(Reason(err)).To(Equal(ReasonTokenInvalid))
+
+			Expect(k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(secret), secret)).To(Succeed())
+			secret.Data["token"] = []byte("new-token")
+			Expect(k8sClient.Update(context.TODO(), secret)).To(Succeed())
+
+			_, err = provider.Client(context.TODO(), "")
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("When PagerDuty rate limits the calls", func() {
+		It("should retry them through the rate limiter of the account", func() {
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if calls == 1 {
+					w.WriteHeader(http.StatusTooManyRequests)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"abilities": []}`))
+			}))
+			defer server.Close()
+
+			k8sClient := fake.NewClientBuilder().WithObjects(tokenSecret("token")).Build()
+			provider := NewClientFactory(k8sClient, k8sClient, ref, ratelimit.Options{MaxRetries: 1, BaseDelay: time.Millisecond})
+
+			pdClient, err := provider.Client(context.TODO(), "")
+			Expect(err).NotTo(HaveOccurred())
+			req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+			Expect(err).NotTo(HaveOccurred())
+			resp, err := pdClient.HTTPClient.Do(req)
+			Expect(err).NotTo(HaveOccurred())
+			resp.Body.Close()
+
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(calls).To(Equal(2))
+		})
+	})
+
+	Context("When a PagerDutyAccount is referenced", func() {
+		var server *httptest.Server
+		var requests []*http.Request
+
+		BeforeEach(func() {
+			requests = nil
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests = append(requests, r)
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"abilities": []}`))
+			}))
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		newFactory := func(objects ...client.Object) *ClientFactory {
+			scheme := runtime.NewScheme()
+			Expect(corev1.AddToScheme(scheme)).To(Succeed())
+			Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+
+			k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+			return NewClientFactory(k8sClient, k8sClient, ref, ratelimit.Options{})
+		}
+
+		It("should use the account's token, API URL and From email", func() {
+			sandboxToken := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "sandbox-token", Namespace: "pagerduty"},
+				Data:       map[string][]byte{"api-token": []byte("sandbox-token")},
+			}
+			account := &v1alpha1.PagerDutyAccount{
+				ObjectMeta: metav1.ObjectMeta{Name: "sandbox"},
+				Spec: v1alpha1.PagerDutyAccountSpec{
+					TokenSecretRef: v1alpha1.SecretKeyReference{Namespace: "pagerduty", Name: "sandbox-token", Key: "api-token"},
+					APIURL:         server.URL + "/",
+					DefaultFrom:    "oncall@share-now.com",
+				},
+			}
+			factory := newFactory(tokenSecret("default-token"), sandboxToken, account)
+
+			sandboxClient, err := factory.Client(context.TODO(), "sandbox")
+			Expect(err).NotTo(HaveOccurred())
+			defaultClient, err := factory.Client(context.TODO(), "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sandboxClient).NotTo(BeIdenticalTo(defaultClient))
+
+			_, err = sandboxClient.ListAbilitiesWithContext(context.TODO())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(requests).To(HaveLen(1))
+			Expect(requests[0].URL.Path).To(Equal("/abilities"))
+			Expect(requests[0].Header.Get("Authorization")).To(Equal("Token token=sandbox-token"))
+			Expect(requests[0].Header.Get("From")).To(Equal("oncall@share-now.com"))
+		})
+
+		It("should return an AccountMissing error for unknown accounts", func() {
+			factory := newFactory(tokenSecret("default-token"))
+
+			_, err := factory.Client(context.TODO(), "production")
+			Expect(Reason(err)).To(Equal(ReasonAccountMissing))
+		})
+	})
+
+	Describe("Classifying errors", func() {
+		It("should treat 401 API errors as credentials errors", func() {
+			Expect(IsCredentialsError(pagerduty.APIError{StatusCode: http.StatusUnauthorized})).To(BeTrue())
+			Expect(IsCredentialsError(pagerduty.APIError{StatusCode: http.StatusNotFound})).To(BeFalse())
+		})
+	})
+})
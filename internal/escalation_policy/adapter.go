@@ -3,13 +3,21 @@ package escalation_policy
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/PagerDuty/go-pagerduty"
 	"github.com/go-logr/logr"
 	"gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/observe"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_metrics"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_utils"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/typeinfo"
 )
 
 type EPAdapter struct {
+	// Ctx is the context of the reconcile, cancelling it cancels the API calls of the adapter
+	Ctx       context.Context
 	Logger    logr.Logger
 	PD_Client *pagerduty.Client
 }
@@ -19,7 +27,21 @@ type Adapter interface {
 	GetPDEscalationPolicy(string) (*pagerduty.EscalationPolicy, error)
 	DeletePDEscalationPolicy(string) error
 	UpdatePDEscalationPolicy(*v1alpha1.EscalationPolicy) error
-	EqualToUpstream(v1alpha1.EscalationPolicy) (bool, error)
+	UpstreamDrift(v1alpha1.EscalationPolicy) (observe.Diff, error)
+	FindEscalationPolicy(*v1alpha1.EscalationPolicySpec) (string, error)
+	RetainPDEscalationPolicy(string) error
+}
+
+// AdapterFactory builds the Adapter used by a single reconcile on top of the given PagerDuty client
+type AdapterFactory func(ctx context.Context, logger logr.Logger, pdClient *pagerduty.Client) Adapter
+
+// NewEPAdapter is the AdapterFactory that talks to the PagerDuty API
+func NewEPAdapter(ctx context.Context, logger logr.Logger, pdClient *pagerduty.Client) Adapter {
+	return EPAdapter{
+		Ctx:       ctx,
+		Logger:    logger,
+		PD_Client: pdClient,
+	}
 }
 
 var escalation_policy_reference_type = "escalation_policy_reference"
@@ -78,7 +100,7 @@ func (spec EPAdapter) convertSpec(policy *v1alpha1.EscalationPolicySpec) pagerdu
 
 func (adapter EPAdapter) CreateEscalationPolicy(k8sPDEscalationPolicy *v1alpha1.EscalationPolicySpec) (string, error) {
 
-	res, err := adapter.PD_Client.CreateEscalationPolicyWithContext(context.TODO(), adapter.convertSpec(k8sPDEscalationPolicy))
+	res, err := adapter.PD_Client.CreateEscalationPolicyWithContext(pd_metrics.Method(adapter.Ctx, "CreateEscalationPolicy"), adapter.convertSpec(k8sPDEscalationPolicy))
 	if err != nil {
 		adapter.Logger.Error(err, "Escalation policy creation unsuccessfull...")
 		return "", err
@@ -90,7 +112,7 @@ func (adapter EPAdapter) CreateEscalationPolicy(k8sPDEscalationPolicy *v1alpha1.
 func (adapter EPAdapter) DeletePDEscalationPolicy(id string) error {
 	adapter.Logger.Info("Deleting policy...")
 
-	err := adapter.PD_Client.DeleteEscalationPolicyWithContext(context.TODO(), id)
+	err := adapter.PD_Client.DeleteEscalationPolicyWithContext(pd_metrics.Method(adapter.Ctx, "DeletePDEscalationPolicy"), id)
 	if err != nil {
 		adapter.Logger.Error(err, "ERROR: Failed to delete Escalation policy")
 		return err
@@ -100,11 +122,30 @@ func (adapter EPAdapter) DeletePDEscalationPolicy(id string) error {
 	return nil
 }
 
+// RetainPDEscalationPolicy renames the upstream policy with the retained suffix instead of deleting it
+func (adapter EPAdapter) RetainPDEscalationPolicy(id string) error {
+	adapter.Logger.Info("Retaining policy...")
+
+	PDPolicy, err := adapter.GetPDEscalationPolicy(id)
+	if err != nil {
+		return err
+	}
+
+	PDPolicy.Name = pd_utils.RetainedName(PDPolicy.Name)
+	if _, err := adapter.PD_Client.UpdateEscalationPolicyWithContext(pd_metrics.Method(adapter.Ctx, "RetainPDEscalationPolicy"), id, *PDPolicy); err != nil {
+		adapter.Logger.Error(err, "API Failed to rename retained Escalation policy")
+		return err
+	}
+
+	adapter.Logger.Info("Escalation policy retained...", "name", PDPolicy.Name)
+	return nil
+}
+
 func (adapter EPAdapter) UpdatePDEscalationPolicy(k8sPDPolicy *v1alpha1.EscalationPolicy) error {
 
 	adapter.Logger.Info("Updating policy...")
 	_, err := adapter.PD_Client.UpdateEscalationPolicyWithContext(
-		context.TODO(),
+		pd_metrics.Method(adapter.Ctx, "UpdatePDEscalationPolicy"),
 		k8sPDPolicy.Status.PolicyID,
 		adapter.convert(k8sPDPolicy),
 	)
@@ -118,22 +159,63 @@ func (adapter EPAdapter) UpdatePDEscalationPolicy(k8sPDPolicy *v1alpha1.Escalati
 	return nil
 }
 
-func (adapter EPAdapter) EqualToUpstream(k8sPolicy v1alpha1.EscalationPolicy) (bool, error) {
+// UpstreamDrift returns the spec fields whose value differs from the upstream policy
+func (adapter EPAdapter) UpstreamDrift(k8sPolicy v1alpha1.EscalationPolicy) (observe.Diff, error) {
 	PDPolicy, err := adapter.GetPDEscalationPolicy(k8sPolicy.Status.PolicyID)
 	if err != nil {
 		adapter.Logger.Error(err, "Failed to get Escalation policy")
-		return false, err
+		return nil, err
+	}
+
+	return driftedFields(&k8sPolicy.Spec, PDPolicy), nil
+}
+
+// driftedFields compares the spec with the upstream policy field by field and returns
+// the json names of the fields that drifted, with their desired and upstream values
+func driftedFields(spec *v1alpha1.EscalationPolicySpec, PDPolicy *pagerduty.EscalationPolicy) observe.Diff {
+	var drifted observe.Diff
+
+	drifted.Compare("name", spec.Name, PDPolicy.Name)
+	drifted.Compare("description", spec.Description, PDPolicy.Description)
+	drifted.Compare("num_loops", spec.NumLoops, PDPolicy.NumLoops)
+	drifted.Compare("on_call_handoff_notifications", spec.OnCallHandoffNotifications, PDPolicy.OnCallHandoffNotifications)
+	if !spec.EscalationRules.CompareAPIObject(PDPolicy.EscalationRules) {
+		drifted.Add("escalation_rules", ruleSummaries(spec.EscalationRules.ConvertToPagerDutyObj()), ruleSummaries(PDPolicy.EscalationRules))
+	}
+	if !teamMatches(spec.Team, PDPolicy.Teams) {
+		PDTeamIDs := make([]string, len(PDPolicy.Teams))
+		for i, PDTeam := range PDPolicy.Teams {
+			PDTeamIDs[i] = PDTeam.ID
+		}
+		drifted.Add("teams", string(spec.Team), strings.Join(PDTeamIDs, ","))
+	}
+
+	return drifted
+}
+
+// ruleSummaries describes the escalation rules in the drift reports
+func ruleSummaries(rules []pagerduty.EscalationRule) []string {
+	summaries := make([]string, len(rules))
+	for i, rule := range rules {
+		targets := make([]string, len(rule.Targets))
+		for j, target := range rule.Targets {
+			targets[j] = target.Type + " " + target.ID
+		}
+		summaries[i] = fmt.Sprintf("after %d minutes: %s", rule.Delay, strings.Join(targets, ", "))
+	}
+	return summaries
+}
+
+func teamMatches(team typeinfo.TeamID, PDTeams []pagerduty.APIReference) bool {
+	if team == "" {
+		return len(PDTeams) == 0
 	}
 
-	return k8sPolicy.Spec.Name == PDPolicy.Name &&
-		k8sPolicy.Spec.Description == PDPolicy.Description &&
-		k8sPolicy.Spec.NumLoops == PDPolicy.NumLoops &&
-		k8sPolicy.Spec.OnCallHandoffNotifications == PDPolicy.OnCallHandoffNotifications &&
-		k8sPolicy.Spec.EscalationRules.CompareAPIObject(PDPolicy.EscalationRules), nil
+	return len(PDTeams) == 1 && PDTeams[0].ID == string(team)
 }
 
 func (adapter EPAdapter) GetPDEscalationPolicy(id string) (*pagerduty.EscalationPolicy, error) {
-	PDPolicy, err := adapter.PD_Client.GetEscalationPolicyWithContext(context.TODO(), id, &pagerduty.GetEscalationPolicyOptions{})
+	PDPolicy, err := adapter.PD_Client.GetEscalationPolicyWithContext(pd_metrics.Method(adapter.Ctx, "GetPDEscalationPolicy"), id, &pagerduty.GetEscalationPolicyOptions{})
 	if err != nil {
 		adapter.Logger.Error(err, "Failed to get Escalation policy")
 		return nil, err
@@ -142,3 +224,69 @@ func (adapter EPAdapter) GetPDEscalationPolicy(id string) (*pagerduty.Escalation
 	adapter.Logger.Info("Escalation policy retrieved", "PDPolicy", PDPolicy)
 	return PDPolicy, nil
 }
+
+// FindEscalationPolicy returns the ID of the upstream policy selected by spec.adopt, or an empty ID if there is none to adopt
+func (adapter EPAdapter) FindEscalationPolicy(spec *v1alpha1.EscalationPolicySpec) (string, error) {
+	if spec.Adopt == nil {
+		return "", nil
+	}
+
+	if spec.Adopt.ID != "" {
+		PDPolicy, err := adapter.GetPDEscalationPolicy(spec.Adopt.ID)
+		if err != nil {
+			return "", err
+		}
+		return PDPolicy.ID, nil
+	}
+
+	if !spec.Adopt.ByName {
+		return "", nil
+	}
+
+	PDPolicies, err := adapter.listEscalationPoliciesPaginated(pd_metrics.Method(adapter.Ctx, "FindEscalationPolicy"), pagerduty.ListEscalationPoliciesOptions{Query: spec.Name})
+	if err != nil {
+		adapter.Logger.Error(err, "Failed to list Escalation policies")
+		return "", err
+	}
+
+	var ids []string
+	for _, PDPolicy := range PDPolicies {
+		if PDPolicy.Name == spec.Name {
+			ids = append(ids, PDPolicy.ID)
+		}
+	}
+	return pd_utils.SingleMatch("escalation policy", spec.Name, ids)
+}
+
+// listEscalationPoliciesPaginated lists the policies matching the options across all the pages of the response.
+// The PagerDuty client has paginated variants for services and business services, but not for escalation policies.
+func (adapter EPAdapter) listEscalationPoliciesPaginated(ctx context.Context, opts pagerduty.ListEscalationPoliciesOptions) ([]pagerduty.EscalationPolicy, error) {
+	var PDPolicies []pagerduty.EscalationPolicy
+	for {
+		res, err := adapter.PD_Client.ListEscalationPoliciesWithContext(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		PDPolicies = append(PDPolicies, res.EscalationPolicies...)
+		if !res.More || len(res.EscalationPolicies) == 0 {
+			return PDPolicies, nil
+		}
+		opts.Offset += uint(len(res.EscalationPolicies))
+	}
+}
+
+// policySnapshot returns the managed fields of the upstream policy
+func policySnapshot(PDPolicy *pagerduty.EscalationPolicy) *v1alpha1.UpstreamSnapshot {
+	PDTeamIDs := make([]string, len(PDPolicy.Teams))
+	for i, PDTeam := range PDPolicy.Teams {
+		PDTeamIDs[i] = PDTeam.ID
+	}
+
+	snapshot := &v1alpha1.UpstreamSnapshot{Name: PDPolicy.Name, Description: PDPolicy.Description}
+	snapshot.SetField("num_loops", strconv.FormatUint(uint64(PDPolicy.NumLoops), 10))
+	snapshot.SetField("on_call_handoff_notifications", PDPolicy.OnCallHandoffNotifications)
+	snapshot.SetField("escalation_rules", strconv.Itoa(len(PDPolicy.EscalationRules)))
+	snapshot.SetField("teams", strings.Join(PDTeamIDs, ","))
+	return snapshot
+}
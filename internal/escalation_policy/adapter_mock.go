@@ -1,11 +1,14 @@
 package escalation_policy
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/PagerDuty/go-pagerduty"
 	"github.com/go-logr/logr"
 	"gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/observe"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_utils"
 )
 
 type EPMockAdapter struct {
@@ -19,6 +22,9 @@ var Default_on_call_handoff_notifications = "if_has_services"
 
 var policies map[string]pagerduty.EscalationPolicy = make(map[string]pagerduty.EscalationPolicy)
 
+var Default_user_email = "mock.user@share-now.com"
+var userIDs map[string]string = map[string]string{Default_user_email: "MOCKUSERID"}
+
 func (adapter EPMockAdapter) convert(policy *v1alpha1.EscalationPolicy) pagerduty.EscalationPolicy {
 	if policy.Spec.Team == "" {
 		fmt.Println("------------------------------------ Team is empty")
@@ -85,24 +91,33 @@ func (adapter *EPMockAdapter) DeletePDEscalationPolicy(id string) error {
 	return nil
 }
 
+func (adapter *EPMockAdapter) RetainPDEscalationPolicy(id string) error {
+	policy, ok := policies[id]
+	if !ok {
+		return fmt.Errorf("policy not found")
+	}
+
+	policy.Name = pd_utils.RetainedName(policy.Name)
+	policies[id] = policy
+
+	adapter.Logger.Info("Policy retained...")
+	return nil
+}
+
 func (adapter *EPMockAdapter) UpdatePDEscalationPolicy(k8sPDPolicy *v1alpha1.EscalationPolicy) error {
 	policies[k8sPDPolicy.Status.PolicyID] = adapter.convert(k8sPDPolicy)
 	adapter.Logger.Info("Upstream Escalation Policy updated...")
 	return nil
 }
 
-func (adapter *EPMockAdapter) EqualToUpstream(k8sPolicy v1alpha1.EscalationPolicy) (bool, error) {
+func (adapter *EPMockAdapter) UpstreamDrift(k8sPolicy v1alpha1.EscalationPolicy) (observe.Diff, error) {
 	PDPolicy, err := adapter.GetPDEscalationPolicy(k8sPolicy.Status.PolicyID)
 	if err != nil {
 		adapter.Logger.Error(err, "Failed to get Escalation policy")
-		return false, err
+		return nil, err
 	}
 
-	return k8sPolicy.Spec.Name == PDPolicy.Name &&
-		k8sPolicy.Spec.Description == PDPolicy.Description &&
-		k8sPolicy.Spec.NumLoops == PDPolicy.NumLoops &&
-		k8sPolicy.Spec.OnCallHandoffNotifications == PDPolicy.OnCallHandoffNotifications &&
-		k8sPolicy.Spec.EscalationRules.CompareAPIObject(PDPolicy.EscalationRules), nil
+	return driftedFields(&k8sPolicy.Spec, PDPolicy), nil
 }
 
 func (adapter *EPMockAdapter) GetPDEscalationPolicy(id string) (*pagerduty.EscalationPolicy, error) {
@@ -113,3 +128,26 @@ func (adapter *EPMockAdapter) GetPDEscalationPolicy(id string) (*pagerduty.Escal
 
 	return &policy, nil
 }
+
+// mockUserLookup finds the users of userIDs instead of calling the PagerDuty API
+func mockUserLookup(ctx context.Context, pdClient *pagerduty.Client, email string) (string, error) {
+	return userIDs[email], nil
+}
+
+func (adapter *EPMockAdapter) FindEscalationPolicy(spec *v1alpha1.EscalationPolicySpec) (string, error) {
+	if spec.Adopt == nil {
+		return "", nil
+	}
+
+	if spec.Adopt.ID != "" {
+		if _, ok := policies[spec.Adopt.ID]; !ok {
+			return "", fmt.Errorf("policy not found")
+		}
+		return spec.Adopt.ID, nil
+	}
+
+	if _, ok := policies[spec.Name]; spec.Adopt.ByName && ok {
+		return spec.Name, nil
+	}
+	return "", nil
+}
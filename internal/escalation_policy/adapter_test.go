@@ -2,11 +2,18 @@ package escalation_policy
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
 
 	"github.com/PagerDuty/go-pagerduty"
+	"github.com/go-logr/logr"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
+	"gitlab.share-now.com/platform/pagerduty-operator/api/v1beta1"
 	"gitlab.share-now.com/platform/pagerduty-operator/internal/typeinfo"
 )
 
@@ -26,7 +33,7 @@ func assertEscalationRulesMatch(pdEscalationRules []pagerduty.EscalationRule, k8
 		Expect(rule.Delay).To(Equal(pdEscalationRules[i].Delay))
 		Expect(len(rule.Targets)).To(Equal(len(pdEscalationRules[i].Targets)))
 		for j, target := range rule.Targets {
-			Expect(string(target)).To(Equal(pdEscalationRules[i].Targets[j].ID))
+			Expect(target.ToAPIObject().ID).To(Equal(pdEscalationRules[i].Targets[j].ID))
 		}
 	}
 }
@@ -47,6 +54,7 @@ var _ = Describe("Escalation policy adapter tests", func() {
 	pd_client := pagerduty.NewClient("")
 
 	adapter := EPAdapter{
+		Ctx:       context.TODO(),
 		PD_Client: pd_client,
 	}
 
@@ -62,8 +70,8 @@ var _ = Describe("Escalation policy adapter tests", func() {
 					OnCallHandoffNotifications: OnCallHandoffNotifications,
 					EscalationRules: []typeinfo.K8sEscalationRule{
 						{
-							Targets: []typeinfo.UserID{
-								typeinfo.UserID(MockUserID),
+							Targets: []typeinfo.EscalationTarget{
+								{UserID: MockUserID},
 							},
 							Delay: Delay,
 						},
@@ -148,3 +156,147 @@ var _ = Describe("Escalation policy adapter tests", func() {
 
 	})
 })
+
+var _ = Describe("Escalation policy drift detection", func() {
+	var spec *v1alpha1.EscalationPolicySpec
+	var pdPolicy *pagerduty.EscalationPolicy
+
+	BeforeEach(func() {
+		spec = &v1alpha1.EscalationPolicySpec{
+			Name:                       "drift-policy",
+			Description:                "drift-policy-description",
+			NumLoops:                   1,
+			OnCallHandoffNotifications: "if_has_services",
+			Team:                       typeinfo.TeamID("TEAMID"),
+			EscalationRules: typeinfo.K8sEscalationRuleList{
+				{
+					Delay:   5,
+					Targets: []typeinfo.EscalationTarget{{UserID: "USERA"}, {ScheduleID: "SCHEDULEB"}},
+				},
+			},
+		}
+		pdPolicy = &pagerduty.EscalationPolicy{
+			Name:                       "drift-policy",
+			Description:                "drift-policy-description",
+			NumLoops:                   1,
+			OnCallHandoffNotifications: "if_has_services",
+			Teams:                      []pagerduty.APIReference{{ID: "TEAMID", Type: "team_reference"}},
+			EscalationRules: []pagerduty.EscalationRule{
+				{
+					Delay: 5,
+					Targets: []pagerduty.APIObject{
+						{ID: "SCHEDULEB", Type: "schedule_reference"},
+						{ID: "USERA", Type: "user_reference"},
+					},
+				},
+			},
+		}
+	})
+
+	It("should ignore the order of the rule targets", func() {
+		Expect(driftedFields(spec, pdPolicy)).To(BeEmpty())
+	})
+
+	It("should report every drifted field", func() {
+		pdPolicy.NumLoops = 3
+		pdPolicy.OnCallHandoffNotifications = "always"
+		pdPolicy.Teams = nil
+		pdPolicy.EscalationRules[0].Targets[0].Type = "user_reference"
+
+		Expect(driftedFields(spec, pdPolicy).Fields()).To(Equal([]string{
+			"num_loops",
+			"on_call_handoff_notifications",
+			"escalation_rules",
+			"teams",
+		}))
+	})
+
+	It("should report a team set upstream but removed from the spec", func() {
+		spec.Team = ""
+
+		Expect(driftedFields(spec, pdPolicy).Fields()).To(Equal([]string{"teams"}))
+	})
+})
+
+var _ = Describe("Escalation policy teams", func() {
+	var server *httptest.Server
+	var adapter EPAdapter
+	// sent is the policy of the last request received by the server
+	var sent pagerduty.EscalationPolicy
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.Method).To(Equal(http.MethodPost))
+			Expect(r.URL.Path).To(Equal("/escalation_policies"))
+
+			var body struct {
+				EscalationPolicy pagerduty.EscalationPolicy `json:"escalation_policy"`
+			}
+			Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+			sent = body.EscalationPolicy
+
+			fmt.Fprint(w, `{"escalation_policy": {"id": "PCREATED"}}`)
+		}))
+		adapter = EPAdapter{
+			Ctx:       context.TODO(),
+			Logger:    logr.Discard(),
+			PD_Client: pagerduty.NewClient("token", pagerduty.WithAPIEndpoint(server.URL)),
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should send the team of a v1beta1 policy to PagerDuty", func() {
+		hub := &v1beta1.EscalationPolicy{Spec: v1beta1.EscalationPolicySpec{
+			Name:  "platform",
+			Teams: []v1beta1.TeamReference{{ID: "PTEAM01"}},
+		}}
+		policy := &v1alpha1.EscalationPolicy{}
+		Expect(policy.ConvertFrom(hub)).To(Succeed())
+
+		Expect(adapter.CreateEscalationPolicy(&policy.Spec)).To(Equal("PCREATED"))
+		Expect(sent.Teams).To(Equal([]pagerduty.APIReference{{ID: "PTEAM01", Type: "team_reference"}}))
+	})
+})
+
+var _ = Describe("Escalation policy adoption", func() {
+	const PolicyName = "adopted-policy"
+
+	var server *httptest.Server
+	var adapter EPAdapter
+	// pages are the policies returned for each page of the listing
+	var pages []string
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.URL.Path).To(Equal("/escalation_policies"))
+			Expect(r.URL.Query().Get("query")).To(Equal(PolicyName))
+
+			offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+			page := offset / 2
+			fmt.Fprintf(w, `{"escalation_policies": %s, "offset": %d, "limit": 2, "more": %t}`,
+				pages[page], offset, page < len(pages)-1)
+		}))
+		adapter = EPAdapter{
+			Ctx:       context.TODO(),
+			Logger:    logr.Discard(),
+			PD_Client: pagerduty.NewClient("token", pagerduty.WithAPIEndpoint(server.URL)),
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	spec := &v1alpha1.EscalationPolicySpec{Name: PolicyName, Adopt: &v1alpha1.AdoptSpec{ByName: true}}
+
+	It("should find a policy named like the spec on a later page", func() {
+		pages = []string{
+			`[{"id": "PONE", "name": "adopted-policy-1"}, {"id": "PTWO", "name": "adopted-policy-2"}]`,
+			`[{"id": "PADOPTED", "name": "adopted-policy"}]`,
+		}
+		Expect(adapter.FindEscalationPolicy(spec)).To(Equal("PADOPTED"))
+	})
+})
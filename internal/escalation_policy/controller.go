@@ -2,26 +2,49 @@ package escalation_policy
 
 import (
 	"context"
+	"time"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	pagerdutyalpha1 "gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
 	"gitlab.share-now.com/platform/pagerduty-operator/internal/condition"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/credentials"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/events"
 	k8s_utils "gitlab.share-now.com/platform/pagerduty-operator/internal/k8s_utils"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/observe"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/pause"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_errors"
 	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_utils"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/resync"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/typeinfo"
 )
 
 // EscalationPolicyReconciler reconciles a EscalationPolicy object
 type EscalationPolicyReconciler struct {
 	client.Client
-	Scheme   *runtime.Scheme
-	Recorder record.EventRecorder
-	Adapter  Adapter
+	Scheme         *runtime.Scheme
+	Recorder       record.EventRecorder
+	ClientProvider credentials.ClientProvider
+	NewAdapter     AdapterFactory
+	UserResolver   *typeinfo.UserResolver
+	// DefaultDeletionPolicy applies to the resources that do not set a deletion policy
+	DefaultDeletionPolicy pagerdutyalpha1.DeletionPolicy
+	// ResyncPeriod defines how often healthy resources are compared with upstream, 0 disables the resync
+	ResyncPeriod time.Duration
+	// DefaultMode applies to the resources without a mode annotation
+	DefaultMode observe.Mode
 }
 
 type Subroutines interface {
@@ -30,13 +53,21 @@ type Subroutines interface {
 	ReconcileUpdate() (pd_utils.OperationResult, error)
 	Initialization() (pd_utils.OperationResult, error)
 	AddFinalizer() (pd_utils.OperationResult, error)
+	EnsureCredentials() (pd_utils.OperationResult, error)
+	ResolveTargets() (pd_utils.OperationResult, error)
+	CheckPaused() (pd_utils.OperationResult, error)
+	CheckInvalidSpec() (pd_utils.OperationResult, error)
+	ObserveUpstream() (pd_utils.OperationResult, error)
+	MarkSynced() (pd_utils.OperationResult, error)
 }
 
 //+kubebuilder:rbac:groups=pagerduty.platform.share-now.com,resources=escalationpolicies,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=pagerduty.platform.share-now.com,resources=escalationpolicies/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=pagerduty.platform.share-now.com,resources=escalationpolicies/finalizers,verbs=update
+//+kubebuilder:rbac:groups=pagerduty.platform.share-now.com,resources=pagerdutyservices,verbs=get;list;watch
+//+kubebuilder:rbac:groups=pagerduty.platform.share-now.com,resources=schedules,verbs=get;list;watch
+//+kubebuilder:rbac:groups=pagerduty.platform.share-now.com,resources=teams,verbs=get;list;watch
 //+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
-//+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
@@ -67,19 +98,48 @@ func (r *EscalationPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		return k8s_utils.RequeueWithError(err)
 	}
 
+	pdClient, credentialsErr := r.ClientProvider.Client(ctx, policy.Spec.AccountRef)
+
+	resyncInterval, err := resync.Interval(policy, r.ResyncPeriod)
+	if err != nil {
+		log.Error(err, "Ignoring resync interval annotation")
+	}
+
+	mode, err := observe.ModeOf(policy, r.DefaultMode)
+	if err != nil {
+		log.Error(err, "Ignoring mode annotation")
+	}
+
+	paused := pause.IsPaused(policy)
+	deletionPolicy := observe.DeletionPolicy(mode, policy.Spec.DeletionPolicy.OrDefault(r.DefaultDeletionPolicy))
+	if paused || pd_errors.SpecRejected(policy.Status.Conditions, policy.Generation) {
+		// removing the annotation or changing the spec triggers a reconcile, there is nothing to resync until then
+		resyncInterval = 0
+	}
+
 	subroutineHandler := &SubroutineHandler{
 		Logger:           log.WithName("escalation-policy controller"),
 		K8sClient:        r.Client,
-		Adapter:          r.Adapter,
+		Adapter:          r.NewAdapter(ctx, log.WithName("EP Adapter"), pdClient),
+		Events:           events.NewRecorder(r.Recorder, policy, "Escalation policy"),
+		PDClient:         pdClient,
+		UserResolver:     r.UserResolver,
 		EscalationPolicy: policy,
+		DeletionPolicy:   deletionPolicy,
+		ResyncInterval:   resyncInterval,
+		Paused:           paused,
+		Observe:          mode == observe.ModeObserve,
+		ctx:              ctx,
 		conditionManager: condition.NewConditionManager(),
+		credentialsErr:   credentialsErr,
 	}
 
 	result, err := r.ReconcileHandler(subroutineHandler)
 	// reason := "ReconcileError"
 	// _, _ = adapter.SetProjectClaimCondition(gcpv1alpha1.ConditionError, reason, err)
 
-	return result, err
+	// healthy resources are requeued, so changes made in PagerDuty are detected without waiting for a Kubernetes event
+	return resync.RequeueHealthy(result, err, resyncInterval)
 }
 
 type ReconcileOperation func() (pd_utils.OperationResult, error)
@@ -88,9 +148,15 @@ func (r *EscalationPolicyReconciler) ReconcileHandler(subroutines Subroutines) (
 	operations := []ReconcileOperation{
 		subroutines.Initialization,
 		subroutines.AddFinalizer,
+		subroutines.EnsureCredentials,
 		subroutines.ReconcileDeletion,
+		subroutines.CheckPaused,
+		subroutines.CheckInvalidSpec,
+		subroutines.ResolveTargets,
+		subroutines.ObserveUpstream,
 		subroutines.ReconcileCreation,
-		// subroutines.ReconcileUpdate,
+		subroutines.ReconcileUpdate,
+		subroutines.MarkSynced,
 	}
 	for _, operation := range operations {
 		result, err := operation()
@@ -105,8 +171,49 @@ func (r *EscalationPolicyReconciler) ReconcileHandler(subroutines Subroutines) (
 }
 
 // SetupWithManager sets up the controller with the Manager.
+// Policies are also reconciled when a PagerdutyService stops referencing them, so a blocked deletion
+// goes through as soon as the last reference is gone.
 func (r *EscalationPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&pagerdutyalpha1.EscalationPolicy{}).
+		Watches(
+			&source.Kind{Type: &pagerdutyalpha1.PagerdutyService{}},
+			handler.EnqueueRequestsFromMapFunc(referencedPolicy),
+			builder.WithPredicates(policyReferenceChanged()),
+		).
 		Complete(r)
 }
+
+// referencedPolicy maps a PagerdutyService to the EscalationPolicy it references
+func referencedPolicy(obj client.Object) []reconcile.Request {
+	service, ok := obj.(*pagerdutyalpha1.PagerdutyService)
+	if !ok || service.Spec.EscalationPolicyName == "" {
+		return nil
+	}
+
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{
+		Name:      service.Spec.EscalationPolicyName,
+		Namespace: service.Namespace,
+	}}}
+}
+
+// policyReferenceChanged only lets through service events that can remove a reference to a policy
+func policyReferenceChanged() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldService, ok := e.ObjectOld.(*pagerdutyalpha1.PagerdutyService)
+			if !ok {
+				return false
+			}
+			newService, ok := e.ObjectNew.(*pagerdutyalpha1.PagerdutyService)
+			if !ok {
+				return false
+			}
+
+			return oldService.Spec.EscalationPolicyName != newService.Spec.EscalationPolicyName
+		},
+	}
+}
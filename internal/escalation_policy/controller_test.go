@@ -4,12 +4,14 @@ import (
 	"context"
 	"time"
 
+	"github.com/PagerDuty/go-pagerduty"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	pagerdutyv1alpha1 "gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
 	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_utils"
 	"gitlab.share-now.com/platform/pagerduty-operator/internal/typeinfo"
 	core "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 )
@@ -47,8 +49,8 @@ func setupTest() *TestPolicyEnv {
 			OnCallHandoffNotifications: Default_on_call_handoff_notifications,
 			EscalationRules: []typeinfo.K8sEscalationRule{
 				{
-					Targets: typeinfo.UserIDList{
-						typeinfo.UserID("MOCKUSERID"),
+					Targets: []typeinfo.EscalationTarget{
+						{UserID: "MOCKUSERID"},
 					},
 					Delay: 5,
 				},
@@ -107,8 +109,8 @@ var _ = Describe("EscalationPolicy controller", func() {
 			Expect(testEnv.Policy.Spec.OnCallHandoffNotifications).Should(Equal(Default_on_call_handoff_notifications))
 			Expect(testEnv.Policy.Spec.EscalationRules).Should(Equal(typeinfo.K8sEscalationRuleList{
 				{
-					Targets: typeinfo.UserIDList{
-						typeinfo.UserID("MOCKUSERID"),
+					Targets: []typeinfo.EscalationTarget{
+						{UserID: "MOCKUSERID"},
 					},
 					Delay: 5,
 				},
@@ -177,8 +179,8 @@ var _ = Describe("EscalationPolicy controller", func() {
 			Expect(testEnv.Policy.Spec.OnCallHandoffNotifications).Should(Equal(Default_on_call_handoff_notifications))
 			Expect(testEnv.Policy.Spec.EscalationRules).Should(Equal(typeinfo.K8sEscalationRuleList{
 				{
-					Targets: typeinfo.UserIDList{
-						typeinfo.UserID("MOCKUSERID"),
+					Targets: []typeinfo.EscalationTarget{
+						{UserID: "MOCKUSERID"},
 					},
 					Delay: 5,
 				},
@@ -186,4 +188,257 @@ var _ = Describe("EscalationPolicy controller", func() {
 		})
 
 	})
+
+	Context("When deleting a Policy referenced by a PagerDuty Service", func() {
+		var service *pagerdutyv1alpha1.PagerdutyService
+
+		BeforeEach(func() {
+			testEnv = setupTest()
+
+			service = &pagerdutyv1alpha1.PagerdutyService{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "dependent-service",
+					Namespace: testEnv.PolicyNamespace,
+				},
+				Spec: pagerdutyv1alpha1.PagerdutyServiceSpec{
+					Name:                 "dependent-service",
+					EscalationPolicyName: Default_policy_name,
+				},
+			}
+			Expect(k8sClient.Create(ctx, service)).Should(Succeed())
+		})
+
+		AfterEach(func() {
+			cleanUp(testEnv)
+		})
+
+		It("Should block the deletion until the service is gone", func() {
+			policyKey := types.NamespacedName{Name: Default_policy_name, Namespace: testEnv.PolicyNamespace}
+
+			Eventually(func() string {
+				if err := k8sClient.Get(ctx, policyKey, testEnv.Policy); err != nil {
+					return ""
+				}
+				return testEnv.Policy.Status.PolicyID
+			}, timeout, interval).Should(Equal(testEnv.Policy.Spec.Name))
+
+			Expect(k8sClient.Delete(ctx, testEnv.Policy)).Should(Succeed())
+
+			Eventually(func() metav1.ConditionStatus {
+				if err := k8sClient.Get(ctx, policyKey, testEnv.Policy); err != nil {
+					return ""
+				}
+				for _, condition := range testEnv.Policy.Status.Conditions {
+					if condition.Type == pagerdutyv1alpha1.ConditionDeletionBlocked.String() {
+						return condition.Status
+					}
+				}
+				return ""
+			}, timeout, interval).Should(Equal(metav1.ConditionTrue))
+
+			Expect(k8sClient.Delete(ctx, service)).Should(Succeed())
+
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, policyKey, &pagerdutyv1alpha1.EscalationPolicy{})
+				return apierrors.IsNotFound(err)
+			}, timeout, interval).Should(BeTrue())
+		})
+		It("Should not block the deletion of a policy never created upstream", func() {
+			pending := &pagerdutyv1alpha1.EscalationPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "pending-policy", Namespace: testEnv.PolicyNamespace},
+				Spec: pagerdutyv1alpha1.EscalationPolicySpec{
+					Name: "pending-policy",
+					EscalationRules: []typeinfo.K8sEscalationRule{
+						{Targets: []typeinfo.EscalationTarget{{ScheduleRef: "missing-schedule"}}, Delay: 5},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, pending)).Should(Succeed())
+			Expect(k8sClient.Create(ctx, &pagerdutyv1alpha1.PagerdutyService{
+				ObjectMeta: metav1.ObjectMeta{Name: "pending-dependent", Namespace: testEnv.PolicyNamespace},
+				Spec: pagerdutyv1alpha1.PagerdutyServiceSpec{
+					Name:                 "pending-dependent",
+					EscalationPolicyName: pending.Name,
+				},
+			})).Should(Succeed())
+
+			pendingKey := types.NamespacedName{Name: pending.Name, Namespace: testEnv.PolicyNamespace}
+			Eventually(func() []string {
+				if err := k8sClient.Get(ctx, pendingKey, pending); err != nil {
+					return nil
+				}
+				return pending.Finalizers
+			}, timeout, interval).ShouldNot(BeEmpty())
+			Expect(pending.Status.PolicyID).Should(BeEmpty())
+
+			Expect(k8sClient.Delete(ctx, pending)).Should(Succeed())
+
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, pendingKey, &pagerdutyv1alpha1.EscalationPolicy{})
+				return apierrors.IsNotFound(err)
+			}, timeout, interval).Should(BeTrue())
+		})
+	})
+
+	Context("When a Policy targets a Schedule custom resource", func() {
+		const scheduleName = "target-schedule"
+
+		BeforeEach(func() {
+			testEnv = setupTest()
+		})
+
+		AfterEach(func() {
+			cleanUp(testEnv)
+		})
+
+		It("Should stay Pending until the Schedule is created upstream", func() {
+			policyKey := types.NamespacedName{Name: Default_policy_name, Namespace: testEnv.PolicyNamespace}
+			Eventually(func() string {
+				if err := k8sClient.Get(ctx, policyKey, testEnv.Policy); err != nil {
+					return ""
+				}
+				return testEnv.Policy.Status.PolicyID
+			}, timeout, interval).Should(Equal(testEnv.Policy.Spec.Name))
+
+			testEnv.Policy.Spec.EscalationRules[0].Targets = []typeinfo.EscalationTarget{
+				{ScheduleRef: scheduleName},
+				{UserID: typeinfo.EmailSelectorPrefix + Default_user_email},
+			}
+			Expect(k8sClient.Update(ctx, testEnv.Policy)).Should(Succeed())
+
+			pendingStatus := func() metav1.ConditionStatus {
+				if err := k8sClient.Get(ctx, policyKey, testEnv.Policy); err != nil {
+					return ""
+				}
+				for _, condition := range testEnv.Policy.Status.Conditions {
+					if condition.Type == pagerdutyv1alpha1.ConditionPending.String() {
+						return condition.Status
+					}
+				}
+				return ""
+			}
+			Eventually(pendingStatus, timeout, interval).Should(Equal(metav1.ConditionTrue))
+
+			schedule := &pagerdutyv1alpha1.Schedule{
+				ObjectMeta: metav1.ObjectMeta{Name: scheduleName, Namespace: testEnv.PolicyNamespace},
+				Spec: pagerdutyv1alpha1.ScheduleSpec{
+					Name: scheduleName,
+					ScheduleLayers: []pagerdutyv1alpha1.ScheduleLayer{
+						{
+							Start:                     "2023-06-05T09:00:00+02:00",
+							RotationVirtualStart:      "2023-06-05T09:00:00+02:00",
+							RotationTurnLengthSeconds: 86400,
+							Users:                     []typeinfo.UserID{"MOCKUSERID"},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, schedule)).Should(Succeed())
+			schedule.Status.ScheduleID = "MOCKSCHEDULEID"
+			schedule.Status.Conditions = []metav1.Condition{}
+			Expect(k8sClient.Status().Update(ctx, schedule)).Should(Succeed())
+
+			Eventually(pendingStatus, timeout, interval).Should(Equal(metav1.ConditionFalse))
+			Eventually(func() []pagerduty.APIObject {
+				return policies[testEnv.Policy.Status.PolicyID].EscalationRules[0].Targets
+			}, timeout, interval).Should(ConsistOf(
+				pagerduty.APIObject{ID: "MOCKSCHEDULEID", Type: "schedule_reference"},
+				pagerduty.APIObject{ID: "MOCKUSERID", Type: "user_reference"},
+			))
+			Expect(k8sClient.Get(ctx, policyKey, testEnv.Policy)).Should(Succeed())
+			Expect(testEnv.Policy.Status.ResolvedUserIDs).Should(Equal(map[string]string{Default_user_email: "MOCKUSERID"}))
+		})
+	})
+
+	Context("When a Policy adopts an existing upstream policy", func() {
+		const adoptedID = "hand-made-policy"
+
+		BeforeEach(func() {
+			policies[adoptedID] = pagerduty.EscalationPolicy{
+				APIObject:   pagerduty.APIObject{ID: adoptedID},
+				Name:        adoptedID,
+				Description: "created by hand",
+			}
+			testEnv = &TestPolicyEnv{PolicyNamespace: "test-" + pd_utils.RandStr(5)}
+			Expect(k8sClient.Create(ctx, &core.Namespace{
+				ObjectMeta: metav1.ObjectMeta{Name: testEnv.PolicyNamespace},
+			})).Should(Succeed())
+		})
+
+		AfterEach(func() {
+			cleanUp(testEnv)
+		})
+
+		It("Should record the adopted ID and reconcile the policy to the spec", func() {
+			testEnv.Policy = &pagerdutyv1alpha1.EscalationPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "adopting-policy", Namespace: testEnv.PolicyNamespace},
+				Spec: pagerdutyv1alpha1.EscalationPolicySpec{
+					Name:                       "adopting-policy",
+					Description:                Default_policy_description,
+					NumLoops:                   Default_num_loops,
+					OnCallHandoffNotifications: Default_on_call_handoff_notifications,
+					EscalationRules: []typeinfo.K8sEscalationRule{
+						{Targets: []typeinfo.EscalationTarget{{UserID: "MOCKUSERID"}}, Delay: 5},
+					},
+					Adopt: &pagerdutyv1alpha1.AdoptSpec{ID: adoptedID},
+				},
+			}
+			Expect(k8sClient.Create(ctx, testEnv.Policy)).Should(Succeed())
+
+			policyKey := types.NamespacedName{Name: "adopting-policy", Namespace: testEnv.PolicyNamespace}
+			Eventually(func() string {
+				if err := k8sClient.Get(ctx, policyKey, testEnv.Policy); err != nil {
+					return ""
+				}
+				return testEnv.Policy.Status.PolicyID
+			}, timeout, interval).Should(Equal(adoptedID))
+
+			Eventually(func() string {
+				return policies[adoptedID].Description
+			}, timeout, interval).Should(Equal(Default_policy_description))
+			Expect(policies).ShouldNot(HaveKey("adopting-policy"))
+		})
+	})
+
+	Context("When deleting a Policy with a deletion policy", func() {
+		BeforeEach(func() {
+			testEnv = setupTest()
+		})
+
+		AfterEach(func() {
+			cleanUp(testEnv)
+		})
+
+		deleteWithPolicy := func(deletionPolicy pagerdutyv1alpha1.DeletionPolicy) {
+			policyKey := types.NamespacedName{Name: Default_policy_name, Namespace: testEnv.PolicyNamespace}
+			Eventually(func() string {
+				if err := k8sClient.Get(ctx, policyKey, testEnv.Policy); err != nil {
+					return ""
+				}
+				return testEnv.Policy.Status.PolicyID
+			}, timeout, interval).Should(Equal(Default_policy_name))
+
+			testEnv.Policy.Spec.DeletionPolicy = deletionPolicy
+			Expect(k8sClient.Update(ctx, testEnv.Policy)).Should(Succeed())
+			Expect(k8sClient.Delete(ctx, testEnv.Policy)).Should(Succeed())
+
+			Eventually(func() bool {
+				return apierrors.IsNotFound(k8sClient.Get(ctx, policyKey, testEnv.Policy))
+			}, timeout, interval).Should(BeTrue())
+		}
+
+		It("Should leave the upstream policy untouched with the Orphan policy", func() {
+			deleteWithPolicy(pagerdutyv1alpha1.DeletionPolicyOrphan)
+			Expect(policies).Should(HaveKey(Default_policy_name))
+			Expect(policies[Default_policy_name].Name).Should(Equal(Default_policy_name))
+			delete(policies, Default_policy_name)
+		})
+
+		It("Should rename the upstream policy with the Retain policy", func() {
+			deleteWithPolicy(pagerdutyv1alpha1.DeletionPolicyRetain)
+			Expect(policies).Should(HaveKey(Default_policy_name))
+			Expect(policies[Default_policy_name].Name).Should(Equal(Default_policy_name + pd_utils.RetainedNameSuffix))
+			delete(policies, Default_policy_name)
+		})
+	})
 })
@@ -4,20 +4,33 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"reflect"
+	"strings"
 	"time"
 
+	"github.com/PagerDuty/go-pagerduty"
 	"github.com/go-logr/logr"
 	"gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
 	"gitlab.share-now.com/platform/pagerduty-operator/internal/condition"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/events"
 	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_errors"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_metrics"
 	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_utils"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/pdservice"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/team"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/typeinfo"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
 const escalationPolicyFinalizer = "pagerduty.platform.share-now.com/escalation_policy"
 const escalationPolicyReady = "PDEscalationPolicyReady"
+const escalationPolicyReferenced = "ReferencedByPagerdutyServices"
+const escalationPolicyTargetsUnresolved = "TargetsUnresolved"
+const escalationPolicyTargetsResolved = "TargetsResolved"
 const RequeWaitTime = time.Second * 10
 
 type SubroutineHandler struct {
@@ -25,7 +38,20 @@ type SubroutineHandler struct {
 	Logger           logr.Logger
 	K8sClient        client.Client
 	Adapter          Adapter
+	Events           *events.Recorder
+	// PDClient looks users up by email through the UserResolver
+	PDClient       *pagerduty.Client
+	UserResolver   *typeinfo.UserResolver
+	DeletionPolicy v1alpha1.DeletionPolicy
+	ResyncInterval time.Duration
+	Paused         bool
+	Observe        bool
+	// ctx is the context of the reconcile, it cancels the user lookups
+	ctx              context.Context
 	conditionManager condition.Conditions
+	credentialsErr   error
+	resolvedRules    typeinfo.K8sEscalationRuleList
+	resolvedTeam     typeinfo.TeamID
 }
 
 func (e *SubroutineHandler) ReconcileCreation() (pd_utils.OperationResult, error) {
@@ -33,12 +59,27 @@ func (e *SubroutineHandler) ReconcileCreation() (pd_utils.OperationResult, error
 
 	// no upstream policy has been created yet
 	if !e.policyIDExists() {
+		adoptedID, err := e.Adapter.FindEscalationPolicy(&e.EscalationPolicy.Spec)
+		if err != nil {
+			e.Logger.Error(err, "Failed to find the PD Escalation Policy to adopt")
+			e.Events.Failed("Adopt", err)
+			return e.SetEscalationPolicyCondition(v1alpha1.ConditionReady, escalationPolicyReady, err, err.Error())
+		}
+
+		if adoptedID != "" {
+			e.Logger.Info("Adopting upstream Escalation Policy...", "policyID", adoptedID)
+			e.EscalationPolicy.Status.PolicyID = adoptedID
+			e.Events.Adopted(adoptedID)
+			return e.SetEscalationPolicyCondition(v1alpha1.ConditionReady, escalationPolicyReady, nil, "Escalation policy adopted")
+		}
+
 		e.Logger.Info("Upstream Escalation Policy policy not found. Creating...")
 
 		// Handles creation of upstream policy
-		policyID, err := e.Adapter.CreateEscalationPolicy(&e.EscalationPolicy.Spec)
+		policyID, err := e.Adapter.CreateEscalationPolicy(&e.resolvedPolicy().Spec)
 		if err != nil {
 			e.Logger.Error(err, "Failed to create PD Escalation Policy")
+			e.Events.Failed("Create", err)
 			return e.SetEscalationPolicyCondition(v1alpha1.ConditionReady, escalationPolicyReady, err, err.Error())
 		}
 
@@ -46,6 +87,7 @@ func (e *SubroutineHandler) ReconcileCreation() (pd_utils.OperationResult, error
 		e.Logger.Info(policyID)
 		// TODO: Update the rest of the policy status/spec
 		e.EscalationPolicy.Status.PolicyID = policyID
+		e.Events.Created(policyID)
 		return e.SetEscalationPolicyCondition(v1alpha1.ConditionReady, escalationPolicyReady, nil, "Escalation policy created")
 	}
 
@@ -58,10 +100,34 @@ func (e *SubroutineHandler) ReconcileDeletion() (pd_utils.OperationResult, error
 	if e.deletionTimestampExists() {
 		e.Logger.Info("Deletion timestamp found. Deleting...")
 
+		// a paused upstream object is only released untouched, any other policy waits for the pause to end
+		if e.Paused && e.DeletionPolicy != v1alpha1.DeletionPolicyOrphan && e.policyIDExists() {
+			return e.status().BlockPausedDeletion(e.DeletionPolicy)
+		}
+
+		// only a deleted upstream policy breaks the services referencing it
+		if e.DeletionPolicy == v1alpha1.DeletionPolicyDelete && e.policyIDExists() {
+			dependents, err := e.referencingServices()
+			if err != nil {
+				e.Logger.Error(err, "Failed to list PagerDuty Services referencing the escalation policy")
+				return e.SetEscalationPolicyCondition(v1alpha1.ConditionReady, escalationPolicyReady, err, err.Error())
+			}
+
+			if len(dependents) > 0 {
+				e.Logger.Info("Escalation policy still referenced by PagerDuty Services. Blocking deletion...", "services", dependents)
+				return e.SetDeletionBlockedCondition(dependents)
+			}
+		}
+
 		if e.policyIDExists() {
-			e.Logger.Info("Upstream policy found, making API deletion call for Escalation policy ...")
-			if err := e.Adapter.DeletePDEscalationPolicy(e.EscalationPolicy.Status.PolicyID); err != nil {
+			err := e.deleteUpstream()
+			if pd_errors.IsNotFound(err) {
+				e.Logger.Info("Upstream Escalation policy already deleted...", "id", e.EscalationPolicy.Status.PolicyID)
+				err = nil
+			}
+			if err != nil {
 				e.Logger.Error(err, "Failed to delete escalation policy")
+				e.Events.Failed("Delete", err)
 				return e.SetEscalationPolicyCondition(v1alpha1.ConditionReady, escalationPolicyReady, err, err.Error())
 			}
 		}
@@ -74,6 +140,32 @@ func (e *SubroutineHandler) ReconcileDeletion() (pd_utils.OperationResult, error
 	return pd_utils.ContinueProcessing()
 }
 
+// deleteUpstream applies the deletion policy to the upstream Escalation policy
+func (e *SubroutineHandler) deleteUpstream() error {
+	policyID := e.EscalationPolicy.Status.PolicyID
+
+	switch e.DeletionPolicy {
+	case v1alpha1.DeletionPolicyOrphan:
+		e.Logger.Info("Deletion policy is Orphan, leaving upstream Escalation policy untouched...", "policyID", policyID)
+		e.Events.Orphaned(policyID)
+		return nil
+	case v1alpha1.DeletionPolicyRetain:
+		e.Logger.Info("Deletion policy is Retain, renaming upstream Escalation policy...", "policyID", policyID)
+		if err := e.Adapter.RetainPDEscalationPolicy(policyID); err != nil {
+			return err
+		}
+		e.Events.Retained(policyID)
+		return nil
+	default:
+		e.Logger.Info("Upstream policy found, making API deletion call for Escalation policy ...")
+		if err := e.Adapter.DeletePDEscalationPolicy(policyID); err != nil {
+			return err
+		}
+		e.Events.Deleted(policyID)
+		return nil
+	}
+}
+
 func (e *SubroutineHandler) ReconcileUpdate() (pd_utils.OperationResult, error) {
 	e.Logger.Info("Reconcile Escalation Policy Update...")
 
@@ -82,29 +174,258 @@ func (e *SubroutineHandler) ReconcileUpdate() (pd_utils.OperationResult, error)
 		return pd_utils.ContinueProcessing()
 	}
 
-	equal, err := e.Adapter.EqualToUpstream(*e.EscalationPolicy)
+	drifted, err := e.Adapter.UpstreamDrift(*e.resolvedPolicy())
+	if pd_errors.IsNotFound(err) {
+		return e.ForgetUpstream()
+	}
 	if err != nil {
 		e.Logger.Error(err, "Failed to compare Escalation Policy spec with upstream policy")
+		e.Events.Failed("Compare", err)
 		return e.SetEscalationPolicyCondition(v1alpha1.ConditionReady, escalationPolicyReady, err, err.Error())
 	}
 
-	if !equal {
-		e.Logger.Info("Escalation Policy spec does not match upstream service. Updating...")
-		err := e.Adapter.UpdatePDEscalationPolicy(e.EscalationPolicy)
-
+	if len(drifted) > 0 {
+		e.Logger.Info("Escalation Policy spec does not match upstream policy. Updating...", "driftedFields", drifted.Fields())
+		err := e.Adapter.UpdatePDEscalationPolicy(e.resolvedPolicy())
+		if pd_errors.IsNotFound(err) {
+			return e.ForgetUpstream()
+		}
 		if err != nil {
 			e.Logger.Error(err, "Failed to update Escalation Policy")
+			e.Events.Failed("Update", err)
 			return e.SetEscalationPolicyCondition(v1alpha1.ConditionReady, escalationPolicyReady, err, err.Error())
 		}
 
 		e.Logger.Info("Escalation Policy changed...")
-		return e.SetEscalationPolicyCondition(v1alpha1.ConditionReady, escalationPolicyReady, nil, "PagerDuty Escalation Policy matches upstream service")
+		e.Events.Updated(e.EscalationPolicy.Status.PolicyID, drifted.Fields())
+		pd_metrics.DriftCorrected("EscalationPolicy", e.EscalationPolicy.Namespace)
+		// read again by MarkSynced
+		e.EscalationPolicy.Status.Upstream = nil
+		message := fmt.Sprintf("Escalation policy updated, drifted fields: %s", strings.Join(drifted.Fields(), ", "))
+		return e.SetEscalationPolicyCondition(v1alpha1.ConditionReady, escalationPolicyReady, nil, message)
 	}
 
 	e.Logger.Info("PagerDuty Escalation Policy not changed, Reconcile Update PagerDuty Escalation Policy done...")
 	return pd_utils.ContinueProcessing()
 }
 
+// ResolveTargets resolves the Schedule references and user emails of the escalation rules, and the Team reference,
+// to PagerDuty IDs. The policy stays Pending, and is neither created nor updated, until every target is resolved.
+func (e *SubroutineHandler) ResolveTargets() (pd_utils.OperationResult, error) {
+	e.Logger.Info("Resolving escalation rule targets...")
+
+	rules := make(typeinfo.K8sEscalationRuleList, len(e.EscalationPolicy.Spec.EscalationRules))
+	resolvedUsers := map[string]string{}
+	var unresolved []string
+	for i, rule := range e.EscalationPolicy.Spec.EscalationRules {
+		rules[i] = *rule.DeepCopy()
+		for j, target := range rule.Targets {
+			resolved, err := e.resolveTarget(target, resolvedUsers)
+			if err != nil {
+				e.Logger.Error(err, "Failed to resolve escalation rule target", "target", target.String())
+				return e.SetEscalationPolicyCondition(v1alpha1.ConditionReady, escalationPolicyReady, err, err.Error())
+			}
+			if !resolved.IsResolved() {
+				unresolved = append(unresolved, target.String())
+				continue
+			}
+			rules[i].Targets[j] = resolved
+		}
+	}
+
+	e.resolvedTeam = e.EscalationPolicy.Spec.Team
+	if e.EscalationPolicy.Spec.TeamRef != "" {
+		teamID, err := team.TeamID(context.TODO(), e.K8sClient, types.NamespacedName{
+			Name:      e.EscalationPolicy.Spec.TeamRef,
+			Namespace: e.EscalationPolicy.Namespace,
+		}, e.EscalationPolicy.Spec.AccountRef)
+		if err != nil {
+			e.Logger.Error(err, "Failed to resolve Team", "teamRef", e.EscalationPolicy.Spec.TeamRef)
+			return e.SetEscalationPolicyCondition(v1alpha1.ConditionReady, escalationPolicyReady, err, err.Error())
+		}
+		if teamID == "" {
+			unresolved = append(unresolved, "Team "+e.EscalationPolicy.Spec.TeamRef)
+		}
+		e.resolvedTeam = typeinfo.TeamID(teamID)
+	}
+
+	if len(unresolved) > 0 {
+		e.Logger.Info("Escalation rule targets not resolved yet", "targets", unresolved)
+		return e.SetPendingCondition(fmt.Sprintf("Waiting for escalation rule targets: %s", strings.Join(unresolved, ", ")))
+	}
+
+	e.resolvedRules = rules
+
+	if len(resolvedUsers) == 0 {
+		resolvedUsers = nil
+	}
+	if !reflect.DeepEqual(resolvedUsers, e.EscalationPolicy.Status.ResolvedUserIDs) {
+		e.Logger.Info("Recording resolved user IDs...", "users", resolvedUsers)
+		e.EscalationPolicy.Status.ResolvedUserIDs = resolvedUsers
+		if err := e.StatusUpdate(); err != nil {
+			return pd_utils.RequeueAfter(RequeWaitTime, err)
+		}
+	}
+
+	return e.ClearPendingCondition()
+}
+
+// resolveTarget returns the target unchanged when the referenced Schedule or user does not exist yet
+func (e *SubroutineHandler) resolveTarget(target typeinfo.EscalationTarget, resolvedUsers map[string]string) (typeinfo.EscalationTarget, error) {
+	switch {
+	case target.ScheduleRef != "":
+		schedule := &v1alpha1.Schedule{}
+		err := e.K8sClient.Get(context.TODO(), types.NamespacedName{
+			Name:      target.ScheduleRef,
+			Namespace: e.EscalationPolicy.Namespace,
+		}, schedule)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return target, nil
+			}
+			return target, err
+		}
+
+		if schedule.Spec.AccountRef != e.EscalationPolicy.Spec.AccountRef {
+			return target, fmt.Errorf("schedule %s uses account %q but the Escalation Policy uses account %q",
+				schedule.Name, schedule.Spec.AccountRef, e.EscalationPolicy.Spec.AccountRef)
+		}
+		if schedule.Status.ScheduleID == "" {
+			return target, nil
+		}
+		return typeinfo.EscalationTarget{ScheduleID: schedule.Status.ScheduleID}, nil
+
+	case !target.IsResolved() && target.UserID != "":
+		email, _ := target.Email()
+		userID, err := e.UserResolver.Resolve(pd_metrics.Method(e.ctx, "FindUserByEmail"), e.PDClient, e.EscalationPolicy.Spec.AccountRef, email)
+		if err != nil || userID == "" {
+			return target, err
+		}
+		resolvedUsers[email] = userID
+		return typeinfo.EscalationTarget{UserID: userID}, nil
+	}
+
+	return target, nil
+}
+
+// resolvedPolicy returns a copy of the policy whose targets and team all reference PagerDuty IDs
+func (e *SubroutineHandler) resolvedPolicy() *v1alpha1.EscalationPolicy {
+	policy := e.EscalationPolicy.DeepCopy()
+	policy.Spec.EscalationRules = e.resolvedRules
+	policy.Spec.Team = e.resolvedTeam
+	return policy
+}
+
+// SetPendingCondition marks the policy as waiting for its targets and requeues it
+func (e *SubroutineHandler) SetPendingCondition(message string) (pd_utils.OperationResult, error) {
+	conditions := &e.EscalationPolicy.Status.Conditions
+
+	e.conditionManager.SetCondition(conditions, v1alpha1.ConditionPending, metav1.ConditionTrue, escalationPolicyTargetsUnresolved, message)
+	err := e.StatusUpdate()
+	if err != nil {
+		e.Logger.Error(err, "Failed to update EscalationPolicy pending condition")
+	}
+
+	return pd_utils.RequeueAfter(RequeWaitTime, err)
+}
+
+// ClearPendingCondition flips the pending condition to false once every target is resolved
+func (e *SubroutineHandler) ClearPendingCondition() (pd_utils.OperationResult, error) {
+	conditions := &e.EscalationPolicy.Status.Conditions
+
+	current := e.conditionManager.GetCondition(conditions, v1alpha1.ConditionPending)
+	if current == nil || current.Status == metav1.ConditionFalse {
+		return pd_utils.ContinueProcessing()
+	}
+
+	e.conditionManager.SetCondition(conditions, v1alpha1.ConditionPending, metav1.ConditionFalse, escalationPolicyTargetsResolved, "All escalation rule targets resolved")
+	err := e.StatusUpdate()
+
+	return pd_utils.RequeueOnErrorOrContinue(err)
+}
+
+// referencingServices returns the names of the PagerdutyServices in the policy's namespace that reference it
+func (e *SubroutineHandler) referencingServices() ([]string, error) {
+	services := &v1alpha1.PagerdutyServiceList{}
+	err := e.K8sClient.List(context.TODO(), services,
+		client.InNamespace(e.EscalationPolicy.Namespace),
+		client.MatchingFields{pdservice.EscalationPolicyRefField: e.EscalationPolicy.Name},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	dependents := make([]string, len(services.Items))
+	for i, service := range services.Items {
+		dependents[i] = service.Name
+	}
+
+	return dependents, nil
+}
+
+// SetDeletionBlockedCondition keeps the finalizer while PagerdutyServices still reference the policy.
+// The policy is requeued until the last reference is gone.
+func (e *SubroutineHandler) SetDeletionBlockedCondition(dependents []string) (pd_utils.OperationResult, error) {
+	conditions := &e.EscalationPolicy.Status.Conditions
+	message := fmt.Sprintf("Escalation policy is still referenced by PagerDuty Services: %s", strings.Join(dependents, ", "))
+
+	if current := e.conditionManager.GetCondition(conditions, v1alpha1.ConditionDeletionBlocked); current == nil || current.Message != message {
+		e.Events.Warning("DeletionBlocked", "%s", message)
+	}
+
+	e.conditionManager.SetCondition(conditions, v1alpha1.ConditionDeletionBlocked, metav1.ConditionTrue, escalationPolicyReferenced, message)
+	err := e.StatusUpdate()
+	if err != nil {
+		e.Logger.Error(err, "Failed to update EscalationPolicy deletion blocked condition")
+	}
+
+	return pd_utils.RequeueAfter(RequeWaitTime, err)
+}
+
+// CheckPaused ends the reconcile of a paused Escalation policy before any PagerDuty API call
+func (e *SubroutineHandler) CheckPaused() (pd_utils.OperationResult, error) {
+	return e.status().CheckPaused(e.Paused)
+}
+
+// ObserveUpstream compares the Escalation policy with upstream in observe mode, without changing PagerDuty.
+// The drift is reported in the DriftDetected condition, and the reconcile ends there.
+func (e *SubroutineHandler) ObserveUpstream() (pd_utils.OperationResult, error) {
+	if !e.Observe {
+		return pd_utils.ContinueProcessing()
+	}
+	e.Logger.Info("Observe mode, comparing Escalation policy with upstream...")
+
+	if !e.policyIDExists() {
+		adoptedID, err := e.Adapter.FindEscalationPolicy(&e.EscalationPolicy.Spec)
+		if err != nil {
+			e.Logger.Error(err, "Failed to find the PD Escalation Policy to observe")
+			e.Events.Failed("Adopt", err)
+			return e.SetEscalationPolicyCondition(v1alpha1.ConditionReady, escalationPolicyReady, err, err.Error())
+		}
+		if adoptedID == "" {
+			return e.status().SetDrift(false, nil)
+		}
+
+		e.Logger.Info("Observing upstream Escalation Policy...", "policyID", adoptedID)
+		e.EscalationPolicy.Status.PolicyID = adoptedID
+		e.Events.Adopted(adoptedID)
+	}
+
+	drifted, err := e.Adapter.UpstreamDrift(*e.resolvedPolicy())
+	if pd_errors.IsNotFound(err) {
+		e.Logger.Info("Observed upstream Escalation policy not found...", "id", e.EscalationPolicy.Status.PolicyID)
+		e.EscalationPolicy.Status.PolicyID = ""
+		return e.status().SetDrift(false, nil)
+	}
+	if err != nil {
+		e.Logger.Error(err, "Failed to compare Escalation Policy spec with upstream policy")
+		e.Events.Failed("Compare", err)
+		return e.SetEscalationPolicyCondition(v1alpha1.ConditionReady, escalationPolicyReady, err, err.Error())
+	}
+
+	return e.status().SetDrift(true, drifted)
+}
+
 func (e *SubroutineHandler) policyIDExists() bool {
 	return e.EscalationPolicy.Status.PolicyID != ""
 }
@@ -143,6 +464,19 @@ func (e *SubroutineHandler) removeFinalizer() error {
 	return e.K8sClient.Update(context.TODO(), e.EscalationPolicy)
 }
 
+// status returns the condition subroutines shared with the other reconcilers, applied to the Escalation policy
+func (e *SubroutineHandler) status() *condition.Status {
+	return &condition.Status{
+		Kind:         "Escalation policy",
+		Object:       e.EscalationPolicy,
+		Conditions:   &e.EscalationPolicy.Status.Conditions,
+		Logger:       e.Logger,
+		Events:       e.Events,
+		Update:       e.StatusUpdate,
+		RequeueDelay: RequeWaitTime,
+	}
+}
+
 // StatusUpdate updates the project claim status
 func (e *SubroutineHandler) StatusUpdate() error {
 	e.Logger.Info("Updating status...")
@@ -161,19 +495,38 @@ func (e *SubroutineHandler) SetEscalationPolicyCondition(conditionType v1alpha1.
 	// defer e.StatusUpdate()??
 
 	if err != nil {
+		switch class := pd_errors.Classify(err); class {
+		case pd_errors.Unauthorized:
+			return e.status().SetCredentials(err)
+		case pd_errors.Invalid:
+			// the upstream object still has to be released on deletion, whatever the spec
+			if !e.deletionTimestampExists() {
+				return e.status().SetInvalidSpec(err)
+			}
+		case pd_errors.NotFound, pd_errors.Forbidden, pd_errors.RateLimited:
+			reason = string(class)
+		}
+
+		delay := RequeWaitTime
+		if backoff, ok := pd_errors.Backoff(err); ok {
+			delay = backoff
+		}
+
 		e.Logger.Info("Setting ready condition to false", "conditionType", conditionType, "status", metav1.ConditionFalse, "reason", reason, "message", message, "error", err.Error())
 		e.conditionManager.SetCondition(conditions, conditionType, metav1.ConditionFalse, reason, err.Error())
+		e.status().SetError(reason, err)
 
 		err := e.StatusUpdate()
 		if err != nil {
 			e.Logger.Error(err, "Failed to update EscalationPolicy to false ready condition, Requeing in 10 seconds... ")
 		}
 
-		return pd_utils.RequeueAfter(RequeWaitTime, err)
+		return pd_utils.RequeueAfter(delay, err)
 	}
 
 	// TODO: Can probably simplify this if by only setting condition if on of these conditions is false...
-	if len(*conditions) > 0 && (*conditions)[0].Status == metav1.ConditionTrue && (*conditions)[0].Message == message {
+	if current := e.conditionManager.GetCondition(conditions, conditionType); current != nil && current.Status == metav1.ConditionTrue && current.Message == message {
+		e.status().ClearError()
 		err = e.StatusUpdate()
 		if err != nil {
 			e.Logger.Error(err, "Failed to update EscalationPolicy to true ready condition ")
@@ -184,11 +537,29 @@ func (e *SubroutineHandler) SetEscalationPolicyCondition(conditionType v1alpha1.
 
 	e.Logger.Info("Setting ready condition to true", "conditionType", conditionType, "status", metav1.ConditionFalse, "reason", reason, "message", message)
 	e.conditionManager.SetCondition(conditions, conditionType, metav1.ConditionTrue, reason, message)
+	e.status().ClearError()
 	err = e.StatusUpdate()
 
 	return pd_utils.RequeueOnErrorOrStop(err)
 }
 
+// CheckInvalidSpec ends the reconcile of a Escalation policy whose spec PagerDuty rejected, before any PagerDuty API call
+func (e *SubroutineHandler) CheckInvalidSpec() (pd_utils.OperationResult, error) {
+	return e.status().CheckInvalidSpec()
+}
+
+// ForgetUpstream drops the upstream Escalation policy deleted outside the operator from the status,
+// so the next reconcile creates it again
+func (e *SubroutineHandler) ForgetUpstream() (pd_utils.OperationResult, error) {
+	status := &e.EscalationPolicy.Status
+
+	return e.status().ForgetUpstream(status.PolicyID, func() {
+		status.PolicyID = ""
+		status.HTMLURL = ""
+		status.Upstream = nil
+	})
+}
+
 func (e *SubroutineHandler) Initialization() (pd_utils.OperationResult, error) {
 	e.Logger.Info("Starting Initialization...")
 	if e.EscalationPolicy.Status.Conditions == nil {
@@ -202,3 +573,36 @@ func (e *SubroutineHandler) Initialization() (pd_utils.OperationResult, error) {
 	e.Logger.Info("Initialization done...")
 	return pd_utils.ContinueProcessing()
 }
+
+func (e *SubroutineHandler) EnsureCredentials() (pd_utils.OperationResult, error) {
+	e.Logger.Info("Ensuring PagerDuty credentials...")
+	return e.status().SetCredentials(e.credentialsErr)
+}
+
+// MarkSynced records the successful comparison with upstream of the Escalation policy
+func (e *SubroutineHandler) MarkSynced() (pd_utils.OperationResult, error) {
+	if !e.policyIDExists() {
+		return pd_utils.ContinueProcessing()
+	}
+
+	status := &e.EscalationPolicy.Status
+
+	if e.status().SyncDue(status.Upstream != nil, status.ObservedGeneration, status.LastSyncedTime, e.ResyncInterval) {
+		e.Logger.Info("Recording Escalation policy sync with upstream...")
+		PDPolicy, err := e.Adapter.GetPDEscalationPolicy(status.PolicyID)
+		if pd_errors.IsNotFound(err) {
+			return e.ForgetUpstream()
+		}
+		if err != nil {
+			e.Logger.Error(err, "Failed to read upstream Escalation policy")
+			e.Events.Failed("Get", err)
+			return e.SetEscalationPolicyCondition(v1alpha1.ConditionReady, escalationPolicyReady, err, err.Error())
+		}
+
+		status.HTMLURL = PDPolicy.HTMLURL
+		status.Upstream = policySnapshot(PDPolicy)
+		return e.status().MarkSynced(&status.ObservedGeneration, &status.LastSyncedTime)
+	}
+
+	return pd_utils.ContinueProcessing()
+}
@@ -20,7 +20,10 @@ import (
 	"context"
 	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/PagerDuty/go-pagerduty"
+	"github.com/go-logr/logr"
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -34,6 +37,11 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	pagerdutyv1alpha1 "gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
+	pagerdutyv1beta1 "gitlab.share-now.com/platform/pagerduty-operator/api/v1beta1"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/credentials"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/pdservice"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/typeinfo"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/webhooks"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -63,32 +71,49 @@ var _ = BeforeSuite(func() {
 		ErrorIfCRDPathMissing: true,
 	}
 
-	var err error
-	// cfg is defined in this file globally.
-	cfg, err = testEnv.Start()
+	// the types must be registered before the start, for the CRDs to be installed with the conversion webhook
+	err := pagerdutyv1alpha1.AddToScheme(scheme.Scheme)
 	Expect(err).NotTo(HaveOccurred())
-	Expect(cfg).NotTo(BeNil())
-
-	err = pagerdutyv1alpha1.AddToScheme(scheme.Scheme)
+	err = pagerdutyv1beta1.AddToScheme(scheme.Scheme)
 	Expect(err).NotTo(HaveOccurred())
 
 	//+kubebuilder:scaffold:scheme
 
+	// cfg is defined in this file globally.
+	cfg, err = testEnv.Start()
+	Expect(err).NotTo(HaveOccurred())
+	Expect(cfg).NotTo(BeNil())
+
 	k8sClient, err = client.New(cfg, client.Options{Scheme: scheme.Scheme})
 	Expect(err).NotTo(HaveOccurred())
 	Expect(k8sClient).NotTo(BeNil())
 
 	k8sManager, err := ctrl.NewManager(cfg, ctrl.Options{
-		Scheme: scheme.Scheme,
+		Scheme:  scheme.Scheme,
+		Host:    testEnv.WebhookInstallOptions.LocalServingHost,
+		Port:    testEnv.WebhookInstallOptions.LocalServingPort,
+		CertDir: testEnv.WebhookInstallOptions.LocalServingCertDir,
 	})
 	Expect(err).ToNot(HaveOccurred())
 
+	err = webhooks.SetupConversionWithManager(k8sManager)
+	Expect(err).ToNot(HaveOccurred())
+
+	// registered by the PagerdutyService reconciler in the manager, which does not run in this suite
+	err = pdservice.IndexEscalationPolicyRef(k8sManager)
+	Expect(err).ToNot(HaveOccurred())
+
 	err = (&EscalationPolicyReconciler{
-		Client: k8sManager.GetClient(),
-		Scheme: k8sManager.GetScheme(),
-		Adapter: &EPMockAdapter{
-			Logger: k8sManager.GetLogger().WithName("EP Adapter"),
+		Client:         k8sManager.GetClient(),
+		Scheme:         k8sManager.GetScheme(),
+		Recorder:       k8sManager.GetEventRecorderFor("escalation-policy-controller"),
+		ClientProvider: credentials.NewStaticClientProvider(pagerduty.NewClient("")),
+		NewAdapter: func(_ context.Context, logger logr.Logger, _ *pagerduty.Client) Adapter {
+			return &EPMockAdapter{
+				Logger: logger,
+			}
 		},
+		UserResolver: typeinfo.NewUserResolverWithLookup(time.Minute, mockUserLookup),
 	}).SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred())
 
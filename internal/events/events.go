@@ -0,0 +1,116 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/PagerDuty/go-pagerduty"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	// ReasonCreated is the reason of the event emitted when an upstream object is created
+	ReasonCreated = "Created"
+	// ReasonUpdated is the reason of the event emitted when an upstream object is updated
+	ReasonUpdated = "Updated"
+	// ReasonDeleted is the reason of the event emitted when an upstream object is deleted
+	ReasonDeleted = "Deleted"
+	// ReasonAdopted is the reason of the event emitted when an existing upstream object is adopted
+	ReasonAdopted = "Adopted"
+	// ReasonRetained is the reason of the event emitted when an upstream object is renamed instead of deleted
+	ReasonRetained = "Retained"
+	// ReasonOrphaned is the reason of the event emitted when an upstream object is left untouched on deletion
+	ReasonOrphaned = "Orphaned"
+	// ReasonUpstreamMissing is the reason of the event emitted when an upstream object was deleted outside the operator
+	ReasonUpstreamMissing = "UpstreamMissing"
+)
+
+// Recorder emits the events of a single resource about its upstream PagerDuty object.
+// A Recorder without an EventRecorder drops the events.
+type Recorder struct {
+	recorder record.EventRecorder
+	object   runtime.Object
+	kind     string
+}
+
+// NewRecorder returns the Recorder of the object, whose upstream objects are described as kind, e.g. "Schedule"
+func NewRecorder(recorder record.EventRecorder, object runtime.Object, kind string) *Recorder {
+	return &Recorder{
+		recorder: recorder,
+		object:   object,
+		kind:     kind,
+	}
+}
+
+// Created records the creation of the upstream object
+func (r *Recorder) Created(id string) {
+	r.Normal(ReasonCreated, "Created PagerDuty %s %s", r.kind, id)
+}
+
+// Updated records the update of the drifted fields of the upstream object
+func (r *Recorder) Updated(id string, fields []string) {
+	r.Normal(ReasonUpdated, "Updated %s of PagerDuty %s %s", strings.Join(fields, ", "), r.kind, id)
+}
+
+// Deleted records the deletion of the upstream object
+func (r *Recorder) Deleted(id string) {
+	r.Normal(ReasonDeleted, "Deleted PagerDuty %s %s", r.kind, id)
+}
+
+// Adopted records the adoption of an existing upstream object
+func (r *Recorder) Adopted(id string) {
+	r.Normal(ReasonAdopted, "Adopted PagerDuty %s %s", r.kind, id)
+}
+
+// Retained records the renaming of the upstream object of a deleted resource
+func (r *Recorder) Retained(id string) {
+	r.Normal(ReasonRetained, "Retained PagerDuty %s %s", r.kind, id)
+}
+
+// Orphaned records that the upstream object of a deleted resource was left untouched
+func (r *Recorder) Orphaned(id string) {
+	r.Normal(ReasonOrphaned, "Left PagerDuty %s %s untouched", r.kind, id)
+}
+
+// Missing records that the upstream object no longer exists, so it is adopted or created again
+func (r *Recorder) Missing(id string) {
+	r.Warning(ReasonUpstreamMissing, "PagerDuty %s %s no longer exists upstream, recreating it", r.kind, id)
+}
+
+// Failed records a failed PagerDuty API call. The reason is the action followed by Failed, e.g. CreateFailed.
+func (r *Recorder) Failed(action string, err error) {
+	r.Warning(action+"Failed", "Failed to %s PagerDuty %s: %s", strings.ToLower(action), r.kind, DescribeError(err))
+}
+
+// Normal emits an event of type Normal
+func (r *Recorder) Normal(reason string, messageFmt string, args ...interface{}) {
+	if r.recorder != nil {
+		r.recorder.Eventf(r.object, corev1.EventTypeNormal, reason, messageFmt, args...)
+	}
+}
+
+// Warning emits an event of type Warning
+func (r *Recorder) Warning(reason string, messageFmt string, args ...interface{}) {
+	if r.recorder != nil {
+		r.recorder.Eventf(r.object, corev1.EventTypeWarning, reason, messageFmt, args...)
+	}
+}
+
+// DescribeError formats an error returned by the PagerDuty API with its HTTP status and PagerDuty error code.
+// Other errors are formatted as they are.
+func DescribeError(err error) string {
+	var apiErr pagerduty.APIError
+	if !errors.As(err, &apiErr) || !apiErr.APIError.Valid {
+		return err.Error()
+	}
+
+	errorObject := apiErr.APIError.ErrorObject
+	message := fmt.Sprintf("%s (HTTP %d, PagerDuty error code %d)", errorObject.Message, apiErr.StatusCode, errorObject.Code)
+	if len(errorObject.Errors) > 0 {
+		message += ": " + strings.Join(errorObject.Errors, ", ")
+	}
+	return message
+}
@@ -0,0 +1,60 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/PagerDuty/go-pagerduty"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
+	"k8s.io/client-go/tools/record"
+)
+
+var _ = Describe("Events", func() {
+	var fakeRecorder *record.FakeRecorder
+	var recorder *Recorder
+
+	BeforeEach(func() {
+		fakeRecorder = record.NewFakeRecorder(10)
+		recorder = NewRecorder(fakeRecorder, &v1alpha1.Schedule{}, "Schedule")
+	})
+
+	It("should record mutations with the upstream ID", func() {
+		recorder.Created("PSCHED1")
+		recorder.Updated("PSCHED1", []string{"name", "time_zone"})
+
+		Expect(<-fakeRecorder.Events).To(Equal("Normal Created Created PagerDuty Schedule PSCHED1"))
+		Expect(<-fakeRecorder.Events).To(Equal("Normal Updated Updated name, time_zone of PagerDuty Schedule PSCHED1"))
+	})
+
+	It("should record API failures with the PagerDuty error code", func() {
+		apiErr := pagerduty.APIError{
+			StatusCode: 400,
+			APIError: pagerduty.NullAPIErrorObject{
+				Valid:       true,
+				ErrorObject: pagerduty.APIErrorObject{Code: 2001, Message: "Invalid Input Provided", Errors: []string{"Name has already been taken"}},
+			},
+		}
+		recorder.Failed("Create", fmt.Errorf("wrapped: %w", apiErr))
+
+		Expect(<-fakeRecorder.Events).To(Equal(
+			"Warning CreateFailed Failed to create PagerDuty Schedule: Invalid Input Provided (HTTP 400, PagerDuty error code 2001): Name has already been taken"))
+	})
+
+	It("should record other failures as they are", func() {
+		recorder.Failed("Delete", errors.New("connection refused"))
+
+		Expect(<-fakeRecorder.Events).To(Equal("Warning DeleteFailed Failed to delete PagerDuty Schedule: connection refused"))
+	})
+
+	It("should record upstream objects deleted outside the operator", func() {
+		recorder.Missing("PSCHED1")
+
+		Expect(<-fakeRecorder.Events).To(Equal("Warning UpstreamMissing PagerDuty Schedule PSCHED1 no longer exists upstream, recreating it"))
+	})
+
+	It("should drop the events without an EventRecorder", func() {
+		Expect(func() { NewRecorder(nil, &v1alpha1.Schedule{}, "Schedule").Created("PSCHED1") }).NotTo(Panic())
+	})
+})
@@ -0,0 +1,14 @@
+package events
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestEvents(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "Events Suite")
+}
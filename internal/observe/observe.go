@@ -0,0 +1,114 @@
+package observe
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
+)
+
+// ModeAnnotation overrides the operator's --mode for a single resource
+const ModeAnnotation = "pagerduty.platform.share-now.com/mode"
+
+const (
+	// ReasonDriftDetected is the reason of the DriftDetected condition when the spec differs from upstream
+	ReasonDriftDetected = "DriftDetected"
+	// ReasonUpstreamMissing is the reason of the DriftDetected condition when the upstream object does not exist
+	ReasonUpstreamMissing = "UpstreamMissing"
+	// ReasonNoDrift is the reason of the DriftDetected condition when the spec matches upstream
+	ReasonNoDrift = "NoDrift"
+)
+
+// Mode defines whether the operator changes PagerDuty or only reports what it would change
+type Mode string
+
+const (
+	// ModeManage creates, updates and deletes the upstream objects
+	ModeManage Mode = "manage"
+	// ModeObserve never changes PagerDuty, and reports the drift between the spec and the upstream objects instead
+	ModeObserve Mode = "observe"
+)
+
+// IsValid returns true for the known modes
+func (m Mode) IsValid() bool {
+	return m == ModeManage || m == ModeObserve
+}
+
+// ModeOf returns the mode of the object: the value of its annotation, or defaultMode.
+// An invalid annotation returns defaultMode together with an error.
+func ModeOf(obj metav1.Object, defaultMode Mode) (Mode, error) {
+	value, ok := obj.GetAnnotations()[ModeAnnotation]
+	if !ok {
+		return defaultMode, nil
+	}
+
+	if mode := Mode(value); mode.IsValid() {
+		return mode, nil
+	}
+	return defaultMode, fmt.Errorf("invalid %s annotation %q, it must be %s or %s", ModeAnnotation, value, ModeManage, ModeObserve)
+}
+
+// FieldDiff is a field whose desired value differs from its upstream value
+type FieldDiff struct {
+	Field    string      `json:"field"`
+	Desired  interface{} `json:"desired"`
+	Upstream interface{} `json:"upstream"`
+}
+
+// Diff lists the fields of an object that differ from upstream
+type Diff []FieldDiff
+
+// Compare adds the field when its desired and upstream values are not equal
+func (d *Diff) Compare(field string, desired interface{}, upstream interface{}) {
+	if !reflect.DeepEqual(desired, upstream) {
+		d.Add(field, desired, upstream)
+	}
+}
+
+// Add adds a field that was found different by a dedicated comparison
+func (d *Diff) Add(field string, desired interface{}, upstream interface{}) {
+	*d = append(*d, FieldDiff{Field: field, Desired: desired, Upstream: upstream})
+}
+
+// Fields returns the names of the differing fields
+func (d Diff) Fields() []string {
+	fields := make([]string, len(d))
+	for i, diff := range d {
+		fields[i] = diff.Field
+	}
+	return fields
+}
+
+// String formats the diff as JSON, for conditions and events
+func (d Diff) String() string {
+	out, err := json.Marshal(d)
+	if err != nil {
+		return fmt.Sprintf("%v", []FieldDiff(d))
+	}
+	return string(out)
+}
+
+// DriftCondition returns the status, reason and message of the DriftDetected condition.
+// upstreamExists is false when the resource has no upstream object, which is never created in observe mode.
+func DriftCondition(upstreamExists bool, diff Diff) (metav1.ConditionStatus, string, string) {
+	switch {
+	case !upstreamExists:
+		return metav1.ConditionTrue, ReasonUpstreamMissing, "No upstream object found, it is not created in observe mode"
+	case len(diff) > 0:
+		return metav1.ConditionTrue, ReasonDriftDetected, diff.String()
+	default:
+		return metav1.ConditionFalse, ReasonNoDrift, "Spec matches upstream"
+	}
+}
+
+// DeletionPolicy returns the deletion policy applied to a resource. Observed upstream objects are never changed,
+// not even on deletion, so they are orphaned whatever the policy of the resource.
+func DeletionPolicy(mode Mode, policy v1alpha1.DeletionPolicy) v1alpha1.DeletionPolicy {
+	if mode == ModeObserve {
+		return v1alpha1.DeletionPolicyOrphan
+	}
+	return policy
+}
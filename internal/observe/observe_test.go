@@ -0,0 +1,89 @@
+package observe
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
+)
+
+var _ = Describe("Observe", func() {
+
+	Describe("Reading the mode of a resource", func() {
+		annotated := func(value string) *metav1.ObjectMeta {
+			return &metav1.ObjectMeta{Annotations: map[string]string{ModeAnnotation: value}}
+		}
+
+		It("should use the default mode without annotation", func() {
+			mode, err := ModeOf(&metav1.ObjectMeta{}, ModeObserve)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mode).To(Equal(ModeObserve))
+		})
+
+		It("should use the annotation", func() {
+			mode, err := ModeOf(annotated("observe"), ModeManage)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mode).To(Equal(ModeObserve))
+		})
+
+		It("should fall back to the default mode on an invalid annotation", func() {
+			mode, err := ModeOf(annotated("dry-run"), ModeManage)
+			Expect(err).To(HaveOccurred())
+			Expect(mode).To(Equal(ModeManage))
+		})
+	})
+
+	Describe("Comparing fields", func() {
+		It("should only list the differing fields", func() {
+			var diff Diff
+			diff.Compare("name", "desired", "desired")
+			diff.Compare("description", "desired", "upstream")
+			diff.Compare("users", []string{"P1", "P2"}, []string{"P1", "P2"})
+
+			Expect(diff.Fields()).To(Equal([]string{"description"}))
+			Expect(diff.String()).To(Equal(`[{"field":"description","desired":"desired","upstream":"upstream"}]`))
+		})
+
+		It("should compare pointed values", func() {
+			desired, upstream := uint(300), uint(300)
+
+			var diff Diff
+			diff.Compare("auto_resolve_timeout", &desired, &upstream)
+			Expect(diff).To(BeEmpty())
+		})
+	})
+
+	Describe("Reporting the drift", func() {
+		It("should report a missing upstream object", func() {
+			status, reason, _ := DriftCondition(false, nil)
+			Expect(status).To(Equal(metav1.ConditionTrue))
+			Expect(reason).To(Equal(ReasonUpstreamMissing))
+		})
+
+		It("should report the diff", func() {
+			diff := Diff{{Field: "name", Desired: "desired", Upstream: "upstream"}}
+
+			status, reason, message := DriftCondition(true, diff)
+			Expect(status).To(Equal(metav1.ConditionTrue))
+			Expect(reason).To(Equal(ReasonDriftDetected))
+			Expect(message).To(Equal(diff.String()))
+		})
+
+		It("should report an upstream object matching the spec", func() {
+			status, reason, _ := DriftCondition(true, nil)
+			Expect(status).To(Equal(metav1.ConditionFalse))
+			Expect(reason).To(Equal(ReasonNoDrift))
+		})
+	})
+
+	Describe("Applying the deletion policy", func() {
+		It("should orphan observed upstream objects", func() {
+			Expect(DeletionPolicy(ModeObserve, v1alpha1.DeletionPolicyDelete)).To(Equal(v1alpha1.DeletionPolicyOrphan))
+		})
+
+		It("should keep the policy of managed resources", func() {
+			Expect(DeletionPolicy(ModeManage, v1alpha1.DeletionPolicyRetain)).To(Equal(v1alpha1.DeletionPolicyRetain))
+		})
+	})
+})
@@ -0,0 +1,14 @@
+package observe
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestObserve(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "Observe Suite")
+}
@@ -0,0 +1,20 @@
+package pause
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Annotation pauses the reconciliation of a single resource when set to "true", e.g. while it is edited by hand in PagerDuty
+const Annotation = "pagerduty.platform.share-now.com/paused"
+
+const (
+	// ReasonPaused is the reason of the Paused condition while the annotation is set
+	ReasonPaused = "Paused"
+	// ReasonResumed is the reason of the Paused condition once the annotation is removed
+	ReasonResumed = "Resumed"
+)
+
+// IsPaused returns true when the pause annotation of the object is set to "true"
+func IsPaused(obj metav1.Object) bool {
+	return obj.GetAnnotations()[Annotation] == "true"
+}
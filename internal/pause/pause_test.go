@@ -0,0 +1,26 @@
+package pause
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Pause", func() {
+	annotated := func(value string) *metav1.ObjectMeta {
+		return &metav1.ObjectMeta{Annotations: map[string]string{Annotation: value}}
+	}
+
+	It("should not pause resources without annotation", func() {
+		Expect(IsPaused(&metav1.ObjectMeta{})).To(BeFalse())
+	})
+
+	It("should pause resources annotated with true", func() {
+		Expect(IsPaused(annotated("true"))).To(BeTrue())
+	})
+
+	It("should not pause resources annotated with another value", func() {
+		Expect(IsPaused(annotated("false"))).To(BeFalse())
+		Expect(IsPaused(annotated("yes"))).To(BeFalse())
+	})
+})
@@ -0,0 +1,14 @@
+package pause
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestPause(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "Pause Suite")
+}
@@ -0,0 +1,87 @@
+package pd_errors
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/PagerDuty/go-pagerduty"
+)
+
+// Class groups the errors of the PagerDuty API by how the operator reacts to them
+type Class string
+
+const (
+	// Unauthorized is returned when the API token is missing or rejected, see the credentials package
+	Unauthorized Class = "Unauthorized"
+	// Forbidden is returned when the API token may not access the object, or the account lacks the feature
+	Forbidden Class = "Forbidden"
+	// NotFound is returned when the upstream object does not exist (anymore)
+	NotFound Class = "NotFound"
+	// Invalid is returned when PagerDuty rejects the request as invalid or conflicting, retrying does not help
+	// until the spec changes
+	Invalid Class = "Invalid"
+	// RateLimited is returned when the REST API rate limit of the account is exhausted
+	RateLimited Class = "RateLimited"
+	// Transient covers server errors and any other failure, e.g. of the network, that a retry may fix
+	Transient Class = "Transient"
+)
+
+const (
+	// RateLimitBackoff is the delay before retrying a rate limited call. PagerDuty counts the calls per minute.
+	RateLimitBackoff = time.Minute
+	// ForbiddenBackoff is the delay before retrying a call forbidden to the API token, which only succeeds
+	// once the permissions of the token or the plan of the account change
+	ForbiddenBackoff = 5 * time.Minute
+)
+
+// ReasonNoError is the reason of the Error condition cleared once the PagerDuty API calls of a resource succeed
+const ReasonNoError = "NoError"
+
+// Classify returns the class of an error returned by the PagerDuty API, also when it is wrapped.
+// Errors that are not PagerDuty API errors are Transient.
+func Classify(err error) Class {
+	var apiErr pagerduty.APIError
+	if !errors.As(err, &apiErr) {
+		return Transient
+	}
+
+	switch {
+	case apiErr.NotFound():
+		return NotFound
+	case apiErr.StatusCode == http.StatusUnauthorized:
+		return Unauthorized
+	case apiErr.StatusCode == http.StatusForbidden, apiErr.StatusCode == http.StatusPaymentRequired:
+		return Forbidden
+	case apiErr.RateLimited():
+		return RateLimited
+	case apiErr.StatusCode == http.StatusBadRequest, apiErr.StatusCode == http.StatusConflict,
+		apiErr.StatusCode == http.StatusUnprocessableEntity:
+		return Invalid
+	default:
+		return Transient
+	}
+}
+
+// IsNotFound returns true if the upstream object of the request does not exist
+func IsNotFound(err error) bool {
+	return err != nil && Classify(err) == NotFound
+}
+
+// IsInvalid returns true if PagerDuty rejected the request as invalid
+func IsInvalid(err error) bool {
+	return err != nil && Classify(err) == Invalid
+}
+
+// Backoff returns the delay before retrying after err, when its class asks for one.
+// The other errors are retried with the backoff of the controller.
+func Backoff(err error) (time.Duration, bool) {
+	switch Classify(err) {
+	case RateLimited:
+		return RateLimitBackoff, true
+	case Forbidden:
+		return ForbiddenBackoff, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,60 @@
+package pd_errors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/PagerDuty/go-pagerduty"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func apiError(statusCode int, code int) error {
+	apiErr := pagerduty.APIError{StatusCode: statusCode}
+	if code != 0 {
+		apiErr.APIError = pagerduty.NullAPIErrorObject{Valid: true, ErrorObject: pagerduty.APIErrorObject{Code: code}}
+	}
+	return apiErr
+}
+
+var _ = Describe("Classify", func() {
+	DescribeTable("classifies the PagerDuty API errors",
+		func(err error, class Class) {
+			Expect(Classify(err)).To(Equal(class))
+		},
+		Entry("401", apiError(http.StatusUnauthorized, 0), Unauthorized),
+		Entry("402", apiError(http.StatusPaymentRequired, 0), Forbidden),
+		Entry("403", apiError(http.StatusForbidden, 0), Forbidden),
+		Entry("404", apiError(http.StatusNotFound, 0), NotFound),
+		Entry("400 with the not found error code", apiError(http.StatusBadRequest, 2100), NotFound),
+		Entry("400", apiError(http.StatusBadRequest, 2001), Invalid),
+		Entry("409", apiError(http.StatusConflict, 0), Invalid),
+		Entry("422", apiError(http.StatusUnprocessableEntity, 0), Invalid),
+		Entry("429", apiError(http.StatusTooManyRequests, 0), RateLimited),
+		Entry("500", apiError(http.StatusInternalServerError, 0), Transient),
+		Entry("503", apiError(http.StatusServiceUnavailable, 0), Transient),
+		Entry("not an API error", errors.New("connection refused"), Transient),
+	)
+
+	It("unwraps the API error", func() {
+		err := Wrap(fmt.Errorf("failed to update service: %w", apiError(http.StatusNotFound, 0)), "update")
+
+		Expect(Classify(err)).To(Equal(NotFound))
+		Expect(IsNotFound(err)).To(BeTrue())
+		Expect(IsInvalid(err)).To(BeFalse())
+	})
+
+	It("returns the backoff of rate limited and forbidden calls", func() {
+		backoff, ok := Backoff(apiError(http.StatusTooManyRequests, 0))
+		Expect(ok).To(BeTrue())
+		Expect(backoff).To(Equal(RateLimitBackoff))
+
+		backoff, ok = Backoff(apiError(http.StatusForbidden, 0))
+		Expect(ok).To(BeTrue())
+		Expect(backoff).To(Equal(ForbiddenBackoff))
+
+		_, ok = Backoff(apiError(http.StatusInternalServerError, 0))
+		Expect(ok).To(BeFalse())
+	})
+})
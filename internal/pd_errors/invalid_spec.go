@@ -0,0 +1,27 @@
+package pd_errors
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
+)
+
+const (
+	// ReasonInvalidSpec is the reason of the Ready condition while PagerDuty rejects the spec
+	ReasonInvalidSpec = "InvalidSpec"
+	// ReasonRejected is the reason of the InvalidSpec condition set when PagerDuty rejects the spec
+	ReasonRejected = "RejectedByPagerDuty"
+	// ReasonSpecChanged is the reason of the InvalidSpec condition cleared once the rejected spec changed
+	ReasonSpecChanged = "SpecChanged"
+	// ReasonUpstreamMissing is the reason of the Synced condition cleared when the upstream object was deleted
+	// outside the operator
+	ReasonUpstreamMissing = "UpstreamMissing"
+)
+
+// SpecRejected returns true while the InvalidSpec condition is set for the given generation of the spec.
+// Retrying the rejected calls cannot succeed until the spec changes.
+func SpecRejected(conditions []metav1.Condition, generation int64) bool {
+	condition := meta.FindStatusCondition(conditions, v1alpha1.ConditionInvalidSpec.String())
+	return condition != nil && condition.Status == metav1.ConditionTrue && condition.ObservedGeneration == generation
+}
@@ -0,0 +1,14 @@
+package pd_errors
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestPDErrors(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "PagerDuty Errors Suite")
+}
@@ -0,0 +1,80 @@
+package pd_metrics
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/PagerDuty/go-pagerduty"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// OutcomeSuccess labels the API requests answered with a 2xx or 3xx status
+	OutcomeSuccess = "success"
+	// OutcomeError labels the API requests answered with an error status or not answered at all
+	OutcomeError = "error"
+
+	// unlabelledMethod labels the API requests not sent by an adapter method, e.g. the user lookups of the webhooks
+	unlabelledMethod = "other"
+	// noStatus is the code of the API requests that got no response
+	noStatus = "none"
+)
+
+var (
+	apiRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "pagerduty_api_requests_total",
+		Help: "Number of PagerDuty API requests, by adapter method, outcome and HTTP status.",
+	}, []string{"method", "outcome", "code"})
+
+	apiRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "pagerduty_api_request_duration_seconds",
+		Help:    "Duration of the PagerDuty API requests including retries and throttling, by adapter method and outcome.",
+		Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"method", "outcome"})
+)
+
+type methodKey struct{}
+
+// Method returns the context the adapter method passes to the PagerDuty client: the context of the reconcile,
+// so the API requests it sends are cancelled with it, labelled with the name of the method, e.g. CreatePDService
+func Method(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, methodKey{}, name)
+}
+
+// methodOf returns the adapter method that sent the request
+func methodOf(ctx context.Context) string {
+	if name, ok := ctx.Value(methodKey{}).(string); ok {
+		return name
+	}
+	return unlabelledMethod
+}
+
+// Instrument returns an HTTP client recording the requests of next in the API metrics
+func Instrument(next pagerduty.HTTPClient) pagerduty.HTTPClient {
+	return &instrumentedClient{next: next}
+}
+
+type instrumentedClient struct {
+	next pagerduty.HTTPClient
+}
+
+func (c *instrumentedClient) Do(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := c.next.Do(req)
+
+	outcome, code := OutcomeError, noStatus
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+		if resp.StatusCode < http.StatusBadRequest {
+			outcome = OutcomeSuccess
+		}
+	}
+
+	method := methodOf(req.Context())
+	apiRequests.WithLabelValues(method, outcome, code).Inc()
+	apiRequestDuration.WithLabelValues(method, outcome).Observe(time.Since(start).Seconds())
+
+	return resp, err
+}
@@ -0,0 +1,72 @@
+package pd_metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/PagerDuty/go-pagerduty"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+var _ = Describe("API metrics", func() {
+	var server *httptest.Server
+	var pdClient *pagerduty.Client
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/services/PMISSING" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"service": {"id": "PSERVICE1"}}`))
+		}))
+		pdClient = pagerduty.NewClient("token", pagerduty.WithAPIEndpoint(server.URL))
+		pdClient.HTTPClient = Instrument(pdClient.HTTPClient)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should count the requests by adapter method, outcome and HTTP status", func() {
+		_, err := pdClient.GetServiceWithContext(Method(context.TODO(), "GetPDService"), "PSERVICE1", nil)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = pdClient.GetServiceWithContext(Method(context.TODO(), "GetPDService"), "PMISSING", nil)
+		Expect(err).To(HaveOccurred())
+
+		Expect(testutil.ToFloat64(apiRequests.WithLabelValues("GetPDService", OutcomeSuccess, "200"))).To(Equal(1.0))
+		Expect(testutil.ToFloat64(apiRequests.WithLabelValues("GetPDService", OutcomeError, "404"))).To(Equal(1.0))
+		Expect(testutil.CollectAndCount(apiRequestDuration, "pagerduty_api_request_duration_seconds")).To(BeNumerically(">=", 2))
+	})
+
+	It("should cancel the requests with the context of the reconcile", func() {
+		ctx, cancel := context.WithCancel(context.TODO())
+		cancel()
+
+		_, err := pdClient.GetServiceWithContext(Method(ctx, "GetPDService"), "PSERVICE1", nil)
+		Expect(err).To(MatchError(ContainSubstring(context.Canceled.Error())))
+		Expect(testutil.ToFloat64(apiRequests.WithLabelValues("GetPDService", OutcomeError, noStatus))).To(Equal(1.0))
+	})
+
+	It("should label the requests sent outside an adapter method", func() {
+		before := testutil.ToFloat64(apiRequests.WithLabelValues(unlabelledMethod, OutcomeSuccess, "200"))
+
+		_, err := pdClient.GetServiceWithContext(context.TODO(), "PSERVICE1", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(testutil.ToFloat64(apiRequests.WithLabelValues(unlabelledMethod, OutcomeSuccess, "200"))).To(Equal(before + 1))
+	})
+
+	It("should count the requests without a response as errors", func() {
+		server.Close()
+
+		err := pdClient.DeleteServiceWithContext(Method(context.TODO(), "DeletePDService"), "PSERVICE1")
+		Expect(err).To(HaveOccurred())
+
+		Expect(testutil.ToFloat64(apiRequests.WithLabelValues("DeletePDService", OutcomeError, noStatus))).To(Equal(1.0))
+	})
+})
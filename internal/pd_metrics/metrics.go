@@ -0,0 +1,9 @@
+package pd_metrics
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+func init() {
+	metrics.Registry.MustRegister(apiRequests, apiRequestDuration, driftCorrections)
+}
@@ -0,0 +1,148 @@
+package pd_metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
+)
+
+// listTimeout bounds the listing of the resources during a scrape
+const listTimeout = 10 * time.Second
+
+// reportedConditions are the conditions counted by the resources gauge
+var reportedConditions = []v1alpha1.ConditionType{
+	v1alpha1.ConditionReady,
+	v1alpha1.ConditionPending,
+	v1alpha1.ConditionError,
+	v1alpha1.ConditionDriftDetected,
+}
+
+var (
+	driftCorrections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "pagerduty_drift_corrections_total",
+		Help: "Number of upstream PagerDuty objects updated because they drifted from their spec, by kind and namespace.",
+	}, []string{"kind", "namespace"})
+
+	resourcesDesc = prometheus.NewDesc(
+		"pagerduty_resources",
+		"Number of pagerduty custom resources by kind, namespace, condition and condition status. "+
+			"Resources without the condition are counted with the status Unknown.",
+		[]string{"kind", "namespace", "condition", "status"}, nil,
+	)
+)
+
+// DriftCorrected counts an upstream object updated to match the spec of the resource again
+func DriftCorrected(kind string, namespace string) {
+	driftCorrections.WithLabelValues(kind, namespace).Inc()
+}
+
+// kindList lists the resources of a kind and returns their namespaces and conditions
+type kindList struct {
+	kind string
+	list func(ctx context.Context, reader client.Reader) ([]resourceConditions, error)
+}
+
+type resourceConditions struct {
+	namespace  string
+	conditions []metav1.Condition
+}
+
+// kindOf returns the kindList of a kind from the items of its list type and the conditions of an item
+func kindOf[L any, I any, PL interface {
+	*L
+	client.ObjectList
+}, PI interface {
+	*I
+	client.Object
+}](kind string, items func(PL) []I, conditions func(PI) []metav1.Condition) kindList {
+	return kindList{kind: kind, list: func(ctx context.Context, reader client.Reader) ([]resourceConditions, error) {
+		list := PL(new(L))
+		if err := reader.List(ctx, list); err != nil {
+			return nil, err
+		}
+
+		listed := items(list)
+		resources := make([]resourceConditions, 0, len(listed))
+		for i := range listed {
+			item := PI(&listed[i])
+			resources = append(resources, resourceConditions{item.GetNamespace(), conditions(item)})
+		}
+		return resources, nil
+	}}
+}
+
+var kindLists = []kindList{
+	kindOf("PagerdutyService",
+		func(list *v1alpha1.PagerdutyServiceList) []v1alpha1.PagerdutyService { return list.Items },
+		func(item *v1alpha1.PagerdutyService) []metav1.Condition { return item.Status.Conditions }),
+	kindOf("EscalationPolicy",
+		func(list *v1alpha1.EscalationPolicyList) []v1alpha1.EscalationPolicy { return list.Items },
+		func(item *v1alpha1.EscalationPolicy) []metav1.Condition { return item.Status.Conditions }),
+	kindOf("BusinessService",
+		func(list *v1alpha1.BusinessServiceList) []v1alpha1.BusinessService { return list.Items },
+		func(item *v1alpha1.BusinessService) []metav1.Condition { return item.Status.Conditions }),
+	kindOf("Schedule",
+		func(list *v1alpha1.ScheduleList) []v1alpha1.Schedule { return list.Items },
+		func(item *v1alpha1.Schedule) []metav1.Condition { return item.Status.Conditions }),
+	kindOf("Team",
+		func(list *v1alpha1.TeamList) []v1alpha1.Team { return list.Items },
+		func(item *v1alpha1.Team) []metav1.Condition { return item.Status.Conditions }),
+}
+
+// ResourceCollector counts the pagerduty custom resources by condition when the metrics are scraped.
+// It reads them from the manager's cache, so a scrape does not hit the API server.
+type ResourceCollector struct {
+	reader client.Reader
+}
+
+// NewResourceCollector returns a ResourceCollector listing the resources through the given reader
+func NewResourceCollector(reader client.Reader) *ResourceCollector {
+	return &ResourceCollector{reader: reader}
+}
+
+func (c *ResourceCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- resourcesDesc
+}
+
+func (c *ResourceCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), listTimeout)
+	defer cancel()
+
+	type series struct {
+		namespace string
+		condition v1alpha1.ConditionType
+		status    metav1.ConditionStatus
+	}
+
+	for _, kindList := range kindLists {
+		resources, err := kindList.list(ctx, c.reader)
+		if err != nil {
+			// a failed kind must not break the scrape of every other metric
+			log.Log.WithName("metrics").Error(err, "Failed to list resources", "kind", kindList.kind)
+			continue
+		}
+
+		counts := map[series]int{}
+		for _, resource := range resources {
+			for _, conditionType := range reportedConditions {
+				status := metav1.ConditionUnknown
+				if condition := meta.FindStatusCondition(resource.conditions, conditionType.String()); condition != nil {
+					status = condition.Status
+				}
+				counts[series{resource.namespace, conditionType, status}]++
+			}
+		}
+
+		for s, count := range counts {
+			ch <- prometheus.MustNewConstMetric(resourcesDesc, prometheus.GaugeValue, float64(count),
+				kindList.kind, s.namespace, s.condition.String(), string(s.status))
+		}
+	}
+}
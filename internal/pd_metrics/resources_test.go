@@ -0,0 +1,83 @@
+package pd_metrics
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
+)
+
+// gaugeValues returns the values of the resources gauge by kind, namespace, condition and status
+func gaugeValues(collector prometheus.Collector) map[[4]string]float64 {
+	registry := prometheus.NewPedanticRegistry()
+	Expect(registry.Register(collector)).To(Succeed())
+	families, err := registry.Gather()
+	Expect(err).NotTo(HaveOccurred())
+
+	values := map[[4]string]float64{}
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			values[[4]string{labels["kind"], labels["namespace"], labels["condition"], labels["status"]}] = metric.GetGauge().GetValue()
+		}
+	}
+	return values
+}
+
+var _ = Describe("Resource metrics", func() {
+	It("should count the resources by kind, namespace and condition", func() {
+		scheme := runtime.NewScheme()
+		Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+
+		ready := metav1.Condition{Type: "Ready", Status: metav1.ConditionTrue}
+		notReady := metav1.Condition{Type: "Ready", Status: metav1.ConditionFalse}
+		drifted := metav1.Condition{Type: "DriftDetected", Status: metav1.ConditionTrue}
+		failed := metav1.Condition{Type: "Error", Status: metav1.ConditionTrue}
+		objects := []client.Object{
+			&v1alpha1.PagerdutyService{
+				ObjectMeta: metav1.ObjectMeta{Name: "checkout", Namespace: "shop"},
+				Status:     v1alpha1.PagerdutyServiceStatus{Conditions: []metav1.Condition{ready}},
+			},
+			&v1alpha1.PagerdutyService{
+				ObjectMeta: metav1.ObjectMeta{Name: "payment", Namespace: "shop"},
+				Status:     v1alpha1.PagerdutyServiceStatus{Conditions: []metav1.Condition{ready, drifted}},
+			},
+			&v1alpha1.PagerdutyService{
+				ObjectMeta: metav1.ObjectMeta{Name: "search", Namespace: "catalog"},
+				Status:     v1alpha1.PagerdutyServiceStatus{Conditions: []metav1.Condition{notReady, failed}},
+			},
+			&v1alpha1.Team{
+				ObjectMeta: metav1.ObjectMeta{Name: "platform", Namespace: "shop"},
+			},
+		}
+		reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+
+		values := gaugeValues(NewResourceCollector(reader))
+
+		Expect(values).To(HaveKeyWithValue([4]string{"PagerdutyService", "shop", "Ready", "True"}, 2.0))
+		Expect(values).To(HaveKeyWithValue([4]string{"PagerdutyService", "catalog", "Ready", "False"}, 1.0))
+		Expect(values).To(HaveKeyWithValue([4]string{"PagerdutyService", "shop", "DriftDetected", "True"}, 1.0))
+		Expect(values).To(HaveKeyWithValue([4]string{"PagerdutyService", "shop", "DriftDetected", "Unknown"}, 1.0))
+		Expect(values).To(HaveKeyWithValue([4]string{"PagerdutyService", "shop", "Pending", "Unknown"}, 2.0))
+		Expect(values).To(HaveKeyWithValue([4]string{"Team", "shop", "Ready", "Unknown"}, 1.0))
+		Expect(values).NotTo(HaveKey([4]string{"EscalationPolicy", "shop", "Ready", "Unknown"}))
+		Expect(values).To(HaveKeyWithValue([4]string{"PagerdutyService", "catalog", "Error", "True"}, 1.0))
+		Expect(values).To(HaveKeyWithValue([4]string{"PagerdutyService", "shop", "Error", "Unknown"}, 2.0))
+	})
+
+	It("should count drift corrections by kind and namespace", func() {
+		DriftCorrected("Schedule", "oncall")
+		DriftCorrected("Schedule", "oncall")
+
+		Expect(testutil.ToFloat64(driftCorrections.WithLabelValues("Schedule", "oncall"))).To(Equal(2.0))
+	})
+})
@@ -0,0 +1,14 @@
+package pd_metrics
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestPDMetrics(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "PagerDuty Metrics Suite")
+}
@@ -0,0 +1,16 @@
+package pd_utils
+
+import "fmt"
+
+// SingleMatch returns the ID of the only upstream object matching an adoption by name, or an empty ID when none matches.
+// Several matches are an error, since the object to adopt has to be picked by ID then.
+func SingleMatch(kind string, name string, ids []string) (string, error) {
+	switch len(ids) {
+	case 0:
+		return "", nil
+	case 1:
+		return ids[0], nil
+	default:
+		return "", fmt.Errorf("found %d upstream objects of kind %s named %q, set adopt.id to pick the one to adopt", len(ids), kind, name)
+	}
+}
@@ -0,0 +1,24 @@
+package pd_utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SingleMatch", func() {
+	DescribeTable("picks the upstream object to adopt by name",
+		func(ids []string, expectedID string, expectedErr string) {
+			id, err := SingleMatch("service", "checkout", ids)
+			if expectedErr != "" {
+				Expect(err).To(MatchError(expectedErr))
+			} else {
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(id).To(Equal(expectedID))
+		},
+		Entry("no match creates a new object", nil, "", ""),
+		Entry("a single match is adopted", []string{"PADOPTED"}, "PADOPTED", ""),
+		Entry("several matches are refused", []string{"PFIRST", "PSECOND"}, "",
+			`found 2 upstream objects of kind service named "checkout", set adopt.id to pick the one to adopt`),
+	)
+})
@@ -1,5 +1,18 @@
 package pd_utils
 
+import "strings"
+
+// RetainedNameSuffix is appended to the name of the upstream objects kept by the Retain deletion policy
+const RetainedNameSuffix = " (retained)"
+
 func Ptr[T any](v T) *T {
 	return &v
 }
+
+// RetainedName returns the name of an upstream object kept by the Retain deletion policy
+func RetainedName(name string) string {
+	if strings.HasSuffix(name, RetainedNameSuffix) {
+		return name
+	}
+	return name + RetainedNameSuffix
+}
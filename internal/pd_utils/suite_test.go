@@ -0,0 +1,14 @@
+package pd_utils
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestPDUtils(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "PD Utils Suite")
+}
@@ -2,28 +2,52 @@ package pdservice
 
 import (
 	"context"
+	"strconv"
 
 	"github.com/PagerDuty/go-pagerduty"
 	"github.com/go-logr/logr"
 	"gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/observe"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_metrics"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_utils"
 	"gitlab.share-now.com/platform/pagerduty-operator/internal/typeinfo"
 )
 
 type Adapter interface {
-	convert(*v1alpha1.PagerdutyServiceSpec) pagerduty.Service
-	CreatePDService(k8sPDService *v1alpha1.PagerdutyServiceSpec) (string, error)
-	GetService(string) (*pagerduty.Service, error)
-	DeleteService(string) error
-	UpdateService(*pagerduty.Service) error
-	EqualToUpstream(*pagerduty.Service) bool
+	CreatePDService(*v1alpha1.PagerdutyService) (string, error)
+	GetPDService(string) (*pagerduty.Service, error)
+	DeletePDService(string) error
+	UpdatePDService(*v1alpha1.PagerdutyService) error
+	UpstreamDrift(*v1alpha1.PagerdutyService) (observe.Diff, error)
+	CreateIntegration(string, v1alpha1.ServiceIntegration) (*pagerduty.Integration, error)
+	GetIntegrationKey(string, string) (string, error)
+	DeleteIntegration(string, string) error
+	FindPDService(*v1alpha1.PagerdutyServiceSpec) (string, error)
+	RetainPDService(string) error
+}
+
+// AdapterFactory builds the Adapter used by a single reconcile on top of the given PagerDuty client
+type AdapterFactory func(ctx context.Context, logger logr.Logger, pdClient *pagerduty.Client) Adapter
+
+// NewPDServiceAdapter is the AdapterFactory that talks to the PagerDuty API
+func NewPDServiceAdapter(ctx context.Context, logger logr.Logger, pdClient *pagerduty.Client) Adapter {
+	return &PDServiceAdapter{
+		Ctx:       ctx,
+		Logger:    logger,
+		PD_Client: pdClient,
+	}
 }
 
 type PDServiceAdapter struct {
+	// Ctx is the context of the reconcile, cancelling it cancels the API calls of the adapter
+	Ctx       context.Context
 	Logger    logr.Logger
 	PD_Client *pagerduty.Client
 }
 
 var pdservice_reference_type string = "service"
+var events_api_v2_integration_type string = "events_api_v2_inbound_integration"
+var vendor_reference_type string = "vendor_reference"
 
 // func (adapter *PDServiceAdapter) convertSpec(spec *v1alpha1.PagerdutyServiceSpec) pagerduty.Service {
 // 	return pagerduty.Service{
@@ -60,7 +84,7 @@ func (adapter *PDServiceAdapter) CreatePDService(k8sPDService *v1alpha1.Pagerdut
 	// Get Escalation Policy ID, update this service with the ID
 	// Finally you can create the service
 
-	res, err := adapter.PD_Client.CreateServiceWithContext(context.TODO(), adapter.convert(k8sPDService))
+	res, err := adapter.PD_Client.CreateServiceWithContext(pd_metrics.Method(adapter.Ctx, "CreatePDService"), adapter.convert(k8sPDService))
 	if err != nil {
 		adapter.Logger.Error(err, "PagerDuty Service creation unsuccessfull...")
 		return "", err
@@ -70,7 +94,7 @@ func (adapter *PDServiceAdapter) CreatePDService(k8sPDService *v1alpha1.Pagerdut
 }
 
 func (adapter *PDServiceAdapter) GetPDService(id string) (*pagerduty.Service, error) {
-	PDService, err := adapter.PD_Client.GetServiceWithContext(context.TODO(), id, &pagerduty.GetServiceOptions{})
+	PDService, err := adapter.PD_Client.GetServiceWithContext(pd_metrics.Method(adapter.Ctx, "GetPDService"), id, &pagerduty.GetServiceOptions{})
 	if err != nil {
 		adapter.Logger.Error(err, "Failed to get PagerDuty Service")
 		return nil, err
@@ -82,7 +106,7 @@ func (adapter *PDServiceAdapter) GetPDService(id string) (*pagerduty.Service, er
 
 func (adapter *PDServiceAdapter) UpdatePDService(k8sPDService *v1alpha1.PagerdutyService) error {
 	adapter.Logger.Info("Updating upstream service with API call...")
-	_, err := adapter.PD_Client.UpdateServiceWithContext(context.TODO(), adapter.convert(k8sPDService))
+	_, err := adapter.PD_Client.UpdateServiceWithContext(pd_metrics.Method(adapter.Ctx, "UpdatePDService"), adapter.convert(k8sPDService))
 
 	if err != nil {
 		adapter.Logger.Error(err, "API Failed to update PagerDuty Service")
@@ -98,7 +122,7 @@ func (adapter *PDServiceAdapter) DeletePDService(id string) error {
 
 	// TODO: Check if it's necessary to delete the escalation policy
 
-	err := adapter.PD_Client.DeleteServiceWithContext(context.TODO(), id)
+	err := adapter.PD_Client.DeleteServiceWithContext(pd_metrics.Method(adapter.Ctx, "DeletePDService"), id)
 	if err != nil {
 		adapter.Logger.Error(err, "ERROR: Failed to delete PagerDuty Service")
 		return err
@@ -108,19 +132,151 @@ func (adapter *PDServiceAdapter) DeletePDService(id string) error {
 	return nil
 }
 
-func (adapter *PDServiceAdapter) EqualToUpstream(k8sPDService *v1alpha1.PagerdutyService) (bool, error) {
+// RetainPDService renames the upstream service with the retained suffix instead of deleting it
+func (adapter *PDServiceAdapter) RetainPDService(id string) error {
+	adapter.Logger.Info("Retaining PagerDuty Service...")
+
+	PDService, err := adapter.GetPDService(id)
+	if err != nil {
+		return err
+	}
+
+	PDService.Name = pd_utils.RetainedName(PDService.Name)
+	if _, err := adapter.PD_Client.UpdateServiceWithContext(pd_metrics.Method(adapter.Ctx, "RetainPDService"), *PDService); err != nil {
+		adapter.Logger.Error(err, "API Failed to rename retained PagerDuty Service")
+		return err
+	}
+
+	adapter.Logger.Info("PagerDuty Service retained...", "name", PDService.Name)
+	return nil
+}
+
+// UpstreamDrift returns the spec fields whose value differs from the upstream service
+func (adapter *PDServiceAdapter) UpstreamDrift(k8sPDService *v1alpha1.PagerdutyService) (observe.Diff, error) {
 	PDService, err := adapter.GetPDService(k8sPDService.Status.ServiceID)
 	if err != nil {
 		adapter.Logger.Error(err, "Failed to get Escalation policy")
-		return false, err
+		return nil, err
+	}
+
+	return serviceDiff(adapter.convert(k8sPDService), PDService), nil
+}
+
+// serviceDiff compares the converted spec with the upstream service field by field.
+// The escalation policy is reported by ID.
+func serviceDiff(convertedk8sPDService pagerduty.Service, PDService *pagerduty.Service) observe.Diff {
+	var diff observe.Diff
+
+	diff.Compare("name", convertedk8sPDService.Name, PDService.Name)
+	diff.Compare("description", convertedk8sPDService.Description, PDService.Description)
+	diff.Compare("auto_resolve_timeout", convertedk8sPDService.AutoResolveTimeout, PDService.AutoResolveTimeout)
+	diff.Compare("acknowledgement_timeout", convertedk8sPDService.AcknowledgementTimeout, PDService.AcknowledgementTimeout)
+	diff.Compare("status", convertedk8sPDService.Status, PDService.Status)
+	diff.Compare("alert_creation", convertedk8sPDService.AlertCreation, PDService.AlertCreation)
+	diff.Compare("escalation_policy", convertedk8sPDService.EscalationPolicy.ID, PDService.EscalationPolicy.ID)
+
+	return diff
+}
+
+// FindPDService returns the ID of the upstream service selected by spec.adopt, or an empty ID if there is none to adopt
+func (adapter *PDServiceAdapter) FindPDService(spec *v1alpha1.PagerdutyServiceSpec) (string, error) {
+	if spec.Adopt == nil {
+		return "", nil
+	}
+
+	if spec.Adopt.ID != "" {
+		PDService, err := adapter.GetPDService(spec.Adopt.ID)
+		if err != nil {
+			return "", err
+		}
+		return PDService.ID, nil
+	}
+
+	if !spec.Adopt.ByName {
+		return "", nil
+	}
+
+	PDServices, err := adapter.PD_Client.ListServicesPaginated(pd_metrics.Method(adapter.Ctx, "FindPDService"), pagerduty.ListServiceOptions{Query: spec.Name})
+	if err != nil {
+		adapter.Logger.Error(err, "Failed to list PagerDuty Services")
+		return "", err
 	}
-	convertedk8sPDService := adapter.convert(k8sPDService)
 
-	return convertedk8sPDService.Name == PDService.Name &&
-		convertedk8sPDService.Description == PDService.Description &&
-		*convertedk8sPDService.AutoResolveTimeout == *PDService.AutoResolveTimeout &&
-		*convertedk8sPDService.AcknowledgementTimeout == *PDService.AcknowledgementTimeout &&
-		convertedk8sPDService.Status == PDService.Status &&
-		convertedk8sPDService.AlertCreation == PDService.AlertCreation &&
-		convertedk8sPDService.EscalationPolicy.ID == PDService.EscalationPolicy.ID, nil
+	var ids []string
+	for _, PDService := range PDServices {
+		if PDService.Name == spec.Name {
+			ids = append(ids, PDService.ID)
+		}
+	}
+	return pd_utils.SingleMatch("service", spec.Name, ids)
+}
+
+func convertIntegration(integration v1alpha1.ServiceIntegration) pagerduty.Integration {
+	vendor := integration.Vendor()
+	if vendor == "" {
+		return pagerduty.Integration{
+			APIObject: pagerduty.APIObject{
+				Type: events_api_v2_integration_type,
+			},
+			Name: integration.Name,
+		}
+	}
+
+	return pagerduty.Integration{
+		Name: integration.Name,
+		Vendor: &pagerduty.APIObject{
+			ID:   vendor,
+			Type: vendor_reference_type,
+		},
+	}
+}
+
+func (adapter *PDServiceAdapter) CreateIntegration(serviceID string, integration v1alpha1.ServiceIntegration) (*pagerduty.Integration, error) {
+	adapter.Logger.Info("Creating PagerDuty Service integration...", "integration", integration.Name)
+
+	created, err := adapter.PD_Client.CreateIntegrationWithContext(pd_metrics.Method(adapter.Ctx, "CreateIntegration"), serviceID, convertIntegration(integration))
+	if err != nil {
+		adapter.Logger.Error(err, "API Failed to create PagerDuty Service integration")
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func (adapter *PDServiceAdapter) GetIntegrationKey(serviceID string, integrationID string) (string, error) {
+	integration, err := adapter.PD_Client.GetIntegrationWithContext(pd_metrics.Method(adapter.Ctx, "GetIntegrationKey"), serviceID, integrationID, pagerduty.GetIntegrationOptions{})
+	if err != nil {
+		adapter.Logger.Error(err, "Failed to get PagerDuty Service integration")
+		return "", err
+	}
+
+	return integration.IntegrationKey, nil
+}
+
+func (adapter *PDServiceAdapter) DeleteIntegration(serviceID string, integrationID string) error {
+	adapter.Logger.Info("Deleting PagerDuty Service integration...", "integrationID", integrationID)
+
+	err := adapter.PD_Client.DeleteIntegrationWithContext(pd_metrics.Method(adapter.Ctx, "DeleteIntegration"), serviceID, integrationID)
+	if err != nil {
+		adapter.Logger.Error(err, "API Failed to delete PagerDuty Service integration")
+		return err
+	}
+
+	return nil
+}
+
+// serviceSnapshot returns the managed fields of the upstream service
+func serviceSnapshot(PDService *pagerduty.Service) *v1alpha1.UpstreamSnapshot {
+	snapshot := &v1alpha1.UpstreamSnapshot{Name: PDService.Name, Description: PDService.Description}
+	snapshot.SetField("status", PDService.Status)
+	snapshot.SetField("alert_creation", PDService.AlertCreation)
+	snapshot.SetField("escalation_policy", PDService.EscalationPolicy.ID)
+	if PDService.AutoResolveTimeout != nil {
+		snapshot.SetField("auto_resolve_timeout", strconv.FormatUint(uint64(*PDService.AutoResolveTimeout), 10))
+	}
+	if PDService.AcknowledgementTimeout != nil {
+		snapshot.SetField("acknowledgement_timeout", strconv.FormatUint(uint64(*PDService.AcknowledgementTimeout), 10))
+	}
+	snapshot.SetField("integrations", strconv.Itoa(len(PDService.Integrations)))
+	return snapshot
 }
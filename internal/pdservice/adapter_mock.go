@@ -0,0 +1,140 @@
+package pdservice
+
+import (
+	"fmt"
+
+	"github.com/PagerDuty/go-pagerduty"
+	"github.com/go-logr/logr"
+	"gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/observe"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_utils"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/typeinfo"
+)
+
+type PDServiceMockAdapter struct {
+	Logger logr.Logger
+}
+
+var Default_service_name = "default-service"
+var Default_service_description = "default_service_description"
+var Default_service_status = "active"
+var Default_service_alert_creation = "create_alerts_and_incidents"
+var Default_policy_name = "default-policy"
+var Default_policy_id = "MOCKPOLICYID"
+
+var services map[string]pagerduty.Service = make(map[string]pagerduty.Service)
+var integrations map[string]pagerduty.Integration = make(map[string]pagerduty.Integration)
+
+func (adapter *PDServiceMockAdapter) convert(pdService *v1alpha1.PagerdutyService) pagerduty.Service {
+	return pagerduty.Service{
+		APIObject: pagerduty.APIObject{
+			ID:   pdService.Status.ServiceID,
+			Type: pdservice_reference_type,
+		},
+		Name:                   pdService.Spec.Name,
+		Description:            pdService.Spec.Description,
+		AutoResolveTimeout:     pdService.Spec.AutoResolveTimeout,
+		AcknowledgementTimeout: pdService.Spec.AcknowledgementTimeout,
+		Status:                 pdService.Spec.Status,
+		AlertCreation:          pdService.Spec.AlertCreation,
+		EscalationPolicy:       typeinfo.EscalationPolicyID(pdService.Status.EscalationPolicyID).ToSpecificObject(),
+	}
+}
+
+func (adapter *PDServiceMockAdapter) CreatePDService(k8sPDService *v1alpha1.PagerdutyService) (string, error) {
+	service := adapter.convert(k8sPDService)
+	service.ID = k8sPDService.Spec.Name
+	services[service.ID] = service
+
+	adapter.Logger.Info("service created...")
+	return service.ID, nil
+}
+
+func (adapter *PDServiceMockAdapter) GetPDService(id string) (*pagerduty.Service, error) {
+	service, ok := services[id]
+	if !ok {
+		return nil, fmt.Errorf("service not found")
+	}
+
+	return &service, nil
+}
+
+func (adapter *PDServiceMockAdapter) UpdatePDService(k8sPDService *v1alpha1.PagerdutyService) error {
+	services[k8sPDService.Status.ServiceID] = adapter.convert(k8sPDService)
+	adapter.Logger.Info("Upstream service updated...")
+	return nil
+}
+
+func (adapter *PDServiceMockAdapter) DeletePDService(id string) error {
+	delete(services, id)
+
+	adapter.Logger.Info("service deleted...")
+	return nil
+}
+
+func (adapter *PDServiceMockAdapter) RetainPDService(id string) error {
+	service, ok := services[id]
+	if !ok {
+		return fmt.Errorf("service not found")
+	}
+
+	service.Name = pd_utils.RetainedName(service.Name)
+	services[id] = service
+
+	adapter.Logger.Info("service retained...")
+	return nil
+}
+
+func (adapter *PDServiceMockAdapter) UpstreamDrift(k8sPDService *v1alpha1.PagerdutyService) (observe.Diff, error) {
+	PDService, err := adapter.GetPDService(k8sPDService.Status.ServiceID)
+	if err != nil {
+		adapter.Logger.Error(err, "Failed to get service")
+		return nil, err
+	}
+
+	return serviceDiff(adapter.convert(k8sPDService), PDService), nil
+}
+
+func (adapter *PDServiceMockAdapter) CreateIntegration(serviceID string, integration v1alpha1.ServiceIntegration) (*pagerduty.Integration, error) {
+	created := convertIntegration(integration)
+	created.ID = serviceID + "-" + integration.Name
+	created.IntegrationKey = "key-" + created.ID
+	integrations[created.ID] = created
+
+	adapter.Logger.Info("service integration created...")
+	return &created, nil
+}
+
+func (adapter *PDServiceMockAdapter) GetIntegrationKey(serviceID string, integrationID string) (string, error) {
+	integration, ok := integrations[integrationID]
+	if !ok {
+		return "", fmt.Errorf("integration not found")
+	}
+
+	return integration.IntegrationKey, nil
+}
+
+func (adapter *PDServiceMockAdapter) DeleteIntegration(serviceID string, integrationID string) error {
+	delete(integrations, integrationID)
+
+	adapter.Logger.Info("service integration deleted...")
+	return nil
+}
+
+func (adapter *PDServiceMockAdapter) FindPDService(spec *v1alpha1.PagerdutyServiceSpec) (string, error) {
+	if spec.Adopt == nil {
+		return "", nil
+	}
+
+	if spec.Adopt.ID != "" {
+		if _, ok := services[spec.Adopt.ID]; !ok {
+			return "", fmt.Errorf("service not found")
+		}
+		return spec.Adopt.ID, nil
+	}
+
+	if _, ok := services[spec.Name]; spec.Adopt.ByName && ok {
+		return spec.Name, nil
+	}
+	return "", nil
+}
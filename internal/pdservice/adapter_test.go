@@ -2,8 +2,12 @@ package pdservice
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 
 	"github.com/PagerDuty/go-pagerduty"
+	"github.com/go-logr/logr"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
@@ -38,6 +42,7 @@ var _ = Describe("PagerDuty Service adapter tests", func() {
 	pd_client := pagerduty.NewClient("")
 
 	adapter := PDServiceAdapter{
+		Ctx:       context.TODO(),
 		PD_Client: pd_client,
 	}
 
@@ -135,3 +140,22 @@ var _ = Describe("PagerDuty Service adapter tests", func() {
 		})
 	})
 })
+
+var _ = Describe("PagerDuty Service adoption", func() {
+	It("should query the services by name and adopt the one named exactly like the spec", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.URL.Path).To(Equal("/services"))
+			Expect(r.URL.Query().Get("query")).To(Equal("adopted-service"))
+			fmt.Fprint(w, `{"services": [{"id": "POTHER", "name": "adopted-service-2"}, {"id": "PADOPTED", "name": "adopted-service"}], "more": false}`)
+		}))
+		defer server.Close()
+
+		adapter := &PDServiceAdapter{
+			Ctx:       context.TODO(),
+			Logger:    logr.Discard(),
+			PD_Client: pagerduty.NewClient("token", pagerduty.WithAPIEndpoint(server.URL)),
+		}
+		spec := &v1alpha1.PagerdutyServiceSpec{Name: "adopted-service", Adopt: &v1alpha1.AdoptSpec{ByName: true}}
+		Expect(adapter.FindPDService(spec)).To(Equal("PADOPTED"))
+	})
+})
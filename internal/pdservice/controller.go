@@ -2,26 +2,51 @@ package pdservice
 
 import (
 	"context"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
-	"github.com/PagerDuty/go-pagerduty"
 	pagerdutyalpha1 "gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
 	"gitlab.share-now.com/platform/pagerduty-operator/internal/condition"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/credentials"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/events"
 	k8s_utils "gitlab.share-now.com/platform/pagerduty-operator/internal/k8s_utils"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/observe"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/pause"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_errors"
 	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_utils"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/resync"
 )
 
+// EscalationPolicyRefField indexes PagerdutyServices by the name of the EscalationPolicy they reference
+const EscalationPolicyRefField = "spec.escalation_policy_ref"
+
 // EscalationPolicyReconciler reconciles a EscalationPolicy object
 type PagerdutyServiceReconciler struct {
 	client.Client
-	Scheme   *runtime.Scheme
-	Recorder record.EventRecorder
+	Scheme         *runtime.Scheme
+	Recorder       record.EventRecorder
+	ClientProvider credentials.ClientProvider
+	NewAdapter     AdapterFactory
+	// DefaultDeletionPolicy applies to the resources that do not set a deletion policy
+	DefaultDeletionPolicy pagerdutyalpha1.DeletionPolicy
+	// ResyncPeriod defines how often healthy resources are compared with upstream, 0 disables the resync
+	ResyncPeriod time.Duration
+	// DefaultMode applies to the resources without a mode annotation
+	DefaultMode observe.Mode
 }
 
 type Subroutines interface {
@@ -31,6 +56,12 @@ type Subroutines interface {
 	Initialization() (pd_utils.OperationResult, error)
 	AddFinalizer() (pd_utils.OperationResult, error)
 	EnsureEscalationPolicy() (pd_utils.OperationResult, error)
+	EnsureCredentials() (pd_utils.OperationResult, error)
+	ReconcileIntegrations() (pd_utils.OperationResult, error)
+	CheckPaused() (pd_utils.OperationResult, error)
+	CheckInvalidSpec() (pd_utils.OperationResult, error)
+	ObserveUpstream() (pd_utils.OperationResult, error)
+	MarkSynced() (pd_utils.OperationResult, error)
 }
 
 // The following markers are used to generate the rules permissions (RBAC) on config/rbac using controller-gen
@@ -40,6 +71,7 @@ type Subroutines interface {
 //+kubebuilder:rbac:groups=pagerduty.platform.share-now.com,resources=pagerdutyservices,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=pagerduty.platform.share-now.com,resources=pagerdutyservices/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=pagerduty.platform.share-now.com,resources=pagerdutyservices/finalizers,verbs=update
+//+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 //+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
@@ -75,22 +107,45 @@ func (r *PagerdutyServiceReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		return k8s_utils.RequeueWithError(err)
 	}
 
+	pdClient, credentialsErr := r.ClientProvider.Client(ctx, pdService.Spec.AccountRef)
+
+	resyncInterval, err := resync.Interval(pdService, r.ResyncPeriod)
+	if err != nil {
+		log.Error(err, "Ignoring resync interval annotation")
+	}
+
+	mode, err := observe.ModeOf(pdService, r.DefaultMode)
+	if err != nil {
+		log.Error(err, "Ignoring mode annotation")
+	}
+
+	paused := pause.IsPaused(pdService)
+	deletionPolicy := observe.DeletionPolicy(mode, pdService.Spec.DeletionPolicy.OrDefault(r.DefaultDeletionPolicy))
+	if paused || pd_errors.SpecRejected(pdService.Status.Conditions, pdService.Generation) {
+		// removing the annotation or changing the spec triggers a reconcile, there is nothing to resync until then
+		resyncInterval = 0
+	}
+
 	subroutineHandler := &SubroutineHandler{
-		Logger:    log.WithName("pdservice controller"),
-		K8sClient: r.Client,
-		PDServiceAdapter: &PDServiceAdapter{
-			Logger:    log,
-			PD_Client: pagerduty.NewClient(""),
-		},
+		Logger:           log.WithName("pdservice controller"),
+		K8sClient:        r.Client,
+		Adapter:          r.NewAdapter(ctx, log.WithName("PD Service Adapter"), pdClient),
 		PagerdutyService: pdService,
+		Events:           events.NewRecorder(r.Recorder, pdService, "Service"),
+		DeletionPolicy:   deletionPolicy,
+		Paused:           paused,
+		Observe:          mode == observe.ModeObserve,
+		ResyncInterval:   resyncInterval,
 		conditionManager: condition.NewConditionManager(),
+		credentialsErr:   credentialsErr,
 	}
 
 	result, err := r.ReconcileHandler(subroutineHandler)
 	// reason := "ReconcileError"
 	// _, _ = adapter.SetProjectClaimCondition(gcpv1alpha1.ConditionError, reason, err)
 
-	return result, err
+	// healthy resources are requeued, so changes made in PagerDuty are detected without waiting for a Kubernetes event
+	return resync.RequeueHealthy(result, err, resyncInterval)
 }
 
 type ReconcileOperation func() (pd_utils.OperationResult, error)
@@ -99,10 +154,16 @@ func (r *PagerdutyServiceReconciler) ReconcileHandler(subroutines Subroutines) (
 	operations := []ReconcileOperation{
 		subroutines.Initialization,
 		subroutines.AddFinalizer,
+		subroutines.EnsureCredentials,
 		subroutines.ReconcileDeletion,
+		subroutines.CheckPaused,
+		subroutines.CheckInvalidSpec,
 		subroutines.EnsureEscalationPolicy,
+		subroutines.ObserveUpstream,
 		subroutines.ReconcileCreation,
+		subroutines.ReconcileIntegrations,
 		subroutines.ReconcileUpdate,
+		subroutines.MarkSynced,
 	}
 	for _, operation := range operations {
 		result, err := operation()
@@ -117,8 +178,75 @@ func (r *PagerdutyServiceReconciler) ReconcileHandler(subroutines Subroutines) (
 }
 
 // SetupWithManager sets up the controller with the Manager.
+// Services are also reconciled whenever the EscalationPolicy they reference is created, deleted
+// or gets a new upstream ID, so they do not have to wait for the next requeue to become Ready.
+// Integration Secrets they own are recreated when deleted.
 func (r *PagerdutyServiceReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := IndexEscalationPolicyRef(mgr); err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&pagerdutyalpha1.PagerdutyService{}).
+		Owns(&corev1.Secret{}).
+		Watches(
+			&source.Kind{Type: &pagerdutyalpha1.EscalationPolicy{}},
+			handler.EnqueueRequestsFromMapFunc(r.servicesReferencingPolicy),
+			builder.WithPredicates(escalationPolicyChanged()),
+		).
 		Complete(r)
 }
+
+// IndexEscalationPolicyRef registers the EscalationPolicyRefField index, used by both the service
+// and the escalation policy reconcilers to find the services referencing a policy
+func IndexEscalationPolicyRef(mgr ctrl.Manager) error {
+	return mgr.GetFieldIndexer().IndexField(context.Background(), &pagerdutyalpha1.PagerdutyService{}, EscalationPolicyRefField, func(obj client.Object) []string {
+		service := obj.(*pagerdutyalpha1.PagerdutyService)
+		if service.Spec.EscalationPolicyName == "" {
+			return nil
+		}
+		return []string{service.Spec.EscalationPolicyName}
+	})
+}
+
+// servicesReferencingPolicy maps an EscalationPolicy to the PagerdutyServices of its namespace referencing it
+func (r *PagerdutyServiceReconciler) servicesReferencingPolicy(obj client.Object) []reconcile.Request {
+	services := &pagerdutyalpha1.PagerdutyServiceList{}
+	err := r.List(context.Background(), services,
+		client.InNamespace(obj.GetNamespace()),
+		client.MatchingFields{EscalationPolicyRefField: obj.GetName()},
+	)
+	if err != nil {
+		log.Log.Error(err, "Failed to list PagerDuty Services referencing escalation policy", "policy", obj.GetName())
+		return nil
+	}
+
+	requests := make([]reconcile.Request, len(services.Items))
+	for i, service := range services.Items {
+		requests[i] = reconcile.Request{NamespacedName: types.NamespacedName{
+			Name:      service.Name,
+			Namespace: service.Namespace,
+		}}
+	}
+	return requests
+}
+
+// escalationPolicyChanged filters out policy updates that cannot change the services referencing it
+func escalationPolicyChanged() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldPolicy, ok := e.ObjectOld.(*pagerdutyalpha1.EscalationPolicy)
+			if !ok {
+				return false
+			}
+			newPolicy, ok := e.ObjectNew.(*pagerdutyalpha1.EscalationPolicy)
+			if !ok {
+				return false
+			}
+
+			return oldPolicy.Status.PolicyID != newPolicy.Status.PolicyID ||
+				oldPolicy.Spec.AccountRef != newPolicy.Spec.AccountRef ||
+				oldPolicy.GetDeletionTimestamp().IsZero() != newPolicy.GetDeletionTimestamp().IsZero()
+		},
+	}
+}
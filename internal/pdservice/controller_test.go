@@ -0,0 +1,164 @@
+package pdservice
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	pagerdutyv1alpha1 "gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_utils"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/typeinfo"
+	core "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var timeout time.Duration = time.Second * 13
+var interval time.Duration = time.Millisecond * 250
+
+type TestServiceEnv struct {
+	ServiceNamespace string
+	Service          *pagerdutyv1alpha1.PagerdutyService
+}
+
+func setupTest() *TestServiceEnv {
+	serviceNamespace := "test-" + pd_utils.RandStr(5)
+
+	err := k8sClient.Create(ctx, &core.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: serviceNamespace},
+	})
+
+	Expect(err).NotTo(HaveOccurred(), "failed to create test namespace")
+
+	// no escalation policy controller runs in this suite, the upstream ID is set by hand
+	policy := &pagerdutyv1alpha1.EscalationPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      Default_policy_name,
+			Namespace: serviceNamespace,
+		},
+		Spec: pagerdutyv1alpha1.EscalationPolicySpec{
+			Name:     Default_policy_name,
+			NumLoops: 1,
+			EscalationRules: typeinfo.K8sEscalationRuleList{
+				{Delay: 5, Targets: []typeinfo.EscalationTarget{{UserID: "MOCKUSERID"}}},
+			},
+		},
+	}
+	Expect(k8sClient.Create(ctx, policy)).Should(Succeed())
+	policy.Status.PolicyID = Default_policy_id
+	Expect(k8sClient.Status().Update(ctx, policy)).Should(Succeed())
+
+	service := &pagerdutyv1alpha1.PagerdutyService{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "pagerduty.platform.share-now.com/v1alpha1",
+			Kind:       "PagerdutyService",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      Default_service_name,
+			Namespace: serviceNamespace,
+		},
+		Spec: pagerdutyv1alpha1.PagerdutyServiceSpec{
+			Name:                 Default_service_name,
+			Description:          Default_service_description,
+			Status:               Default_service_status,
+			AlertCreation:        Default_service_alert_creation,
+			EscalationPolicyName: Default_policy_name,
+		},
+	}
+
+	Expect(k8sClient.Create(ctx, service)).Should(Succeed())
+
+	return &TestServiceEnv{
+		ServiceNamespace: serviceNamespace,
+		Service:          service,
+	}
+}
+
+func cleanUp(testEnv *TestServiceEnv) {
+	err := k8sClient.Delete(ctx, &core.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: testEnv.ServiceNamespace},
+	})
+	Expect(err).NotTo(HaveOccurred(), "failed to delete test namespace")
+}
+
+var _ = Describe("PagerdutyService controller", func() {
+
+	var testEnv *TestServiceEnv
+
+	Context("When creating a Service", func() {
+		BeforeEach(func() {
+			testEnv = setupTest()
+		})
+
+		AfterEach(func() {
+			cleanUp(testEnv)
+			delete(services, Default_service_name)
+		})
+
+		It("Should create the service upstream with the ID of the escalation policy", func() {
+			Eventually(func() string {
+				err := k8sClient.Get(
+					context.Background(),
+					types.NamespacedName{Name: Default_service_name, Namespace: testEnv.ServiceNamespace},
+					testEnv.Service,
+				)
+				if err != nil {
+					return ""
+				}
+				return testEnv.Service.Status.ServiceID
+			}, timeout, interval).Should(Equal(Default_service_name))
+
+			Expect(services).Should(HaveKey(Default_service_name))
+			Expect(services[Default_service_name].EscalationPolicy.ID).Should(Equal(Default_policy_id))
+		})
+	})
+
+	Context("When deleting a Service with a deletion policy", func() {
+		BeforeEach(func() {
+			testEnv = setupTest()
+		})
+
+		AfterEach(func() {
+			cleanUp(testEnv)
+		})
+
+		deleteWithPolicy := func(deletionPolicy pagerdutyv1alpha1.DeletionPolicy) {
+			serviceKey := types.NamespacedName{Name: Default_service_name, Namespace: testEnv.ServiceNamespace}
+			Eventually(func() string {
+				if err := k8sClient.Get(ctx, serviceKey, testEnv.Service); err != nil {
+					return ""
+				}
+				return testEnv.Service.Status.ServiceID
+			}, timeout, interval).Should(Equal(Default_service_name))
+
+			testEnv.Service.Spec.DeletionPolicy = deletionPolicy
+			Expect(k8sClient.Update(ctx, testEnv.Service)).Should(Succeed())
+			Expect(k8sClient.Delete(ctx, testEnv.Service)).Should(Succeed())
+
+			Eventually(func() bool {
+				return apierrors.IsNotFound(k8sClient.Get(ctx, serviceKey, testEnv.Service))
+			}, timeout, interval).Should(BeTrue())
+		}
+
+		It("Should delete the upstream service with the Delete policy", func() {
+			deleteWithPolicy(pagerdutyv1alpha1.DeletionPolicyDelete)
+			Expect(services).ShouldNot(HaveKey(Default_service_name))
+		})
+
+		It("Should leave the upstream service untouched with the Orphan policy", func() {
+			deleteWithPolicy(pagerdutyv1alpha1.DeletionPolicyOrphan)
+			Expect(services).Should(HaveKey(Default_service_name))
+			Expect(services[Default_service_name].Name).Should(Equal(Default_service_name))
+			delete(services, Default_service_name)
+		})
+
+		It("Should rename the upstream service with the Retain policy", func() {
+			deleteWithPolicy(pagerdutyv1alpha1.DeletionPolicyRetain)
+			Expect(services).Should(HaveKey(Default_service_name))
+			Expect(services[Default_service_name].Name).Should(Equal(Default_service_name + pd_utils.RetainedNameSuffix))
+			delete(services, Default_service_name)
+		})
+	})
+})
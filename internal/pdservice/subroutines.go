@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -16,7 +17,9 @@ import (
 	"gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
 	pdv1alpha1 "gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
 	"gitlab.share-now.com/platform/pagerduty-operator/internal/condition"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/events"
 	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_errors"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_metrics"
 	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_utils"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -30,26 +33,48 @@ type SubroutineHandler struct {
 	PagerdutyService *v1alpha1.PagerdutyService
 	Logger           logr.Logger
 	K8sClient        client.Client
-	PDServiceAdapter *PDServiceAdapter
+	Adapter          Adapter
+	Events           *events.Recorder
+	DeletionPolicy   v1alpha1.DeletionPolicy
+	Paused           bool
+	Observe          bool
+	ResyncInterval   time.Duration
 	conditionManager condition.Conditions
+	credentialsErr   error
 }
 
 func (e *SubroutineHandler) ReconcileCreation() (pd_utils.OperationResult, error) {
 	e.Logger.Info("Reconcile PagerDuty Service Creation...")
 
 	if !e.serviceIDExists() && e.escalationPolicyFound() {
+		adoptedID, err := e.Adapter.FindPDService(&e.PagerdutyService.Spec)
+		if err != nil {
+			e.Logger.Error(err, "Failed to find the PagerDuty Service to adopt")
+			e.Events.Failed("Adopt", err)
+			return e.SetPagerDutyServiceCondition(pdv1alpha1.ConditionReady, pdServiceReady, err, err.Error())
+		}
+
+		if adoptedID != "" {
+			e.Logger.Info("Adopting upstream PagerDuty Service...", "serviceID", adoptedID)
+			e.PagerdutyService.Status.ServiceID = adoptedID
+			e.Events.Adopted(adoptedID)
+			return e.SetPagerDutyServiceCondition(pdv1alpha1.ConditionReady, pdServiceReady, nil, "PagerDuty Service adopted")
+		}
+
 		e.Logger.Info("Upstream PagerDuty Service not found. Creating...")
 
 		// Handles creation of upstream policy
-		serviceID, err := e.PDServiceAdapter.CreatePDService(e.PagerdutyService)
+		serviceID, err := e.Adapter.CreatePDService(e.PagerdutyService)
 		if err != nil {
 			e.Logger.Error(err, "Failed to create PagerDuty Service")
+			e.Events.Failed("Create", err)
 			return e.SetPagerDutyServiceCondition(pdv1alpha1.ConditionReady, pdServiceReady, err, err.Error())
 		}
 
 		e.Logger.Info("Updating PagerDuty Service status...")
 		// TODO: Update the rest of the policy status/spec
 		e.PagerdutyService.Status.ServiceID = serviceID
+		e.Events.Created(serviceID)
 		return e.SetPagerDutyServiceCondition(pdv1alpha1.ConditionReady, pdServiceReady, nil, "PagerDuty Service created")
 	}
 
@@ -63,10 +88,20 @@ func (e *SubroutineHandler) ReconcileDeletion() (pd_utils.OperationResult, error
 	if e.deletionTimestampExists() {
 		e.Logger.Info("Deletion timestamp found. Deleting...")
 
+		// a paused upstream object is only released untouched, any other policy waits for the pause to end
+		if e.Paused && e.DeletionPolicy != pdv1alpha1.DeletionPolicyOrphan && e.serviceIDExists() {
+			return e.status().BlockPausedDeletion(e.DeletionPolicy)
+		}
+
 		if e.serviceIDExists() {
-			e.Logger.Info("Upstream PagerDuty Service found, making API deletion call...")
-			if err := e.PDServiceAdapter.DeletePDService(e.PagerdutyService.Status.ServiceID); err != nil {
+			err := e.deleteUpstream()
+			if pd_errors.IsNotFound(err) {
+				e.Logger.Info("Upstream PagerDuty Service already deleted...", "serviceID", e.PagerdutyService.Status.ServiceID)
+				err = nil
+			}
+			if err != nil {
 				e.Logger.Error(err, "Failed to delete PagerDuty Service")
+				e.Events.Failed("Delete", err)
 				return e.SetPagerDutyServiceCondition(pdv1alpha1.ConditionReady, pdServiceReady, err, err.Error())
 			}
 		}
@@ -79,6 +114,32 @@ func (e *SubroutineHandler) ReconcileDeletion() (pd_utils.OperationResult, error
 	return pd_utils.ContinueProcessing()
 }
 
+// deleteUpstream applies the deletion policy to the upstream PagerDuty Service
+func (e *SubroutineHandler) deleteUpstream() error {
+	serviceID := e.PagerdutyService.Status.ServiceID
+
+	switch e.DeletionPolicy {
+	case pdv1alpha1.DeletionPolicyOrphan:
+		e.Logger.Info("Deletion policy is Orphan, leaving upstream PagerDuty Service untouched...", "serviceID", serviceID)
+		e.Events.Orphaned(serviceID)
+		return nil
+	case pdv1alpha1.DeletionPolicyRetain:
+		e.Logger.Info("Deletion policy is Retain, renaming upstream PagerDuty Service...", "serviceID", serviceID)
+		if err := e.Adapter.RetainPDService(serviceID); err != nil {
+			return err
+		}
+		e.Events.Retained(serviceID)
+		return nil
+	default:
+		e.Logger.Info("Upstream PagerDuty Service found, making API deletion call...")
+		if err := e.Adapter.DeletePDService(serviceID); err != nil {
+			return err
+		}
+		e.Events.Deleted(serviceID)
+		return nil
+	}
+}
+
 func (e *SubroutineHandler) ReconcileUpdate() (pd_utils.OperationResult, error) {
 	e.Logger.Info("Reconcile PagerDuty Service Update...")
 
@@ -87,22 +148,33 @@ func (e *SubroutineHandler) ReconcileUpdate() (pd_utils.OperationResult, error)
 		return pd_utils.ContinueProcessing()
 	}
 
-	equal, err := e.PDServiceAdapter.EqualToUpstream(e.PagerdutyService)
+	drifted, err := e.Adapter.UpstreamDrift(e.PagerdutyService)
+	if pd_errors.IsNotFound(err) {
+		return e.ForgetUpstream()
+	}
 	if err != nil {
 		e.Logger.Error(err, "Failed to compare PagerDuty Service spec with upstream service")
+		e.Events.Failed("Compare", err)
 		return e.SetPagerDutyServiceCondition(pdv1alpha1.ConditionReady, pdServiceReady, err, err.Error())
 	}
 
-	if !equal {
-		e.Logger.Info("PagerDuty Service spec does not match upstream service. Updating...")
-		err := e.PDServiceAdapter.UpdatePDService(e.PagerdutyService)
-
+	if len(drifted) > 0 {
+		e.Logger.Info("PagerDuty Service spec does not match upstream service. Updating...", "driftedFields", drifted.Fields())
+		err := e.Adapter.UpdatePDService(e.PagerdutyService)
+		if pd_errors.IsNotFound(err) {
+			return e.ForgetUpstream()
+		}
 		if err != nil {
 			e.Logger.Error(err, "Failed to update PagerDuty Service")
+			e.Events.Failed("Update", err)
 			return e.SetPagerDutyServiceCondition(pdv1alpha1.ConditionReady, pdServiceReady, err, err.Error())
 		}
 
 		e.Logger.Info("PagerDuty Service changed...")
+		e.Events.Updated(e.PagerdutyService.Status.ServiceID, drifted.Fields())
+		pd_metrics.DriftCorrected("PagerdutyService", e.PagerdutyService.Namespace)
+		// read again by MarkSynced
+		e.PagerdutyService.Status.Upstream = nil
 		return e.SetPagerDutyServiceCondition(pdv1alpha1.ConditionReady, pdServiceReady, nil, "PagerDuty Service matches upstream service")
 	}
 
@@ -110,6 +182,231 @@ func (e *SubroutineHandler) ReconcileUpdate() (pd_utils.OperationResult, error)
 	return pd_utils.ContinueProcessing()
 }
 
+// ReconcileIntegrations creates the integrations of the spec upstream and writes their keys into Secrets.
+// Integrations removed from the spec are deleted upstream together with their Secret, and integrations
+// whose type changed are replaced.
+func (e *SubroutineHandler) ReconcileIntegrations() (pd_utils.OperationResult, error) {
+	e.Logger.Info("Reconcile PagerDuty Service integrations...")
+
+	if !e.serviceIDExists() {
+		e.Logger.Info("No upstream pagerduty service created yet. Skipping integrations...")
+		return pd_utils.ContinueProcessing()
+	}
+
+	serviceID := e.PagerdutyService.Status.ServiceID
+	changed := false
+
+	observed := make(map[string]pdv1alpha1.IntegrationStatus, len(e.PagerdutyService.Status.Integrations))
+	for _, integration := range e.PagerdutyService.Status.Integrations {
+		observed[integration.Name] = integration
+	}
+
+	var integrations []pdv1alpha1.IntegrationStatus
+	for _, integration := range e.PagerdutyService.Spec.Integrations {
+		vendor := integration.Vendor()
+		current, found := observed[integration.Name]
+		delete(observed, integration.Name)
+
+		if found && current.Vendor != vendor {
+			e.Logger.Info("Integration type changed, replacing it...", "integration", integration.Name)
+			if err := e.removeIntegration(current); err != nil {
+				return e.SetPagerDutyServiceCondition(pdv1alpha1.ConditionReady, pdServiceReady, err, err.Error())
+			}
+			found = false
+		}
+
+		integrationKey := ""
+		if !found {
+			created, err := e.Adapter.CreateIntegration(serviceID, integration)
+			if err != nil {
+				e.Logger.Error(err, "Failed to create PagerDuty Service integration", "integration", integration.Name)
+				e.Events.Warning("IntegrationFailed", "Failed to create integration %s of PagerDuty Service %s: %s", integration.Name, serviceID, events.DescribeError(err))
+				return e.SetPagerDutyServiceCondition(pdv1alpha1.ConditionReady, pdServiceReady, err, err.Error())
+			}
+
+			current = pdv1alpha1.IntegrationStatus{Name: integration.Name, ID: created.ID, Vendor: vendor}
+			integrationKey = created.IntegrationKey
+			changed = true
+			e.Events.Normal("IntegrationCreated", "Created integration %s %s of PagerDuty Service %s", integration.Name, created.ID, serviceID)
+
+			// recorded right away, so the integration is not leaked if anything below fails
+			if err := e.recordIntegration(current); err != nil {
+				return pd_utils.RequeueAfter(RequeWaitTime, err)
+			}
+		}
+
+		if current.SecretName != integration.SecretName {
+			if current.SecretName != "" {
+				if err := e.deleteIntegrationSecret(current.SecretName); err != nil {
+					return e.SetPagerDutyServiceCondition(pdv1alpha1.ConditionReady, pdServiceReady, err, err.Error())
+				}
+			}
+			current.SecretName = integration.SecretName
+			changed = true
+		}
+
+		if err := e.writeIntegrationSecret(integration, current.ID, integrationKey); err != nil {
+			e.Logger.Error(err, "Failed to write integration key", "integration", integration.Name, "secret", integration.SecretName)
+			return e.SetPagerDutyServiceCondition(pdv1alpha1.ConditionReady, pdServiceReady, err, err.Error())
+		}
+
+		integrations = append(integrations, current)
+	}
+
+	for _, removed := range observed {
+		e.Logger.Info("Removing integration no longer in spec...", "integration", removed.Name)
+		if err := e.removeIntegration(removed); err != nil {
+			return e.SetPagerDutyServiceCondition(pdv1alpha1.ConditionReady, pdServiceReady, err, err.Error())
+		}
+		changed = true
+	}
+
+	if !changed {
+		e.Logger.Info("PagerDuty Service integrations not changed...")
+		return pd_utils.ContinueProcessing()
+	}
+
+	e.PagerdutyService.Status.Integrations = integrations
+	return e.SetPagerDutyServiceCondition(pdv1alpha1.ConditionReady, pdServiceReady, nil, "PagerDuty Service integrations updated")
+}
+
+// recordIntegration adds the integration to the status, or replaces the entry with the same name
+func (e *SubroutineHandler) recordIntegration(integration pdv1alpha1.IntegrationStatus) error {
+	integrations := e.PagerdutyService.Status.Integrations
+	for i, existing := range integrations {
+		if existing.Name == integration.Name {
+			integrations[i] = integration
+			return e.StatusUpdate()
+		}
+	}
+
+	e.PagerdutyService.Status.Integrations = append(integrations, integration)
+	return e.StatusUpdate()
+}
+
+// removeIntegration deletes the integration upstream and its Secret, and drops it from the status
+func (e *SubroutineHandler) removeIntegration(integration pdv1alpha1.IntegrationStatus) error {
+	err := e.Adapter.DeleteIntegration(e.PagerdutyService.Status.ServiceID, integration.ID)
+	if err != nil && !pd_errors.IsNotFound(err) {
+		e.Logger.Error(err, "Failed to delete PagerDuty Service integration", "integration", integration.Name)
+		e.Events.Warning("IntegrationFailed", "Failed to delete integration %s of PagerDuty Service %s: %s", integration.Name, e.PagerdutyService.Status.ServiceID, events.DescribeError(err))
+		return err
+	}
+	e.Events.Normal("IntegrationDeleted", "Deleted integration %s %s of PagerDuty Service %s", integration.Name, integration.ID, e.PagerdutyService.Status.ServiceID)
+
+	if err := e.deleteIntegrationSecret(integration.SecretName); err != nil {
+		return err
+	}
+
+	var remaining []pdv1alpha1.IntegrationStatus
+	for _, existing := range e.PagerdutyService.Status.Integrations {
+		if existing.ID != integration.ID {
+			remaining = append(remaining, existing)
+		}
+	}
+	e.PagerdutyService.Status.Integrations = remaining
+	return e.StatusUpdate()
+}
+
+// writeIntegrationSecret stores the integration key in the Secret of the integration. The key is only
+// fetched from upstream when it is not given and the Secret does not hold it yet.
+func (e *SubroutineHandler) writeIntegrationSecret(integration pdv1alpha1.ServiceIntegration, integrationID string, integrationKey string) error {
+	secretKey := integration.SecretKey
+	if secretKey == "" {
+		secretKey = pdv1alpha1.DefaultIntegrationSecretKey
+	}
+
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+		Name:      integration.SecretName,
+		Namespace: e.PagerdutyService.Namespace,
+	}}
+
+	err := e.K8sClient.Get(context.TODO(), client.ObjectKeyFromObject(secret), secret)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	if err == nil && integrationKey == "" && len(secret.Data[secretKey]) > 0 && metav1.IsControlledBy(secret, e.PagerdutyService) {
+		return nil
+	}
+
+	if integrationKey == "" {
+		integrationKey, err = e.Adapter.GetIntegrationKey(e.PagerdutyService.Status.ServiceID, integrationID)
+		if err != nil {
+			return err
+		}
+	}
+
+	e.Logger.Info("Writing integration key...", "integration", integration.Name, "secret", integration.SecretName)
+	_, err = controllerutil.CreateOrUpdate(context.TODO(), e.K8sClient, secret, func() error {
+		if secret.Data == nil {
+			secret.Data = map[string][]byte{}
+		}
+		secret.Data[secretKey] = []byte(integrationKey)
+		return controllerutil.SetControllerReference(e.PagerdutyService, secret, e.K8sClient.Scheme())
+	})
+	return err
+}
+
+// deleteIntegrationSecret deletes an integration Secret, unless it is not owned by the service
+func (e *SubroutineHandler) deleteIntegrationSecret(name string) error {
+	secret := &corev1.Secret{}
+	err := e.K8sClient.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: e.PagerdutyService.Namespace}, secret)
+	if err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	if !metav1.IsControlledBy(secret, e.PagerdutyService) {
+		e.Logger.Info("Integration Secret not owned by the PagerDuty Service, keeping it...", "secret", name)
+		return nil
+	}
+
+	return client.IgnoreNotFound(e.K8sClient.Delete(context.TODO(), secret))
+}
+
+// CheckPaused ends the reconcile of a paused PagerDuty Service before any PagerDuty API call
+func (e *SubroutineHandler) CheckPaused() (pd_utils.OperationResult, error) {
+	return e.status().CheckPaused(e.Paused)
+}
+
+// ObserveUpstream compares the PagerDuty Service with upstream in observe mode, without changing PagerDuty.
+// The drift is reported in the DriftDetected condition, and the reconcile ends there.
+func (e *SubroutineHandler) ObserveUpstream() (pd_utils.OperationResult, error) {
+	if !e.Observe {
+		return pd_utils.ContinueProcessing()
+	}
+	e.Logger.Info("Observe mode, comparing PagerDuty Service with upstream...")
+
+	if !e.serviceIDExists() {
+		adoptedID, err := e.Adapter.FindPDService(&e.PagerdutyService.Spec)
+		if err != nil {
+			e.Logger.Error(err, "Failed to find the PagerDuty Service to observe")
+			e.Events.Failed("Adopt", err)
+			return e.SetPagerDutyServiceCondition(v1alpha1.ConditionReady, pdServiceReady, err, err.Error())
+		}
+		if adoptedID == "" {
+			return e.status().SetDrift(false, nil)
+		}
+
+		e.Logger.Info("Observing upstream PagerDuty Service...", "id", adoptedID)
+		e.PagerdutyService.Status.ServiceID = adoptedID
+		e.Events.Adopted(adoptedID)
+	}
+
+	drifted, err := e.Adapter.UpstreamDrift(e.PagerdutyService)
+	if pd_errors.IsNotFound(err) {
+		e.Logger.Info("Observed upstream PagerDuty Service not found...", "serviceID", e.PagerdutyService.Status.ServiceID)
+		e.PagerdutyService.Status.ServiceID = ""
+		return e.status().SetDrift(false, nil)
+	}
+	if err != nil {
+		e.Logger.Error(err, "Failed to compare PagerDuty Service spec with upstream")
+		e.Events.Failed("Compare", err)
+		return e.SetPagerDutyServiceCondition(v1alpha1.ConditionReady, pdServiceReady, err, err.Error())
+	}
+
+	return e.status().SetDrift(true, drifted)
+}
+
 func (e *SubroutineHandler) serviceIDExists() bool {
 	return e.PagerdutyService.Status.ServiceID != ""
 }
@@ -149,6 +446,19 @@ func (e *SubroutineHandler) removeFinalizer() error {
 	return e.K8sClient.Update(context.TODO(), e.PagerdutyService)
 }
 
+// status returns the condition subroutines shared with the other reconcilers, applied to the PagerDuty Service
+func (e *SubroutineHandler) status() *condition.Status {
+	return &condition.Status{
+		Kind:         "PagerDuty Service",
+		Object:       e.PagerdutyService,
+		Conditions:   &e.PagerdutyService.Status.Conditions,
+		Logger:       e.Logger,
+		Events:       e.Events,
+		Update:       e.StatusUpdate,
+		RequeueDelay: RequeWaitTime,
+	}
+}
+
 // StatusUpdate updates the project claim status
 func (e *SubroutineHandler) StatusUpdate() error {
 	e.Logger.Info("Updating PagerDuty Service status...")
@@ -167,20 +477,39 @@ func (e *SubroutineHandler) SetPagerDutyServiceCondition(conditionType pdv1alpha
 	// defer e.StatusUpdate()
 
 	if err != nil {
+		switch class := pd_errors.Classify(err); class {
+		case pd_errors.Unauthorized:
+			return e.status().SetCredentials(err)
+		case pd_errors.Invalid:
+			// the upstream service still has to be released on deletion, whatever the spec
+			if !e.deletionTimestampExists() {
+				return e.status().SetInvalidSpec(err)
+			}
+		case pd_errors.NotFound, pd_errors.Forbidden, pd_errors.RateLimited:
+			reason = string(class)
+		}
+
+		delay := RequeWaitTime
+		if backoff, ok := pd_errors.Backoff(err); ok {
+			delay = backoff
+		}
+
 		e.Logger.Info("Setting PagerDuty Service's ready condition to false", "conditionType", conditionType, "status", metav1.ConditionFalse, "reason", reason, "message", message, "error", err.Error())
 		e.conditionManager.SetCondition(conditions, conditionType, metav1.ConditionFalse, reason, message)
+		e.status().SetError(reason, err)
 
 		err := e.StatusUpdate()
 		if err != nil {
 			e.Logger.Error(err, "Failed to update PagerDuty Service to false ready condition ")
 		}
 
-		return pd_utils.RequeueAfter(RequeWaitTime, err)
+		return pd_utils.RequeueAfter(delay, err)
 	}
 
 	// Same condition as before, stop processing
 	// TODO: Can probably simplify this if by only setting condition if on of these conditions is false...
-	if len(*conditions) > 0 && (*conditions)[0].Status == metav1.ConditionTrue && (*conditions)[0].Message == message {
+	if current := e.conditionManager.GetCondition(conditions, conditionType); current != nil && current.Status == metav1.ConditionTrue && current.Message == message {
+		e.status().ClearError()
 		err = e.StatusUpdate()
 		if err != nil {
 			e.Logger.Error(err, "Failed to update PagerDuty Service to true ready condition ")
@@ -191,11 +520,31 @@ func (e *SubroutineHandler) SetPagerDutyServiceCondition(conditionType pdv1alpha
 
 	e.Logger.Info("Setting PagerDuty Service's ready condition to true", "conditionType", conditionType, "status", metav1.ConditionFalse, "reason", reason, "message", message)
 	e.conditionManager.SetCondition(conditions, conditionType, metav1.ConditionTrue, reason, message)
+	e.status().ClearError()
 	err = e.StatusUpdate()
 
 	return pd_utils.RequeueOnErrorOrStop(err)
 }
 
+// CheckInvalidSpec ends the reconcile of a PagerDuty Service whose spec PagerDuty rejected, before any PagerDuty API call
+func (e *SubroutineHandler) CheckInvalidSpec() (pd_utils.OperationResult, error) {
+	return e.status().CheckInvalidSpec()
+}
+
+// ForgetUpstream drops the upstream PagerDuty Service deleted outside the operator from the status,
+// so the next reconcile adopts or creates it again
+func (e *SubroutineHandler) ForgetUpstream() (pd_utils.OperationResult, error) {
+	status := &e.PagerdutyService.Status
+
+	return e.status().ForgetUpstream(status.ServiceID, func() {
+		status.ServiceID = ""
+		status.HTMLURL = ""
+		status.Upstream = nil
+		// the integrations were deleted with the service, their Secrets are overwritten with the new keys
+		status.Integrations = nil
+	})
+}
+
 func (e *SubroutineHandler) Initialization() (pd_utils.OperationResult, error) {
 	e.Logger.Info("Starting Initialization...")
 	if e.PagerdutyService.Status.Conditions == nil {
@@ -242,6 +591,13 @@ func (e *SubroutineHandler) EnsureEscalationPolicy() (pd_utils.OperationResult,
 		return e.SetPagerDutyServiceCondition(pdv1alpha1.ConditionReady, pdServiceReady, err, err.Error())
 	}
 
+	if policy.Spec.AccountRef != e.PagerdutyService.Spec.AccountRef {
+		err := fmt.Errorf("escalation policy %s uses account %q but the PagerDuty Service uses account %q",
+			policy.Name, policy.Spec.AccountRef, e.PagerdutyService.Spec.AccountRef)
+		e.Logger.Error(err, "Escalation policy belongs to a different PagerDuty account")
+		return e.SetPagerDutyServiceCondition(pdv1alpha1.ConditionReady, pdServiceReady, err, err.Error())
+	}
+
 	if e.PagerdutyService.Status.EscalationPolicyID == policy.Status.PolicyID {
 		e.Logger.Info("No changes to escalation policy ID...")
 		return pd_utils.ContinueProcessing()
@@ -263,3 +619,36 @@ func (e *SubroutineHandler) EnsureEscalationPolicy() (pd_utils.OperationResult,
 func (e *SubroutineHandler) escalationPolicyFound() bool {
 	return e.PagerdutyService.Status.EscalationPolicyID != ""
 }
+
+func (e *SubroutineHandler) EnsureCredentials() (pd_utils.OperationResult, error) {
+	e.Logger.Info("Ensuring PagerDuty credentials...")
+	return e.status().SetCredentials(e.credentialsErr)
+}
+
+// MarkSynced records the successful comparison with upstream of the PagerDuty Service
+func (e *SubroutineHandler) MarkSynced() (pd_utils.OperationResult, error) {
+	if !e.serviceIDExists() {
+		return pd_utils.ContinueProcessing()
+	}
+
+	status := &e.PagerdutyService.Status
+
+	if e.status().SyncDue(status.Upstream != nil, status.ObservedGeneration, status.LastSyncedTime, e.ResyncInterval) {
+		e.Logger.Info("Recording PagerDuty Service sync with upstream...")
+		PDService, err := e.Adapter.GetPDService(status.ServiceID)
+		if pd_errors.IsNotFound(err) {
+			return e.ForgetUpstream()
+		}
+		if err != nil {
+			e.Logger.Error(err, "Failed to read upstream PagerDuty Service")
+			e.Events.Failed("Get", err)
+			return e.SetPagerDutyServiceCondition(pdv1alpha1.ConditionReady, pdServiceReady, err, err.Error())
+		}
+
+		status.HTMLURL = PDService.HTMLURL
+		status.Upstream = serviceSnapshot(PDService)
+		return e.status().MarkSynced(&status.ObservedGeneration, &status.LastSyncedTime)
+	}
+
+	return pd_utils.ContinueProcessing()
+}
@@ -0,0 +1,35 @@
+package ratelimit
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// reasonRateLimit marks requests delayed by the operator's own token bucket
+	reasonRateLimit = "rate_limit"
+	// reasonQuota marks requests delayed because PagerDuty reported the quota of the account as used up
+	reasonQuota = "quota"
+)
+
+var (
+	throttledRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "pagerduty_api_throttled_requests_total",
+		Help: "Number of PagerDuty API requests delayed before being sent, by account and reason (rate_limit or quota).",
+	}, []string{"account", "reason"})
+
+	throttleWaitSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "pagerduty_api_throttle_wait_seconds",
+		Help:    "Time PagerDuty API requests were delayed before being sent, by account.",
+		Buckets: []float64{0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30, 60},
+	}, []string{"account"})
+
+	retries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "pagerduty_api_retries_total",
+		Help: "Number of PagerDuty API requests sent again, by account and HTTP status of the failed attempt.",
+	}, []string{"account", "code"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(throttledRequests, throttleWaitSeconds, retries)
+}
@@ -0,0 +1,284 @@
+package ratelimit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/PagerDuty/go-pagerduty"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// DefaultBaseDelay is the delay before the first retry, doubled on every further retry
+	DefaultBaseDelay = 500 * time.Millisecond
+	// DefaultMaxDelay caps the delay between two retries. Longer waits asked by PagerDuty are left to the reconcile requeue.
+	DefaultMaxDelay = 30 * time.Second
+)
+
+var errNotRewindable = errors.New("request body cannot be sent again")
+
+// Options configures the rate limit and the retries of the PagerDuty API calls of an account.
+// The zero value neither limits nor retries the calls.
+type Options struct {
+	// QPS is the sustained number of requests per second, 0 disables the limit
+	QPS float64
+	// Burst is the number of requests sent at once before QPS applies
+	Burst int
+	// MaxRetries is how often a rate limited or failed request is sent again
+	MaxRetries int
+	// BaseDelay is the delay before the first retry. DefaultBaseDelay when 0.
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between two retries and the wait before a request. DefaultMaxDelay when 0.
+	MaxDelay time.Duration
+}
+
+// Limiter throttles the requests to the PagerDuty API of an account. It is shared by all the clients
+// of the account, so every adapter draws from the same token bucket. Rate limit headers returned by
+// PagerDuty pause all the requests of the account until the limit resets.
+type Limiter struct {
+	account string
+	opts    Options
+	bucket  *rate.Limiter
+
+	mu           sync.Mutex
+	blockedUntil time.Time
+}
+
+// NewLimiter returns a Limiter for the given account, which only labels the metrics
+func NewLimiter(account string, opts Options) *Limiter {
+	if opts.BaseDelay == 0 {
+		opts.BaseDelay = DefaultBaseDelay
+	}
+	if opts.MaxDelay == 0 {
+		opts.MaxDelay = DefaultMaxDelay
+	}
+
+	limit, burst := rate.Inf, opts.Burst
+	if opts.QPS > 0 {
+		limit = rate.Limit(opts.QPS)
+	}
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &Limiter{
+		account: account,
+		opts:    opts,
+		bucket:  rate.NewLimiter(limit, burst),
+	}
+}
+
+// Wrap returns an HTTP client sending the requests of next through the limiter
+func (l *Limiter) Wrap(next pagerduty.HTTPClient) pagerduty.HTTPClient {
+	return &retryingClient{next: next, limiter: l}
+}
+
+// wait blocks until the token bucket and the rate limit headers allow another request.
+// A wait longer than MaxDelay is not slept but returned as a blockedError, so the reconcile worker is freed.
+func (l *Limiter) wait(ctx context.Context) error {
+	reservation := l.bucket.Reserve()
+	delay, reason := reservation.Delay(), reasonRateLimit
+
+	l.mu.Lock()
+	if blocked := time.Until(l.blockedUntil); blocked > delay {
+		delay, reason = blocked, reasonQuota
+	}
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	throttledRequests.WithLabelValues(l.account, reason).Inc()
+	if delay > l.opts.MaxDelay {
+		reservation.Cancel()
+		return &blockedError{delay: delay}
+	}
+
+	throttleWaitSeconds.WithLabelValues(l.account).Observe(delay.Seconds())
+	if err := sleep(ctx, delay); err != nil {
+		reservation.Cancel()
+		return err
+	}
+	return nil
+}
+
+// blockedError reports a request that would have to wait longer than MaxDelay before being sent
+type blockedError struct {
+	delay time.Duration
+}
+
+func (e *blockedError) Error() string {
+	return fmt.Sprintf("PagerDuty API rate limited for another %s", e.delay.Round(time.Second))
+}
+
+// response answers the request with a 429 without sending it. The PagerDuty client turns it into a rate limited
+// APIError, which the controllers requeue with their rate limit backoff; an error returned by the HTTP client
+// would lose its type.
+func (e *blockedError) response(req *http.Request) *http.Response {
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	header.Set("Retry-After", strconv.Itoa(int(math.Ceil(e.delay.Seconds()))))
+
+	body, _ := json.Marshal(map[string]any{"error": map[string]string{"message": e.Error()}})
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", http.StatusTooManyRequests, http.StatusText(http.StatusTooManyRequests)),
+		StatusCode:    http.StatusTooManyRequests,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// observe pauses the requests of the account when PagerDuty reports that its quota is used up
+func (l *Limiter) observe(resp *http.Response) {
+	if resp == nil || resp.Header.Get("ratelimit-remaining") != "0" {
+		return
+	}
+
+	reset, ok := parseSeconds(resp.Header.Get("ratelimit-reset"))
+	if !ok {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if until := time.Now().Add(reset); until.After(l.blockedUntil) {
+		l.blockedUntil = until
+	}
+}
+
+// backoff returns the delay before the given retry: an exponential backoff with jitter,
+// unless PagerDuty asked for a longer one with Retry-After or ratelimit-reset
+func (l *Limiter) backoff(retry int, resp *http.Response) time.Duration {
+	delay := l.opts.BaseDelay << retry
+	if delay > l.opts.MaxDelay || delay <= 0 {
+		delay = l.opts.MaxDelay
+	}
+	// full jitter on the upper half, so retries of parallel reconciles do not hit PagerDuty together
+	delay = delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+
+	if requested, ok := retryAfter(resp); ok && requested > delay {
+		delay = requested
+	}
+	return delay
+}
+
+// retryingClient sends a request again when PagerDuty rate limits it or fails to process it
+type retryingClient struct {
+	next    pagerduty.HTTPClient
+	limiter *Limiter
+}
+
+func (c *retryingClient) Do(req *http.Request) (*http.Response, error) {
+	for retry := 0; ; retry++ {
+		if err := c.limiter.wait(req.Context()); err != nil {
+			var blocked *blockedError
+			if errors.As(err, &blocked) {
+				return blocked.response(req), nil
+			}
+			return nil, err
+		}
+
+		resp, err := c.next.Do(req)
+		c.limiter.observe(resp)
+		if err != nil || retry >= c.limiter.opts.MaxRetries || !retryable(req, resp) {
+			return resp, err
+		}
+
+		delay := c.limiter.backoff(retry, resp)
+		if delay > c.limiter.opts.MaxDelay {
+			// waiting that long would block the reconcile worker, the caller requeues instead
+			return resp, nil
+		}
+
+		body, err := rewind(req)
+		if err != nil {
+			return resp, nil
+		}
+
+		retries.WithLabelValues(c.limiter.account, strconv.Itoa(resp.StatusCode)).Inc()
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		if err := sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+		req.Body = body
+	}
+}
+
+// retryable tells whether the response is worth a retry. A rate limited request was not processed,
+// while a server error may have been: only idempotent requests are retried then, so a POST
+// does not create the same object twice.
+func retryable(req *http.Request, resp *http.Response) bool {
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return true
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return req.Method != http.MethodPost && req.Method != http.MethodPatch
+	default:
+		return false
+	}
+}
+
+// rewind returns a fresh copy of the request body to send it again
+func rewind(req *http.Request) (io.ReadCloser, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req.Body, nil
+	}
+	if req.GetBody == nil {
+		return nil, errNotRewindable
+	}
+	return req.GetBody()
+}
+
+// retryAfter reads the delay asked by PagerDuty, from Retry-After in seconds or as a date, or from ratelimit-reset
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	value := resp.Header.Get("Retry-After")
+	if delay, ok := parseSeconds(value); ok {
+		return delay, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return time.Until(date), true
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return parseSeconds(resp.Header.Get("ratelimit-reset"))
+	}
+	return 0, false
+}
+
+func parseSeconds(value string) (time.Duration, bool) {
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+	return time.Duration(seconds) * time.Second, true
+}
+
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,246 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/PagerDuty/go-pagerduty"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// fakePagerDuty answers with the queued responses, then with 200 and an empty service
+type fakePagerDuty struct {
+	mu        sync.Mutex
+	responses []func(w http.ResponseWriter)
+	bodies    []string
+}
+
+func (f *fakePagerDuty) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	f.mu.Lock()
+	f.bodies = append(f.bodies, string(body))
+	var respond func(w http.ResponseWriter)
+	if len(f.responses) > 0 {
+		respond, f.responses = f.responses[0], f.responses[1:]
+	}
+	f.mu.Unlock()
+
+	if respond != nil {
+		respond(w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write([]byte(`{"service": {"id": "PSERVICE1"}}`))
+}
+
+func (f *fakePagerDuty) calls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.bodies)
+}
+
+func status(code int, headers ...string) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		for i := 0; i+1 < len(headers); i += 2 {
+			w.Header().Set(headers[i], headers[i+1])
+		}
+		w.WriteHeader(code)
+	}
+}
+
+var _ = Describe("Rate limiter", func() {
+	var fake *fakePagerDuty
+	var server *httptest.Server
+
+	BeforeEach(func() {
+		fake = &fakePagerDuty{}
+		server = httptest.NewServer(fake)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	newClient := func(limiter *Limiter) *pagerduty.Client {
+		pdClient := pagerduty.NewClient("token", pagerduty.WithAPIEndpoint(server.URL))
+		pdClient.HTTPClient = limiter.Wrap(pdClient.HTTPClient)
+		return pdClient
+	}
+
+	Context("When PagerDuty answers with 429", func() {
+		It("should retry with backoff until the call succeeds", func() {
+			fake.responses = append(fake.responses, status(429), status(429))
+			limiter := NewLimiter("retry", Options{MaxRetries: 3, BaseDelay: time.Millisecond})
+
+			service, err := newClient(limiter).GetServiceWithContext(context.TODO(), "PSERVICE1", nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(service.ID).To(Equal("PSERVICE1"))
+			Expect(fake.calls()).To(Equal(3))
+			Expect(testutil.ToFloat64(retries.WithLabelValues("retry", "429"))).To(Equal(2.0))
+		})
+
+		It("should return the 429 once the retries are used up", func() {
+			fake.responses = append(fake.responses, status(429), status(429), status(429))
+			limiter := NewLimiter("exhausted", Options{MaxRetries: 2, BaseDelay: time.Millisecond})
+
+			_, err := newClient(limiter).GetServiceWithContext(context.TODO(), "PSERVICE1", nil)
+			var apiErr pagerduty.APIError
+			Expect(err).To(BeAssignableToTypeOf(apiErr))
+			Expect(err.(pagerduty.APIError).RateLimited()).To(BeTrue())
+			Expect(fake.calls()).To(Equal(3))
+		})
+
+		It("should wait for Retry-After", func() {
+			fake.responses = append(fake.responses, status(429, "Retry-After", "1"))
+			limiter := NewLimiter("retry-after", Options{MaxRetries: 1, BaseDelay: time.Millisecond})
+
+			start := time.Now()
+			_, err := newClient(limiter).GetServiceWithContext(context.TODO(), "PSERVICE1", nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(time.Since(start)).To(BeNumerically(">=", time.Second))
+			Expect(fake.calls()).To(Equal(2))
+		})
+
+		It("should leave waits longer than the maximum delay to the caller", func() {
+			fake.responses = append(fake.responses, status(429, "ratelimit-reset", "60"))
+			limiter := NewLimiter("too-long", Options{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: time.Second})
+
+			start := time.Now()
+			_, err := newClient(limiter).GetServiceWithContext(context.TODO(), "PSERVICE1", nil)
+			Expect(err).To(HaveOccurred())
+			Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+			Expect(fake.calls()).To(Equal(1))
+		})
+
+		It("should stop waiting when the context is cancelled", func() {
+			fake.responses = append(fake.responses, status(429, "Retry-After", "10"))
+			limiter := NewLimiter("cancelled", Options{MaxRetries: 1, BaseDelay: time.Millisecond, MaxDelay: time.Minute})
+
+			ctx, cancel := context.WithTimeout(context.TODO(), 50*time.Millisecond)
+			defer cancel()
+			_, err := newClient(limiter).GetServiceWithContext(ctx, "PSERVICE1", nil)
+			Expect(err).To(MatchError(ContainSubstring(context.DeadlineExceeded.Error())))
+			Expect(fake.calls()).To(Equal(1))
+		})
+	})
+
+	Context("When PagerDuty fails with a server error", func() {
+		It("should send idempotent requests again with the same body", func() {
+			fake.responses = append(fake.responses, status(503))
+			limiter := NewLimiter("server-error", Options{MaxRetries: 1, BaseDelay: time.Millisecond})
+
+			_, err := newClient(limiter).UpdateServiceWithContext(context.TODO(), pagerduty.Service{APIObject: pagerduty.APIObject{ID: "PSERVICE1"}, Name: "checkout"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fake.bodies).To(HaveLen(2))
+			Expect(fake.bodies[0]).To(ContainSubstring(`"name":"checkout"`))
+			Expect(fake.bodies[1]).To(Equal(fake.bodies[0]))
+			Expect(testutil.ToFloat64(retries.WithLabelValues("server-error", "503"))).To(Equal(1.0))
+		})
+
+		It("should not send a creation twice", func() {
+			fake.responses = append(fake.responses, status(500))
+			limiter := NewLimiter("creation", Options{MaxRetries: 3, BaseDelay: time.Millisecond})
+
+			_, err := newClient(limiter).CreateServiceWithContext(context.TODO(), pagerduty.Service{Name: "checkout"})
+			Expect(err).To(HaveOccurred())
+			Expect(fake.calls()).To(Equal(1))
+		})
+	})
+
+	Context("When the token bucket is empty", func() {
+		It("should delay the requests and count them as throttled", func() {
+			limiter := NewLimiter("bucket", Options{QPS: 20, Burst: 1})
+			pdClient := newClient(limiter)
+
+			start := time.Now()
+			for i := 0; i < 3; i++ {
+				_, err := pdClient.GetServiceWithContext(context.TODO(), "PSERVICE1", nil)
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(time.Since(start)).To(BeNumerically(">=", 90*time.Millisecond))
+			Expect(testutil.ToFloat64(throttledRequests.WithLabelValues("bucket", reasonRateLimit))).To(Equal(2.0))
+		})
+	})
+
+	Context("When PagerDuty reports the quota as used up", func() {
+		It("should hold the next requests until the quota resets", func() {
+			fake.responses = append(fake.responses, func(w http.ResponseWriter) {
+				w.Header().Set("ratelimit-remaining", "0")
+				w.Header().Set("ratelimit-reset", "1")
+				_, _ = w.Write([]byte(`{"service": {"id": "PSERVICE1"}}`))
+			})
+			limiter := NewLimiter("quota", Options{})
+			pdClient := newClient(limiter)
+
+			_, err := pdClient.GetServiceWithContext(context.TODO(), "PSERVICE1", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			start := time.Now()
+			_, err = pdClient.GetServiceWithContext(context.TODO(), "PSERVICE1", nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(time.Since(start)).To(BeNumerically(">=", 900*time.Millisecond))
+			Expect(testutil.ToFloat64(throttledRequests.WithLabelValues("quota", reasonQuota))).To(Equal(1.0))
+		})
+
+		It("should answer as rate limited instead of holding the requests longer than the maximum delay", func() {
+			fake.responses = append(fake.responses, func(w http.ResponseWriter) {
+				w.Header().Set("ratelimit-remaining", "0")
+				w.Header().Set("ratelimit-reset", "60")
+				_, _ = w.Write([]byte(`{"service": {"id": "PSERVICE1"}}`))
+			})
+			limiter := NewLimiter("quota-too-long", Options{MaxDelay: time.Second})
+			pdClient := newClient(limiter)
+
+			_, err := pdClient.GetServiceWithContext(context.TODO(), "PSERVICE1", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			start := time.Now()
+			_, err = pdClient.GetServiceWithContext(context.TODO(), "PSERVICE1", nil)
+			var apiErr pagerduty.APIError
+			Expect(errors.As(err, &apiErr)).To(BeTrue())
+			Expect(apiErr.RateLimited()).To(BeTrue())
+			Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+			Expect(fake.calls()).To(Equal(1))
+			Expect(testutil.ToFloat64(throttledRequests.WithLabelValues("quota-too-long", reasonQuota))).To(Equal(1.0))
+		})
+	})
+
+	Context("When the token bucket is empty for longer than the maximum delay", func() {
+		It("should answer as rate limited without using up a token", func() {
+			limiter := NewLimiter("bucket-too-long", Options{QPS: 0.1, Burst: 1, MaxDelay: 100 * time.Millisecond})
+			pdClient := newClient(limiter)
+
+			_, err := pdClient.GetServiceWithContext(context.TODO(), "PSERVICE1", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			for i := 0; i < 2; i++ {
+				_, err = pdClient.GetServiceWithContext(context.TODO(), "PSERVICE1", nil)
+				var apiErr pagerduty.APIError
+				Expect(errors.As(err, &apiErr)).To(BeTrue())
+				Expect(apiErr.RateLimited()).To(BeTrue())
+			}
+			Expect(fake.calls()).To(Equal(1))
+			Expect(limiter.bucket.Tokens()).To(BeNumerically(">", -0.1))
+		})
+	})
+
+	It("should read Retry-After as a date", func() {
+		resp := &http.Response{StatusCode: 429, Header: http.Header{}}
+		resp.Header.Set("Retry-After", time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+
+		delay, ok := retryAfter(resp)
+		Expect(ok).To(BeTrue())
+		Expect(delay).To(BeNumerically("~", time.Minute, 2*time.Second))
+
+		_, ok = retryAfter(&http.Response{StatusCode: 503, Header: http.Header{"Ratelimit-Reset": []string{"5"}}})
+		Expect(ok).To(BeFalse())
+	})
+})
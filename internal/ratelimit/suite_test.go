@@ -0,0 +1,14 @@
+package ratelimit
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestRateLimit(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "Rate Limit Suite")
+}
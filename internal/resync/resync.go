@@ -0,0 +1,62 @@
+package resync
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// IntervalAnnotation overrides the resync period of a single resource, e.g. 30m. 0 disables the resync.
+const IntervalAnnotation = "pagerduty.platform.share-now.com/resync-interval"
+
+// ReasonUpstreamInSync is the reason of the Synced condition after a successful comparison with upstream
+const ReasonUpstreamInSync = "UpstreamInSync"
+
+// jitterFactor spreads the resyncs of resources created together over 10% of the interval
+const jitterFactor = 0.1
+
+// Interval returns the resync interval of the object: the duration of its annotation, or defaultPeriod.
+// An invalid annotation returns defaultPeriod together with an error.
+func Interval(obj metav1.Object, defaultPeriod time.Duration) (time.Duration, error) {
+	value, ok := obj.GetAnnotations()[IntervalAnnotation]
+	if !ok {
+		return defaultPeriod, nil
+	}
+
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval < 0 {
+		return defaultPeriod, fmt.Errorf("invalid %s annotation %q, it must be a positive duration like 30m", IntervalAnnotation, value)
+	}
+	return interval, nil
+}
+
+// Jitter returns the interval lengthened by a random duration of up to 10% of it
+func Jitter(interval time.Duration) time.Duration {
+	if interval <= 0 {
+		return interval
+	}
+	return interval + time.Duration(rand.Float64()*jitterFactor*float64(interval))
+}
+
+// Due returns true when the last successful sync should be recorded again. Recording it at most every half
+// interval keeps the status update, which triggers a reconcile of its own, from looping.
+func Due(lastSynced *metav1.Time, interval time.Duration, now time.Time) bool {
+	if lastSynced == nil {
+		return true
+	}
+	if interval <= 0 {
+		return false
+	}
+	return now.Sub(lastSynced.Time) >= interval/2
+}
+
+// RequeueHealthy schedules the next resync of a resource whose reconcile neither failed nor asked for a requeue
+func RequeueHealthy(result ctrl.Result, err error, interval time.Duration) (ctrl.Result, error) {
+	if err != nil || result.Requeue || result.RequeueAfter > 0 || interval <= 0 {
+		return result, err
+	}
+	return ctrl.Result{RequeueAfter: Jitter(interval)}, nil
+}
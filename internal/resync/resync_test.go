@@ -0,0 +1,90 @@
+package resync
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var _ = Describe("Resync", func() {
+
+	Describe("Reading the interval of a resource", func() {
+		annotated := func(value string) *metav1.ObjectMeta {
+			return &metav1.ObjectMeta{Annotations: map[string]string{IntervalAnnotation: value}}
+		}
+
+		It("should use the default period without annotation", func() {
+			interval, err := Interval(&metav1.ObjectMeta{}, 10*time.Minute)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(interval).To(Equal(10 * time.Minute))
+		})
+
+		It("should use the annotation", func() {
+			interval, err := Interval(annotated("30s"), 10*time.Minute)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(interval).To(Equal(30 * time.Second))
+		})
+
+		It("should fall back to the default period on an invalid annotation", func() {
+			interval, err := Interval(annotated("often"), 10*time.Minute)
+			Expect(err).To(HaveOccurred())
+			Expect(interval).To(Equal(10 * time.Minute))
+		})
+	})
+
+	It("should add at most 10% of jitter", func() {
+		for i := 0; i < 100; i++ {
+			Expect(Jitter(time.Minute)).To(And(
+				BeNumerically(">=", time.Minute),
+				BeNumerically("<=", time.Minute+6*time.Second),
+			))
+		}
+		Expect(Jitter(0)).To(BeZero())
+	})
+
+	Describe("Recording the last sync", func() {
+		now := time.Date(2023, 6, 5, 9, 0, 0, 0, time.UTC)
+
+		It("should record the first sync", func() {
+			Expect(Due(nil, 0, now)).To(BeTrue())
+		})
+
+		It("should record again after half the interval", func() {
+			lastSynced := metav1.NewTime(now.Add(-4 * time.Minute))
+			Expect(Due(&lastSynced, 10*time.Minute, now)).To(BeFalse())
+			Expect(Due(&lastSynced, 8*time.Minute, now)).To(BeTrue())
+		})
+
+		It("should not record again when the resync is disabled", func() {
+			lastSynced := metav1.NewTime(now.Add(-time.Hour))
+			Expect(Due(&lastSynced, 0, now)).To(BeFalse())
+		})
+	})
+
+	Describe("Requeueing healthy resources", func() {
+		It("should requeue after the interval", func() {
+			result, err := RequeueHealthy(ctrl.Result{}, nil, time.Minute)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically(">=", time.Minute))
+		})
+
+		It("should keep the result of failed or requeued reconciles", func() {
+			failure := errors.New("failed")
+			result, err := RequeueHealthy(ctrl.Result{}, failure, time.Minute)
+			Expect(err).To(Equal(failure))
+			Expect(result).To(Equal(ctrl.Result{}))
+
+			result, _ = RequeueHealthy(ctrl.Result{RequeueAfter: time.Second}, nil, time.Minute)
+			Expect(result.RequeueAfter).To(Equal(time.Second))
+		})
+
+		It("should not requeue when the resync is disabled", func() {
+			result, _ := RequeueHealthy(ctrl.Result{}, nil, 0)
+			Expect(result).To(Equal(ctrl.Result{}))
+		})
+	})
+})
@@ -0,0 +1,354 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/PagerDuty/go-pagerduty"
+	"github.com/go-logr/logr"
+	"gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/observe"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_metrics"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_utils"
+)
+
+type ScheduleAdapter struct {
+	// Ctx is the context of the reconcile, cancelling it cancels the API calls of the adapter
+	Ctx       context.Context
+	Logger    logr.Logger
+	PD_Client *pagerduty.Client
+}
+
+type Adapter interface {
+	CreateSchedule(*v1alpha1.ScheduleSpec) (string, error)
+	GetSchedule(string) (*pagerduty.Schedule, error)
+	DeleteSchedule(string) error
+	UpdateSchedule(*v1alpha1.Schedule) error
+	UpstreamDrift(v1alpha1.Schedule) (observe.Diff, error)
+	RetainSchedule(string) error
+}
+
+// AdapterFactory builds the Adapter used by a single reconcile on top of the given PagerDuty client
+type AdapterFactory func(ctx context.Context, logger logr.Logger, pdClient *pagerduty.Client) Adapter
+
+// NewScheduleAdapter is the AdapterFactory that talks to the PagerDuty API
+func NewScheduleAdapter(ctx context.Context, logger logr.Logger, pdClient *pagerduty.Client) Adapter {
+	return &ScheduleAdapter{
+		Ctx:       ctx,
+		Logger:    logger,
+		PD_Client: pdClient,
+	}
+}
+
+var schedule_type = "schedule"
+
+// convertSpec builds the upstream schedule. Layers whose name matches an entry of layerIDs keep their upstream ID,
+// so PagerDuty updates them instead of replacing them.
+func convertSpec(spec *v1alpha1.ScheduleSpec, layerIDs map[string]string) pagerduty.Schedule {
+	layers := make([]pagerduty.ScheduleLayer, len(spec.ScheduleLayers))
+	for i, layer := range spec.ScheduleLayers {
+		name := layerName(layer, i)
+		layers[i] = pagerduty.ScheduleLayer{
+			APIObject: pagerduty.APIObject{
+				ID: layerIDs[name],
+			},
+			Name:                      name,
+			Start:                     layer.Start,
+			End:                       layer.End,
+			RotationVirtualStart:      layer.RotationVirtualStart,
+			RotationTurnLengthSeconds: layer.RotationTurnLengthSeconds,
+			Users:                     convertUsers(layer),
+			Restrictions:              convertRestrictions(layer.Restrictions),
+		}
+	}
+
+	return pagerduty.Schedule{
+		APIObject: pagerduty.APIObject{
+			Type: schedule_type,
+		},
+		Name:           spec.Name,
+		Description:    spec.Description,
+		TimeZone:       spec.TimeZone,
+		ScheduleLayers: layers,
+	}
+}
+
+func convertUsers(layer v1alpha1.ScheduleLayer) []pagerduty.UserReference {
+	users := make([]pagerduty.UserReference, len(layer.Users))
+	for i, id := range layer.Users {
+		users[i] = pagerduty.UserReference{User: id.ToAPIObject()}
+	}
+	return users
+}
+
+func convertRestrictions(restrictions []v1alpha1.ScheduleRestriction) []pagerduty.Restriction {
+	var pdRestrictions []pagerduty.Restriction
+	for _, restriction := range restrictions {
+		pdRestrictions = append(pdRestrictions, pagerduty.Restriction{
+			Type:            restriction.Type,
+			StartTimeOfDay:  restriction.StartTimeOfDay,
+			StartDayOfWeek:  restriction.StartDayOfWeek,
+			DurationSeconds: restriction.DurationSeconds,
+		})
+	}
+	return pdRestrictions
+}
+
+// layerName returns the name PagerDuty gives to the layer at the given position
+func layerName(layer v1alpha1.ScheduleLayer, position int) string {
+	if layer.Name != "" {
+		return layer.Name
+	}
+	return fmt.Sprintf("Layer %d", position+1)
+}
+
+func (adapter *ScheduleAdapter) CreateSchedule(spec *v1alpha1.ScheduleSpec) (string, error) {
+	res, err := adapter.PD_Client.CreateScheduleWithContext(pd_metrics.Method(adapter.Ctx, "CreateSchedule"), convertSpec(spec, nil))
+	if err != nil {
+		adapter.Logger.Error(err, "Schedule creation unsuccessfull...")
+		return "", err
+	}
+
+	return res.ID, nil
+}
+
+func (adapter *ScheduleAdapter) DeleteSchedule(id string) error {
+	adapter.Logger.Info("Deleting schedule...")
+
+	err := adapter.PD_Client.DeleteScheduleWithContext(pd_metrics.Method(adapter.Ctx, "DeleteSchedule"), id)
+	if err != nil {
+		adapter.Logger.Error(err, "ERROR: Failed to delete Schedule")
+		return err
+	}
+
+	adapter.Logger.Info("Schedule deleted...")
+	return nil
+}
+
+// RetainSchedule renames the upstream schedule with the retained suffix instead of deleting it
+func (adapter *ScheduleAdapter) RetainSchedule(id string) error {
+	adapter.Logger.Info("Retaining schedule...")
+
+	PDSchedule, err := adapter.GetSchedule(id)
+	if err != nil {
+		return err
+	}
+
+	PDSchedule.Name = pd_utils.RetainedName(PDSchedule.Name)
+	if _, err := adapter.PD_Client.UpdateScheduleWithContext(pd_metrics.Method(adapter.Ctx, "RetainSchedule"), id, *PDSchedule); err != nil {
+		adapter.Logger.Error(err, "API Failed to rename retained Schedule")
+		return err
+	}
+
+	adapter.Logger.Info("Schedule retained...", "name", PDSchedule.Name)
+	return nil
+}
+
+func (adapter *ScheduleAdapter) UpdateSchedule(k8sSchedule *v1alpha1.Schedule) error {
+	adapter.Logger.Info("Updating schedule...")
+
+	PDSchedule, err := adapter.GetSchedule(k8sSchedule.Status.ScheduleID)
+	if err != nil {
+		return err
+	}
+
+	layerIDs := make(map[string]string, len(PDSchedule.ScheduleLayers))
+	for _, layer := range PDSchedule.ScheduleLayers {
+		layerIDs[layer.Name] = layer.ID
+	}
+
+	_, err = adapter.PD_Client.UpdateScheduleWithContext(
+		pd_metrics.Method(adapter.Ctx, "UpdateSchedule"),
+		k8sSchedule.Status.ScheduleID,
+		convertSpec(&k8sSchedule.Spec, layerIDs),
+	)
+	if err != nil {
+		adapter.Logger.Error(err, "API Failed to update Schedule")
+		return err
+	}
+
+	adapter.Logger.Info("Upstream Schedule updated...")
+	return nil
+}
+
+// UpstreamDrift returns the spec fields whose value differs from the upstream schedule
+func (adapter *ScheduleAdapter) UpstreamDrift(k8sSchedule v1alpha1.Schedule) (observe.Diff, error) {
+	PDSchedule, err := adapter.GetSchedule(k8sSchedule.Status.ScheduleID)
+	if err != nil {
+		adapter.Logger.Error(err, "Failed to get Schedule")
+		return nil, err
+	}
+
+	return scheduleDiff(&k8sSchedule.Spec, PDSchedule), nil
+}
+
+func (adapter *ScheduleAdapter) GetSchedule(id string) (*pagerduty.Schedule, error) {
+	PDSchedule, err := adapter.PD_Client.GetScheduleWithContext(pd_metrics.Method(adapter.Ctx, "GetSchedule"), id, pagerduty.GetScheduleOptions{})
+	if err != nil {
+		adapter.Logger.Error(err, "Failed to get Schedule")
+		return nil, err
+	}
+
+	adapter.Logger.Info("Schedule retrieved", "PDSchedule", PDSchedule.ID)
+	return PDSchedule, nil
+}
+
+// scheduleMatches compares the spec with the upstream schedule
+func scheduleMatches(spec *v1alpha1.ScheduleSpec, PDSchedule *pagerduty.Schedule) bool {
+	return len(scheduleDiff(spec, PDSchedule)) == 0
+}
+
+// scheduleDiff compares the spec with the upstream schedule field by field. Layers are matched by name,
+// since PagerDuty does not return them in the order they were sent, and reported as schedule_layers[<name>].
+func scheduleDiff(spec *v1alpha1.ScheduleSpec, PDSchedule *pagerduty.Schedule) observe.Diff {
+	var diff observe.Diff
+
+	diff.Compare("name", spec.Name, PDSchedule.Name)
+	diff.Compare("description", spec.Description, PDSchedule.Description)
+	diff.Compare("time_zone", spec.TimeZone, PDSchedule.TimeZone)
+
+	PDLayers := make(map[string]pagerduty.ScheduleLayer, len(PDSchedule.ScheduleLayers))
+	for _, layer := range PDSchedule.ScheduleLayers {
+		PDLayers[layer.Name] = layer
+	}
+
+	for i, layer := range spec.ScheduleLayers {
+		name := layerName(layer, i)
+		PDLayer, ok := PDLayers[name]
+		delete(PDLayers, name)
+
+		field := fmt.Sprintf("schedule_layers[%s]", name)
+		desired := specLayerView(layer)
+		if !ok {
+			diff.Add(field, desired, nil)
+		} else if !layerMatches(layer, PDLayer) {
+			diff.Add(field, desired, upstreamLayerView(PDLayer))
+		}
+	}
+
+	for _, PDLayer := range PDSchedule.ScheduleLayers {
+		if _, extra := PDLayers[PDLayer.Name]; extra {
+			diff.Add(fmt.Sprintf("schedule_layers[%s]", PDLayer.Name), nil, upstreamLayerView(PDLayer))
+		}
+	}
+
+	return diff
+}
+
+// layerView describes a schedule layer in the drift reports
+type layerView struct {
+	Start                     string   `json:"start"`
+	End                       string   `json:"end,omitempty"`
+	RotationVirtualStart      string   `json:"rotation_virtual_start"`
+	RotationTurnLengthSeconds uint     `json:"rotation_turn_length_seconds"`
+	Users                     []string `json:"users"`
+	Restrictions              int      `json:"restrictions"`
+}
+
+func specLayerView(layer v1alpha1.ScheduleLayer) layerView {
+	users := make([]string, len(layer.Users))
+	for i, user := range layer.Users {
+		users[i] = string(user)
+	}
+	return layerView{
+		Start:                     layer.Start,
+		End:                       layer.End,
+		RotationVirtualStart:      layer.RotationVirtualStart,
+		RotationTurnLengthSeconds: layer.RotationTurnLengthSeconds,
+		Users:                     users,
+		Restrictions:              len(layer.Restrictions),
+	}
+}
+
+func upstreamLayerView(PDLayer pagerduty.ScheduleLayer) layerView {
+	users := make([]string, len(PDLayer.Users))
+	for i, user := range PDLayer.Users {
+		users[i] = user.User.ID
+	}
+	return layerView{
+		Start:                     PDLayer.Start,
+		End:                       PDLayer.End,
+		RotationVirtualStart:      PDLayer.RotationVirtualStart,
+		RotationTurnLengthSeconds: PDLayer.RotationTurnLengthSeconds,
+		Users:                     users,
+		Restrictions:              len(PDLayer.Restrictions),
+	}
+}
+
+func layerMatches(layer v1alpha1.ScheduleLayer, PDLayer pagerduty.ScheduleLayer) bool {
+	if !sameTime(layer.Start, PDLayer.Start) ||
+		!sameTime(layer.End, PDLayer.End) ||
+		!sameTime(layer.RotationVirtualStart, PDLayer.RotationVirtualStart) ||
+		layer.RotationTurnLengthSeconds != PDLayer.RotationTurnLengthSeconds ||
+		len(layer.Users) != len(PDLayer.Users) {
+		return false
+	}
+
+	// the order of the users defines the rotation, so it has to match
+	for i, user := range layer.Users {
+		if string(user) != PDLayer.Users[i].User.ID {
+			return false
+		}
+	}
+
+	return restrictionsMatch(convertRestrictions(layer.Restrictions), PDLayer.Restrictions)
+}
+
+func restrictionsMatch(restrictions []pagerduty.Restriction, PDRestrictions []pagerduty.Restriction) bool {
+	if len(restrictions) != len(PDRestrictions) {
+		return false
+	}
+
+	sortRestrictions(restrictions)
+	PDRestrictions = append([]pagerduty.Restriction(nil), PDRestrictions...)
+	sortRestrictions(PDRestrictions)
+
+	for i := range restrictions {
+		if restrictions[i] != PDRestrictions[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func sortRestrictions(restrictions []pagerduty.Restriction) {
+	sort.Slice(restrictions, func(i, j int) bool {
+		if restrictions[i].StartDayOfWeek != restrictions[j].StartDayOfWeek {
+			return restrictions[i].StartDayOfWeek < restrictions[j].StartDayOfWeek
+		}
+		if restrictions[i].StartTimeOfDay != restrictions[j].StartTimeOfDay {
+			return restrictions[i].StartTimeOfDay < restrictions[j].StartTimeOfDay
+		}
+		return restrictions[i].Type < restrictions[j].Type
+	})
+}
+
+// sameTime compares two RFC 3339 timestamps. PagerDuty returns them in the time zone of the schedule,
+// so they rarely match as strings.
+func sameTime(k8sTime string, PDTime string) bool {
+	if k8sTime == "" || PDTime == "" {
+		return k8sTime == PDTime
+	}
+
+	t1, err := time.Parse(time.RFC3339, k8sTime)
+	if err != nil {
+		return false
+	}
+	t2, err := time.Parse(time.RFC3339, PDTime)
+	if err != nil {
+		return false
+	}
+
+	return t1.Equal(t2)
+}
+
+// scheduleSnapshot returns the managed fields of the upstream schedule
+func scheduleSnapshot(PDSchedule *pagerduty.Schedule) *v1alpha1.UpstreamSnapshot {
+	snapshot := &v1alpha1.UpstreamSnapshot{Name: PDSchedule.Name, Description: PDSchedule.Description}
+	snapshot.SetField("time_zone", PDSchedule.TimeZone)
+	snapshot.SetField("schedule_layers", strconv.Itoa(len(PDSchedule.ScheduleLayers)))
+	return snapshot
+}
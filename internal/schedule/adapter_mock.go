@@ -0,0 +1,75 @@
+package schedule
+
+import (
+	"fmt"
+
+	"github.com/PagerDuty/go-pagerduty"
+	"github.com/go-logr/logr"
+	"gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/observe"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_utils"
+)
+
+type ScheduleMockAdapter struct {
+	Logger logr.Logger
+}
+
+var Default_schedule_name = "default-schedule"
+var Default_schedule_description = "default_schedule_description"
+var Default_schedule_time_zone = "Europe/Berlin"
+
+var schedules map[string]pagerduty.Schedule = make(map[string]pagerduty.Schedule)
+
+func (adapter *ScheduleMockAdapter) CreateSchedule(spec *v1alpha1.ScheduleSpec) (string, error) {
+	adapter.Logger.Info("Schedule created...")
+	schedule := convertSpec(spec, nil)
+	schedule.HTMLURL = "https://example.pagerduty.com/schedules/" + spec.Name
+	schedules[spec.Name] = schedule
+
+	return spec.Name, nil
+}
+
+func (adapter *ScheduleMockAdapter) DeleteSchedule(id string) error {
+	delete(schedules, id)
+
+	adapter.Logger.Info("Schedule deleted...")
+	return nil
+}
+
+func (adapter *ScheduleMockAdapter) RetainSchedule(id string) error {
+	schedule, ok := schedules[id]
+	if !ok {
+		return fmt.Errorf("schedule not found")
+	}
+
+	schedule.Name = pd_utils.RetainedName(schedule.Name)
+	schedules[id] = schedule
+
+	adapter.Logger.Info("Schedule retained...")
+	return nil
+}
+
+func (adapter *ScheduleMockAdapter) UpdateSchedule(k8sSchedule *v1alpha1.Schedule) error {
+	schedules[k8sSchedule.Status.ScheduleID] = convertSpec(&k8sSchedule.Spec, nil)
+	adapter.Logger.Info("Upstream Schedule updated...")
+	return nil
+}
+
+func (adapter *ScheduleMockAdapter) UpstreamDrift(k8sSchedule v1alpha1.Schedule) (observe.Diff, error) {
+	PDSchedule, err := adapter.GetSchedule(k8sSchedule.Status.ScheduleID)
+	if err != nil {
+		adapter.Logger.Error(err, "Failed to get Schedule")
+		return nil, err
+	}
+
+	return scheduleDiff(&k8sSchedule.Spec, PDSchedule), nil
+}
+
+func (adapter *ScheduleMockAdapter) GetSchedule(id string) (*pagerduty.Schedule, error) {
+	schedule, ok := schedules[id]
+	if !ok {
+		return nil, fmt.Errorf("schedule not found")
+	}
+
+	return &schedule, nil
+}
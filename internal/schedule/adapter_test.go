@@ -0,0 +1,101 @@
+package schedule
+
+import (
+	"github.com/PagerDuty/go-pagerduty"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/typeinfo"
+)
+
+var _ = Describe("Schedule drift detection", func() {
+	var spec *v1alpha1.ScheduleSpec
+	var pdSchedule *pagerduty.Schedule
+
+	BeforeEach(func() {
+		spec = &v1alpha1.ScheduleSpec{
+			Name:     "drift-schedule",
+			TimeZone: "Europe/Berlin",
+			ScheduleLayers: []v1alpha1.ScheduleLayer{
+				{
+					Name:                      "Weekly",
+					Start:                     "2023-06-05T09:00:00+02:00",
+					RotationVirtualStart:      "2023-06-05T09:00:00+02:00",
+					RotationTurnLengthSeconds: 604800,
+					Users:                     []typeinfo.UserID{"USERA", "USERB"},
+					Restrictions: []v1alpha1.ScheduleRestriction{
+						{Type: "weekly_restriction", StartDayOfWeek: 1, StartTimeOfDay: "09:00:00", DurationSeconds: 32400},
+						{Type: "weekly_restriction", StartDayOfWeek: 2, StartTimeOfDay: "09:00:00", DurationSeconds: 32400},
+					},
+				},
+				{
+					Start:                     "2023-06-05T09:00:00+02:00",
+					RotationVirtualStart:      "2023-06-05T09:00:00+02:00",
+					RotationTurnLengthSeconds: 86400,
+					Users:                     []typeinfo.UserID{"USERC"},
+				},
+			},
+		}
+
+		pdSchedule = &pagerduty.Schedule{
+			Name:     "drift-schedule",
+			TimeZone: "Europe/Berlin",
+			ScheduleLayers: []pagerduty.ScheduleLayer{
+				{
+					Name:                      "Layer 2",
+					Start:                     "2023-06-05T07:00:00Z",
+					RotationVirtualStart:      "2023-06-05T07:00:00Z",
+					RotationTurnLengthSeconds: 86400,
+					Users:                     []pagerduty.UserReference{{User: pagerduty.APIObject{ID: "USERC"}}},
+				},
+				{
+					Name:                      "Weekly",
+					Start:                     "2023-06-05T09:00:00+02:00",
+					RotationVirtualStart:      "2023-06-05T09:00:00+02:00",
+					RotationTurnLengthSeconds: 604800,
+					Users: []pagerduty.UserReference{
+						{User: pagerduty.APIObject{ID: "USERA"}},
+						{User: pagerduty.APIObject{ID: "USERB"}},
+					},
+					Restrictions: []pagerduty.Restriction{
+						{Type: "weekly_restriction", StartDayOfWeek: 2, StartTimeOfDay: "09:00:00", DurationSeconds: 32400},
+						{Type: "weekly_restriction", StartDayOfWeek: 1, StartTimeOfDay: "09:00:00", DurationSeconds: 32400},
+					},
+				},
+			},
+		}
+	})
+
+	It("should match regardless of layer order, time zone offsets and restriction order", func() {
+		Expect(scheduleMatches(spec, pdSchedule)).To(BeTrue())
+	})
+
+	It("should detect a change in the rotation order", func() {
+		spec.ScheduleLayers[0].Users = []typeinfo.UserID{"USERB", "USERA"}
+		Expect(scheduleMatches(spec, pdSchedule)).To(BeFalse())
+	})
+
+	It("should detect a changed restriction", func() {
+		spec.ScheduleLayers[0].Restrictions[1].DurationSeconds = 3600
+		Expect(scheduleMatches(spec, pdSchedule)).To(BeFalse())
+	})
+
+	It("should detect a changed time zone", func() {
+		spec.TimeZone = "UTC"
+		Expect(scheduleMatches(spec, pdSchedule)).To(BeFalse())
+	})
+
+	It("should report the drifted fields and layers", func() {
+		spec.TimeZone = "UTC"
+		spec.ScheduleLayers[0].Users = []typeinfo.UserID{"USERB", "USERA"}
+
+		Expect(scheduleDiff(spec, pdSchedule).Fields()).To(ConsistOf("time_zone", "schedule_layers[Weekly]"))
+	})
+	It("should summarize the upstream schedule in the status snapshot", func() {
+		snapshot := scheduleSnapshot(pdSchedule)
+
+		Expect(snapshot.Name).To(Equal("drift-schedule"))
+		Expect(snapshot.Fields).To(HaveKeyWithValue("time_zone", "Europe/Berlin"))
+		Expect(snapshot.Fields).To(HaveKeyWithValue("schedule_layers", "1"))
+	})
+})
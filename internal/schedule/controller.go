@@ -0,0 +1,169 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedule
+
+import (
+	"context"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	pagerdutyalpha1 "gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/condition"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/credentials"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/events"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/k8s_utils"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/observe"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/pause"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_errors"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_utils"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/resync"
+)
+
+// ScheduleReconciler reconciles a Schedule object
+type ScheduleReconciler struct {
+	client.Client
+	Scheme         *runtime.Scheme
+	Recorder       record.EventRecorder
+	ClientProvider credentials.ClientProvider
+	NewAdapter     AdapterFactory
+	// DefaultDeletionPolicy applies to the resources that do not set a deletion policy
+	DefaultDeletionPolicy pagerdutyalpha1.DeletionPolicy
+	// ResyncPeriod defines how often healthy resources are compared with upstream, 0 disables the resync
+	ResyncPeriod time.Duration
+	// DefaultMode applies to the resources without a mode annotation
+	DefaultMode observe.Mode
+}
+
+type Subroutines interface {
+	ReconcileCreation() (pd_utils.OperationResult, error)
+	ReconcileDeletion() (pd_utils.OperationResult, error)
+	ReconcileUpdate() (pd_utils.OperationResult, error)
+	Initialization() (pd_utils.OperationResult, error)
+	AddFinalizer() (pd_utils.OperationResult, error)
+	EnsureCredentials() (pd_utils.OperationResult, error)
+	CheckPaused() (pd_utils.OperationResult, error)
+	CheckInvalidSpec() (pd_utils.OperationResult, error)
+	ObserveUpstream() (pd_utils.OperationResult, error)
+	MarkSynced() (pd_utils.OperationResult, error)
+}
+
+//+kubebuilder:rbac:groups=pagerduty.platform.share-now.com,resources=schedules,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=pagerduty.platform.share-now.com,resources=schedules/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=pagerduty.platform.share-now.com,resources=schedules/finalizers,verbs=update
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.14.1/pkg/reconcile
+func (r *ScheduleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+	log.Info("Starting Schedule reconcile...")
+
+	schedule := &pagerdutyalpha1.Schedule{}
+	err := r.Get(ctx, req.NamespacedName, schedule)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			// If the custom resource is not found then, it usually means that it was deleted or not created
+			// In this way, we will stop the reconciliation
+			log.Info("Schedule resource not found. Ignoring since object must be deleted")
+			return k8s_utils.DoNotRequeue()
+		}
+		// Error reading the object - requeue the request.
+		log.Info("Failed to get Schedule")
+
+		return k8s_utils.RequeueWithError(err)
+	}
+
+	pdClient, credentialsErr := r.ClientProvider.Client(ctx, schedule.Spec.AccountRef)
+
+	resyncInterval, err := resync.Interval(schedule, r.ResyncPeriod)
+	if err != nil {
+		log.Error(err, "Ignoring resync interval annotation")
+	}
+
+	mode, err := observe.ModeOf(schedule, r.DefaultMode)
+	if err != nil {
+		log.Error(err, "Ignoring mode annotation")
+	}
+
+	paused := pause.IsPaused(schedule)
+	deletionPolicy := observe.DeletionPolicy(mode, schedule.Spec.DeletionPolicy.OrDefault(r.DefaultDeletionPolicy))
+	if paused || pd_errors.SpecRejected(schedule.Status.Conditions, schedule.Generation) {
+		// removing the annotation or changing the spec triggers a reconcile, there is nothing to resync until then
+		resyncInterval = 0
+	}
+
+	subroutineHandler := &SubroutineHandler{
+		Logger:           log.WithName("schedule controller"),
+		K8sClient:        r.Client,
+		Adapter:          r.NewAdapter(ctx, log.WithName("Schedule Adapter"), pdClient),
+		Schedule:         schedule,
+		Events:           events.NewRecorder(r.Recorder, schedule, "Schedule"),
+		DeletionPolicy:   deletionPolicy,
+		Paused:           paused,
+		Observe:          mode == observe.ModeObserve,
+		ResyncInterval:   resyncInterval,
+		conditionManager: condition.NewConditionManager(),
+		credentialsErr:   credentialsErr,
+	}
+
+	// healthy resources are requeued, so changes made in PagerDuty are detected without waiting for a Kubernetes event
+	result, err := r.ReconcileHandler(subroutineHandler)
+	return resync.RequeueHealthy(result, err, resyncInterval)
+}
+
+type ReconcileOperation func() (pd_utils.OperationResult, error)
+
+func (r *ScheduleReconciler) ReconcileHandler(subroutines Subroutines) (ctrl.Result, error) {
+	operations := []ReconcileOperation{
+		subroutines.Initialization,
+		subroutines.AddFinalizer,
+		subroutines.EnsureCredentials,
+		subroutines.ReconcileDeletion,
+		subroutines.CheckPaused,
+		subroutines.CheckInvalidSpec,
+		subroutines.ObserveUpstream,
+		subroutines.ReconcileCreation,
+		subroutines.ReconcileUpdate,
+		subroutines.MarkSynced,
+	}
+	for _, operation := range operations {
+		result, err := operation()
+		if err != nil || result.RequeueRequest {
+			return ctrl.Result{RequeueAfter: result.RequeueDelay}, err
+		}
+		if result.CancelRequest {
+			return ctrl.Result{}, nil
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ScheduleReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&pagerdutyalpha1.Schedule{}).
+		Complete(r)
+}
@@ -0,0 +1,311 @@
+package schedule
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	pagerdutyv1alpha1 "gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/events"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/observe"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/pause"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_utils"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/typeinfo"
+	core "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var timeout time.Duration = time.Second * 13
+var interval time.Duration = time.Millisecond * 250
+
+type TestScheduleEnv struct {
+	ScheduleNamespace string
+	Schedule          *pagerdutyv1alpha1.Schedule
+}
+
+func setupTest() *TestScheduleEnv {
+	scheduleNamespace := "test-" + pd_utils.RandStr(5)
+
+	err := k8sClient.Create(ctx, &core.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: scheduleNamespace},
+	})
+
+	Expect(err).NotTo(HaveOccurred(), "failed to create test namespace")
+
+	schedule := &pagerdutyv1alpha1.Schedule{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "pagerduty.platform.share-now.com/v1alpha1",
+			Kind:       "Schedule",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      Default_schedule_name,
+			Namespace: scheduleNamespace,
+		},
+		Spec: pagerdutyv1alpha1.ScheduleSpec{
+			Name:        Default_schedule_name,
+			Description: Default_schedule_description,
+			TimeZone:    Default_schedule_time_zone,
+			ScheduleLayers: []pagerdutyv1alpha1.ScheduleLayer{
+				{
+					Name:                      "Weekly",
+					Start:                     "2023-06-05T09:00:00+02:00",
+					RotationVirtualStart:      "2023-06-05T09:00:00+02:00",
+					RotationTurnLengthSeconds: 604800,
+					Users:                     []typeinfo.UserID{"MOCKUSERID"},
+				},
+			},
+		},
+	}
+
+	Expect(k8sClient.Create(ctx, schedule)).Should(Succeed())
+
+	return &TestScheduleEnv{
+		ScheduleNamespace: scheduleNamespace,
+		Schedule:          schedule,
+	}
+}
+
+func cleanUp(testEnv *TestScheduleEnv) {
+	err := k8sClient.Delete(ctx, &core.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: testEnv.ScheduleNamespace},
+	})
+	Expect(err).NotTo(HaveOccurred(), "failed to delete test namespace")
+}
+
+var _ = Describe("Schedule controller", func() {
+
+	var testEnv *TestScheduleEnv
+
+	BeforeEach(func() {
+		testEnv = setupTest()
+	})
+
+	AfterEach(func() {
+		cleanUp(testEnv)
+	})
+
+	getScheduleID := func() string {
+		err := k8sClient.Get(
+			context.Background(),
+			types.NamespacedName{Name: Default_schedule_name, Namespace: testEnv.ScheduleNamespace},
+			testEnv.Schedule,
+		)
+		if err != nil {
+			return ""
+		}
+		return testEnv.Schedule.Status.ScheduleID
+	}
+
+	Context("When creating a Schedule", func() {
+		It("Should be able to set the status to contain the schedule ID", func() {
+			Eventually(getScheduleID, timeout, interval).Should(Equal(Default_schedule_name))
+			Expect(testEnv.Schedule.Status.Conditions[0].Status).Should(Equal(metav1.ConditionTrue))
+		})
+
+		It("Should record the last successful sync with upstream", func() {
+			Eventually(func() *metav1.Time {
+				getScheduleID()
+				return testEnv.Schedule.Status.LastSyncedTime
+			}, timeout, interval).ShouldNot(BeNil())
+
+			var synced *metav1.Condition
+			for i, condition := range testEnv.Schedule.Status.Conditions {
+				if condition.Type == pagerdutyv1alpha1.ConditionSynced.String() {
+					synced = &testEnv.Schedule.Status.Conditions[i]
+				}
+			}
+			Expect(synced).NotTo(BeNil())
+			Expect(synced.Status).Should(Equal(metav1.ConditionTrue))
+		})
+
+		It("Should record the observed generation and a summary of the upstream schedule", func() {
+			Eventually(func() int64 {
+				getScheduleID()
+				return testEnv.Schedule.Status.ObservedGeneration
+			}, timeout, interval).Should(Equal(testEnv.Schedule.Generation))
+
+			Expect(testEnv.Schedule.Status.HTMLURL).Should(HaveSuffix("/schedules/" + Default_schedule_name))
+			Expect(testEnv.Schedule.Status.Upstream).NotTo(BeNil())
+			Expect(testEnv.Schedule.Status.Upstream.Name).Should(Equal(testEnv.Schedule.Spec.Name))
+		})
+	})
+
+	Context("When recording events", func() {
+		It("Should record the creation of the upstream schedule with its ID", func() {
+			Eventually(getScheduleID, timeout, interval).Should(Equal(Default_schedule_name))
+
+			Eventually(func() []string {
+				eventList := &core.EventList{}
+				if err := k8sClient.List(ctx, eventList, client.InNamespace(testEnv.ScheduleNamespace)); err != nil {
+					return nil
+				}
+				var messages []string
+				for _, event := range eventList.Items {
+					if event.Reason == events.ReasonCreated {
+						messages = append(messages, event.Message)
+					}
+				}
+				return messages
+			}, timeout, interval).Should(ContainElement("Created PagerDuty Schedule " + Default_schedule_name))
+		})
+	})
+
+	Context("When updating a Schedule", func() {
+		It("Should update the upstream schedule layers", func() {
+			Eventually(getScheduleID, timeout, interval).Should(Equal(Default_schedule_name))
+
+			testEnv.Schedule.Spec.ScheduleLayers[0].Users = []typeinfo.UserID{"MOCKUSERID", "OTHERMOCKUSERID"}
+			Expect(k8sClient.Update(ctx, testEnv.Schedule)).Should(Succeed())
+
+			Eventually(func() int {
+				upstream, ok := schedules[Default_schedule_name]
+				if !ok {
+					return 0
+				}
+				return len(upstream.ScheduleLayers[0].Users)
+			}, timeout, interval).Should(Equal(2))
+		})
+	})
+
+	Context("When observing a Schedule", func() {
+		It("Should report the missing upstream schedule without creating it", func() {
+			observed := testEnv.Schedule.DeepCopy()
+			observed.ObjectMeta = metav1.ObjectMeta{
+				Name:        "observed-schedule",
+				Namespace:   testEnv.ScheduleNamespace,
+				Annotations: map[string]string{observe.ModeAnnotation: string(observe.ModeObserve)},
+			}
+			observed.Spec.Name = "observed-schedule"
+			Expect(k8sClient.Create(ctx, observed)).Should(Succeed())
+
+			Eventually(func() string {
+				err := k8sClient.Get(ctx, types.NamespacedName{Name: "observed-schedule", Namespace: testEnv.ScheduleNamespace}, observed)
+				if err != nil {
+					return ""
+				}
+				for _, condition := range observed.Status.Conditions {
+					if condition.Type == pagerdutyv1alpha1.ConditionDriftDetected.String() {
+						return condition.Reason
+					}
+				}
+				return ""
+			}, timeout, interval).Should(Equal(observe.ReasonUpstreamMissing))
+
+			Expect(observed.Status.ScheduleID).Should(BeEmpty())
+			Expect(schedules).ShouldNot(HaveKey("observed-schedule"))
+		})
+	})
+
+	Context("When pausing a Schedule", func() {
+		It("Should not create the upstream schedule until the annotation is removed", func() {
+			paused := testEnv.Schedule.DeepCopy()
+			paused.ObjectMeta = metav1.ObjectMeta{
+				Name:        "paused-schedule",
+				Namespace:   testEnv.ScheduleNamespace,
+				Annotations: map[string]string{pause.Annotation: "true"},
+			}
+			paused.Spec.Name = "paused-schedule"
+			Expect(k8sClient.Create(ctx, paused)).Should(Succeed())
+
+			getPaused := func() metav1.ConditionStatus {
+				err := k8sClient.Get(ctx, types.NamespacedName{Name: "paused-schedule", Namespace: testEnv.ScheduleNamespace}, paused)
+				if err != nil {
+					return ""
+				}
+				for _, condition := range paused.Status.Conditions {
+					if condition.Type == pagerdutyv1alpha1.ConditionPaused.String() {
+						return condition.Status
+					}
+				}
+				return ""
+			}
+			Eventually(getPaused, timeout, interval).Should(Equal(metav1.ConditionTrue))
+			Expect(paused.Status.ScheduleID).Should(BeEmpty())
+			Expect(schedules).ShouldNot(HaveKey("paused-schedule"))
+
+			delete(paused.Annotations, pause.Annotation)
+			Expect(k8sClient.Update(ctx, paused)).Should(Succeed())
+
+			Eventually(getPaused, timeout, interval).Should(Equal(metav1.ConditionFalse))
+			Eventually(func() string {
+				getPaused()
+				return paused.Status.ScheduleID
+			}, timeout, interval).Should(Equal("paused-schedule"))
+		})
+	})
+
+	Context("When deleting a Schedule", func() {
+		deleteWithPolicy := func(deletionPolicy pagerdutyv1alpha1.DeletionPolicy) {
+			Eventually(getScheduleID, timeout, interval).Should(Equal(Default_schedule_name))
+
+			testEnv.Schedule.Spec.DeletionPolicy = deletionPolicy
+			Expect(k8sClient.Update(ctx, testEnv.Schedule)).Should(Succeed())
+			Expect(k8sClient.Delete(ctx, testEnv.Schedule)).Should(Succeed())
+
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, types.NamespacedName{Name: Default_schedule_name, Namespace: testEnv.ScheduleNamespace}, testEnv.Schedule)
+				return apierrors.IsNotFound(err)
+			}, timeout, interval).Should(BeTrue())
+		}
+
+		It("Should delete the upstream schedule by default", func() {
+			deleteWithPolicy("")
+			Expect(schedules).ShouldNot(HaveKey(Default_schedule_name))
+		})
+
+		It("Should leave the upstream schedule untouched with the Orphan policy", func() {
+			deleteWithPolicy(pagerdutyv1alpha1.DeletionPolicyOrphan)
+			Expect(schedules).Should(HaveKey(Default_schedule_name))
+			Expect(schedules[Default_schedule_name].Name).Should(Equal(Default_schedule_name))
+			delete(schedules, Default_schedule_name)
+		})
+
+		It("Should rename the upstream schedule with the Retain policy", func() {
+			deleteWithPolicy(pagerdutyv1alpha1.DeletionPolicyRetain)
+			Expect(schedules).Should(HaveKey(Default_schedule_name))
+			Expect(schedules[Default_schedule_name].Name).Should(Equal(Default_schedule_name + pd_utils.RetainedNameSuffix))
+			delete(schedules, Default_schedule_name)
+		})
+
+		It("Should keep a paused schedule with the Delete policy until the annotation is removed", func() {
+			Eventually(getScheduleID, timeout, interval).Should(Equal(Default_schedule_name))
+
+			setPaused := func(paused bool) error {
+				if err := k8sClient.Get(ctx, types.NamespacedName{Name: Default_schedule_name, Namespace: testEnv.ScheduleNamespace}, testEnv.Schedule); err != nil {
+					return err
+				}
+				if paused {
+					testEnv.Schedule.Annotations = map[string]string{pause.Annotation: "true"}
+				} else {
+					delete(testEnv.Schedule.Annotations, pause.Annotation)
+				}
+				return k8sClient.Update(ctx, testEnv.Schedule)
+			}
+			Eventually(func() error { return setPaused(true) }, timeout, interval).Should(Succeed())
+			Expect(k8sClient.Delete(ctx, testEnv.Schedule)).Should(Succeed())
+
+			Eventually(func() string {
+				getScheduleID()
+				for _, condition := range testEnv.Schedule.Status.Conditions {
+					if condition.Type == pagerdutyv1alpha1.ConditionDeletionBlocked.String() && condition.Status == metav1.ConditionTrue {
+						return condition.Reason
+					}
+				}
+				return ""
+			}, timeout, interval).Should(Equal(pause.ReasonPaused))
+			Expect(schedules).Should(HaveKey(Default_schedule_name))
+
+			Eventually(func() error { return setPaused(false) }, timeout, interval).Should(Succeed())
+
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, types.NamespacedName{Name: Default_schedule_name, Namespace: testEnv.ScheduleNamespace}, testEnv.Schedule)
+				return apierrors.IsNotFound(err)
+			}, timeout, interval).Should(BeTrue())
+			Expect(schedules).ShouldNot(HaveKey(Default_schedule_name))
+		})
+	})
+})
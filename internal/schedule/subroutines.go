@@ -0,0 +1,368 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/condition"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/events"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_errors"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_metrics"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const scheduleFinalizer = "pagerduty.platform.share-now.com/schedule"
+const scheduleReady = "PDScheduleReady"
+const RequeWaitTime = time.Second * 10
+
+type SubroutineHandler struct {
+	Schedule         *v1alpha1.Schedule
+	Logger           logr.Logger
+	K8sClient        client.Client
+	Adapter          Adapter
+	Events           *events.Recorder
+	DeletionPolicy   v1alpha1.DeletionPolicy
+	Paused           bool
+	Observe          bool
+	ResyncInterval   time.Duration
+	conditionManager condition.Conditions
+	credentialsErr   error
+}
+
+func (e *SubroutineHandler) ReconcileCreation() (pd_utils.OperationResult, error) {
+	e.Logger.Info("Reconcile Schedule Creation...")
+
+	// no upstream schedule has been created yet
+	if !e.scheduleIDExists() {
+		e.Logger.Info("Upstream Schedule not found. Creating...")
+
+		scheduleID, err := e.Adapter.CreateSchedule(&e.Schedule.Spec)
+		if err != nil {
+			e.Logger.Error(err, "Failed to create PD Schedule")
+			e.Events.Failed("Create", err)
+			return e.SetScheduleCondition(v1alpha1.ConditionReady, scheduleReady, err, err.Error())
+		}
+
+		e.Logger.Info("Updating Schedule status...", "scheduleID", scheduleID)
+		e.Schedule.Status.ScheduleID = scheduleID
+		e.Events.Created(scheduleID)
+		return e.SetScheduleCondition(v1alpha1.ConditionReady, scheduleReady, nil, "Schedule created")
+	}
+
+	return pd_utils.ContinueProcessing()
+}
+
+func (e *SubroutineHandler) ReconcileDeletion() (pd_utils.OperationResult, error) {
+	e.Logger.Info("Reconcile Schedule Deletion...")
+
+	if e.deletionTimestampExists() {
+		e.Logger.Info("Deletion timestamp for Schedule found. Deleting...")
+
+		// a paused upstream object is only released untouched, any other policy waits for the pause to end
+		if e.Paused && e.DeletionPolicy != v1alpha1.DeletionPolicyOrphan && e.scheduleIDExists() {
+			return e.status().BlockPausedDeletion(e.DeletionPolicy)
+		}
+
+		if e.scheduleIDExists() {
+			err := e.deleteUpstream()
+			if pd_errors.IsNotFound(err) {
+				e.Logger.Info("Upstream Schedule already deleted...", "id", e.Schedule.Status.ScheduleID)
+				err = nil
+			}
+			if err != nil {
+				e.Logger.Error(err, "Failed to delete Schedule")
+				e.Events.Failed("Delete", err)
+				return e.SetScheduleCondition(v1alpha1.ConditionReady, scheduleReady, err, err.Error())
+			}
+		}
+
+		err := e.removeFinalizer()
+		return pd_utils.RequeueOnErrorOrStop(err)
+	}
+
+	e.Logger.Info("No deletion timestamp found for Schedule. Skipping deletion...")
+	return pd_utils.ContinueProcessing()
+}
+
+// deleteUpstream applies the deletion policy to the upstream Schedule
+func (e *SubroutineHandler) deleteUpstream() error {
+	scheduleID := e.Schedule.Status.ScheduleID
+
+	switch e.DeletionPolicy {
+	case v1alpha1.DeletionPolicyOrphan:
+		e.Logger.Info("Deletion policy is Orphan, leaving upstream Schedule untouched...", "scheduleID", scheduleID)
+		e.Events.Orphaned(scheduleID)
+		return nil
+	case v1alpha1.DeletionPolicyRetain:
+		e.Logger.Info("Deletion policy is Retain, renaming upstream Schedule...", "scheduleID", scheduleID)
+		if err := e.Adapter.RetainSchedule(scheduleID); err != nil {
+			return err
+		}
+		e.Events.Retained(scheduleID)
+		return nil
+	default:
+		e.Logger.Info("Upstream Schedule found, making API deletion call for Schedule ...")
+		if err := e.Adapter.DeleteSchedule(scheduleID); err != nil {
+			return err
+		}
+		e.Events.Deleted(scheduleID)
+		return nil
+	}
+}
+
+func (e *SubroutineHandler) ReconcileUpdate() (pd_utils.OperationResult, error) {
+	e.Logger.Info("Reconcile Schedule Update...")
+
+	if !e.scheduleIDExists() {
+		e.Logger.Info("No upstream Schedule created yet. Skipping Update...")
+		return pd_utils.ContinueProcessing()
+	}
+
+	drifted, err := e.Adapter.UpstreamDrift(*e.Schedule)
+	if pd_errors.IsNotFound(err) {
+		return e.ForgetUpstream()
+	}
+	if err != nil {
+		e.Logger.Error(err, "Failed to compare Schedule spec with upstream")
+		e.Events.Failed("Compare", err)
+		return e.SetScheduleCondition(v1alpha1.ConditionReady, scheduleReady, err, err.Error())
+	}
+
+	if len(drifted) > 0 {
+		e.Logger.Info("Schedule spec does not match upstream schedule. Updating...", "driftedFields", drifted.Fields())
+		err := e.Adapter.UpdateSchedule(e.Schedule)
+		if pd_errors.IsNotFound(err) {
+			return e.ForgetUpstream()
+		}
+		if err != nil {
+			e.Logger.Error(err, "Failed to update Schedule")
+			e.Events.Failed("Update", err)
+			return e.SetScheduleCondition(v1alpha1.ConditionReady, scheduleReady, err, err.Error())
+		}
+
+		e.Logger.Info("Schedule changed...")
+		e.Events.Updated(e.Schedule.Status.ScheduleID, drifted.Fields())
+		pd_metrics.DriftCorrected("Schedule", e.Schedule.Namespace)
+		// read again by MarkSynced
+		e.Schedule.Status.Upstream = nil
+		return e.SetScheduleCondition(v1alpha1.ConditionReady, scheduleReady, nil, "PagerDuty Schedule matches upstream schedule")
+	}
+
+	e.Logger.Info("PagerDuty Schedule not changed, Reconcile Update PagerDuty Schedule done...")
+	return pd_utils.ContinueProcessing()
+}
+
+// CheckPaused ends the reconcile of a paused Schedule before any PagerDuty API call
+func (e *SubroutineHandler) CheckPaused() (pd_utils.OperationResult, error) {
+	return e.status().CheckPaused(e.Paused)
+}
+
+// ObserveUpstream compares the Schedule with upstream in observe mode, without changing PagerDuty.
+// The drift is reported in the DriftDetected condition, and the reconcile ends there.
+func (e *SubroutineHandler) ObserveUpstream() (pd_utils.OperationResult, error) {
+	if !e.Observe {
+		return pd_utils.ContinueProcessing()
+	}
+	e.Logger.Info("Observe mode, comparing Schedule with upstream...")
+
+	if !e.scheduleIDExists() {
+		return e.status().SetDrift(false, nil)
+	}
+
+	drifted, err := e.Adapter.UpstreamDrift(*e.Schedule)
+	if pd_errors.IsNotFound(err) {
+		e.Logger.Info("Observed upstream Schedule not found...", "id", e.Schedule.Status.ScheduleID)
+		e.Schedule.Status.ScheduleID = ""
+		return e.status().SetDrift(false, nil)
+	}
+	if err != nil {
+		e.Logger.Error(err, "Failed to compare Schedule spec with upstream")
+		e.Events.Failed("Compare", err)
+		return e.SetScheduleCondition(v1alpha1.ConditionReady, scheduleReady, err, err.Error())
+	}
+
+	return e.status().SetDrift(true, drifted)
+}
+
+func (e *SubroutineHandler) scheduleIDExists() bool {
+	return e.Schedule.Status.ScheduleID != ""
+}
+
+func (e *SubroutineHandler) deletionTimestampExists() bool {
+	return !e.Schedule.GetDeletionTimestamp().IsZero()
+}
+
+func (e *SubroutineHandler) AddFinalizer() (pd_utils.OperationResult, error) {
+	if !controllerutil.ContainsFinalizer(e.Schedule, scheduleFinalizer) {
+		e.Logger.Info("Adding Finalizer for Schedule")
+		if ok := controllerutil.AddFinalizer(e.Schedule, scheduleFinalizer); !ok {
+			e.Logger.Info("Could not add finalizer for Schedule")
+			return pd_utils.Requeue()
+		}
+
+		err := e.K8sClient.Update(context.Background(), e.Schedule)
+		return pd_utils.RequeueOnErrorOrStop(err)
+	}
+
+	return pd_utils.ContinueProcessing()
+}
+
+func (e *SubroutineHandler) removeFinalizer() error {
+	if !controllerutil.ContainsFinalizer(e.Schedule, scheduleFinalizer) {
+		e.Logger.Info("No Finalizer present in Schedule, skipping finalizer removal...")
+		return nil
+	}
+
+	e.Logger.Info("Removing Finalizer for Schedule after successfully perform the operations")
+	if ok := controllerutil.RemoveFinalizer(e.Schedule, scheduleFinalizer); !ok {
+		e.Logger.Info("Failed to remove finalizer for Schedule")
+		return errors.New("failed to remove finalizer for Schedule")
+	}
+
+	return e.K8sClient.Update(context.TODO(), e.Schedule)
+}
+
+// status returns the condition subroutines shared with the other reconcilers, applied to the Schedule
+func (e *SubroutineHandler) status() *condition.Status {
+	return &condition.Status{
+		Kind:         "Schedule",
+		Object:       e.Schedule,
+		Conditions:   &e.Schedule.Status.Conditions,
+		Logger:       e.Logger,
+		Events:       e.Events,
+		Update:       e.StatusUpdate,
+		RequeueDelay: RequeWaitTime,
+	}
+}
+
+func (e *SubroutineHandler) StatusUpdate() error {
+	e.Logger.Info("Updating status...")
+	if err := e.K8sClient.Status().Update(context.TODO(), e.Schedule); err != nil {
+		e.Logger.Error(err, "Failed to update Schedule status")
+		return pd_errors.Wrap(err, fmt.Sprintf("failed to update Schedule state for %s", e.Schedule.Name))
+	}
+
+	e.Logger.Info("Status updated...")
+	return nil
+}
+
+func (e *SubroutineHandler) SetScheduleCondition(conditionType v1alpha1.ConditionType, reason string, err error, message string) (pd_utils.OperationResult, error) {
+	conditions := &e.Schedule.Status.Conditions
+
+	if err != nil {
+		switch class := pd_errors.Classify(err); class {
+		case pd_errors.Unauthorized:
+			return e.status().SetCredentials(err)
+		case pd_errors.Invalid:
+			// the upstream object still has to be released on deletion, whatever the spec
+			if !e.deletionTimestampExists() {
+				return e.status().SetInvalidSpec(err)
+			}
+		case pd_errors.NotFound, pd_errors.Forbidden, pd_errors.RateLimited:
+			reason = string(class)
+		}
+
+		delay := RequeWaitTime
+		if backoff, ok := pd_errors.Backoff(err); ok {
+			delay = backoff
+		}
+
+		e.Logger.Info("Setting ready condition to false", "conditionType", conditionType, "status", metav1.ConditionFalse, "reason", reason, "message", message, "error", err.Error())
+		e.conditionManager.SetCondition(conditions, conditionType, metav1.ConditionFalse, reason, err.Error())
+		e.status().SetError(reason, err)
+
+		err := e.StatusUpdate()
+		if err != nil {
+			e.Logger.Error(err, "Failed to update Schedule to false ready condition, Requeing in 10 seconds... ")
+		}
+
+		return pd_utils.RequeueAfter(delay, err)
+	}
+
+	if current := e.conditionManager.GetCondition(conditions, conditionType); current != nil && current.Status == metav1.ConditionTrue && current.Message == message {
+		e.status().ClearError()
+		err = e.StatusUpdate()
+		if err != nil {
+			e.Logger.Error(err, "Failed to update Schedule to true ready condition ")
+			return pd_utils.RequeueAfter(RequeWaitTime, err)
+		}
+		return pd_utils.StopProcessing()
+	}
+
+	e.Logger.Info("Setting ready condition to true", "conditionType", conditionType, "status", metav1.ConditionTrue, "reason", reason, "message", message)
+	e.conditionManager.SetCondition(conditions, conditionType, metav1.ConditionTrue, reason, message)
+	e.status().ClearError()
+	err = e.StatusUpdate()
+
+	return pd_utils.RequeueOnErrorOrStop(err)
+}
+
+// CheckInvalidSpec ends the reconcile of a Schedule whose spec PagerDuty rejected, before any PagerDuty API call
+func (e *SubroutineHandler) CheckInvalidSpec() (pd_utils.OperationResult, error) {
+	return e.status().CheckInvalidSpec()
+}
+
+// ForgetUpstream drops the upstream Schedule deleted outside the operator from the status,
+// so the next reconcile creates it again
+func (e *SubroutineHandler) ForgetUpstream() (pd_utils.OperationResult, error) {
+	status := &e.Schedule.Status
+
+	return e.status().ForgetUpstream(status.ScheduleID, func() {
+		status.ScheduleID = ""
+		status.HTMLURL = ""
+		status.Upstream = nil
+	})
+}
+
+func (e *SubroutineHandler) Initialization() (pd_utils.OperationResult, error) {
+	e.Logger.Info("Starting Initialization...")
+	if e.Schedule.Status.Conditions == nil {
+		e.Schedule.Status.Conditions = []metav1.Condition{}
+
+		err := e.StatusUpdate()
+		return pd_utils.RequeueOnErrorOrStop(err)
+	}
+
+	e.Logger.Info("Initialization done...")
+	return pd_utils.ContinueProcessing()
+}
+
+func (e *SubroutineHandler) EnsureCredentials() (pd_utils.OperationResult, error) {
+	e.Logger.Info("Ensuring PagerDuty credentials...")
+	return e.status().SetCredentials(e.credentialsErr)
+}
+
+// MarkSynced records the successful comparison with upstream of the Schedule
+func (e *SubroutineHandler) MarkSynced() (pd_utils.OperationResult, error) {
+	if !e.scheduleIDExists() {
+		return pd_utils.ContinueProcessing()
+	}
+
+	status := &e.Schedule.Status
+
+	if e.status().SyncDue(status.Upstream != nil, status.ObservedGeneration, status.LastSyncedTime, e.ResyncInterval) {
+		e.Logger.Info("Recording Schedule sync with upstream...")
+		PDSchedule, err := e.Adapter.GetSchedule(status.ScheduleID)
+		if pd_errors.IsNotFound(err) {
+			return e.ForgetUpstream()
+		}
+		if err != nil {
+			e.Logger.Error(err, "Failed to read upstream Schedule")
+			e.Events.Failed("Get", err)
+			return e.SetScheduleCondition(v1alpha1.ConditionReady, scheduleReady, err, err.Error())
+		}
+
+		status.HTMLURL = PDSchedule.HTMLURL
+		status.Upstream = scheduleSnapshot(PDSchedule)
+		return e.status().MarkSynced(&status.ObservedGeneration, &status.LastSyncedTime)
+	}
+
+	return pd_utils.ContinueProcessing()
+}
@@ -0,0 +1,249 @@
+package team
+
+import (
+	"context"
+	"sort"
+
+	"github.com/PagerDuty/go-pagerduty"
+	"github.com/go-logr/logr"
+	"gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/observe"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_metrics"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_utils"
+)
+
+type TeamAdapter struct {
+	// Ctx is the context of the reconcile, cancelling it cancels the API calls of the adapter
+	Ctx       context.Context
+	Logger    logr.Logger
+	PD_Client *pagerduty.Client
+}
+
+type Adapter interface {
+	CreateTeam(*v1alpha1.TeamSpec) (string, error)
+	GetTeam(string) (*pagerduty.Team, error)
+	DeleteTeam(string) error
+	UpdateTeam(*v1alpha1.Team) error
+	UpstreamDrift(v1alpha1.Team) (observe.Diff, error)
+	RetainTeam(string) error
+	GetMemberships(string) ([]v1alpha1.TeamMembership, error)
+	AddMembership(string, v1alpha1.TeamMembership) error
+	RemoveMembership(string, string) error
+}
+
+// AdapterFactory builds the Adapter used by a single reconcile on top of the given PagerDuty client
+type AdapterFactory func(ctx context.Context, logger logr.Logger, pdClient *pagerduty.Client) Adapter
+
+// NewTeamAdapter is the AdapterFactory that talks to the PagerDuty API
+func NewTeamAdapter(ctx context.Context, logger logr.Logger, pdClient *pagerduty.Client) Adapter {
+	return &TeamAdapter{
+		Ctx:       ctx,
+		Logger:    logger,
+		PD_Client: pdClient,
+	}
+}
+
+var team_type = "team"
+var team_reference_type = "team_reference"
+
+// convertSpec builds the upstream team. The parent has to be resolved into ParentID beforehand.
+func convertSpec(spec *v1alpha1.TeamSpec) *pagerduty.Team {
+	team := &pagerduty.Team{
+		APIObject: pagerduty.APIObject{
+			Type: team_type,
+		},
+		Name:        spec.Name,
+		Description: spec.Description,
+	}
+
+	if spec.ParentID != "" {
+		team.Parent = &pagerduty.APIObject{
+			ID:   spec.ParentID,
+			Type: team_reference_type,
+		}
+	}
+	return team
+}
+
+func (adapter *TeamAdapter) CreateTeam(spec *v1alpha1.TeamSpec) (string, error) {
+	res, err := adapter.PD_Client.CreateTeamWithContext(pd_metrics.Method(adapter.Ctx, "CreateTeam"), convertSpec(spec))
+	if err != nil {
+		adapter.Logger.Error(err, "Team creation unsuccessfull...")
+		return "", err
+	}
+
+	return res.ID, nil
+}
+
+func (adapter *TeamAdapter) DeleteTeam(id string) error {
+	adapter.Logger.Info("Deleting team...")
+
+	err := adapter.PD_Client.DeleteTeamWithContext(pd_metrics.Method(adapter.Ctx, "DeleteTeam"), id)
+	if err != nil {
+		adapter.Logger.Error(err, "ERROR: Failed to delete Team")
+		return err
+	}
+
+	adapter.Logger.Info("Team deleted...")
+	return nil
+}
+
+// RetainTeam renames the upstream team with the retained suffix instead of deleting it
+func (adapter *TeamAdapter) RetainTeam(id string) error {
+	adapter.Logger.Info("Retaining team...")
+
+	PDTeam, err := adapter.GetTeam(id)
+	if err != nil {
+		return err
+	}
+
+	PDTeam.Name = pd_utils.RetainedName(PDTeam.Name)
+	if _, err := adapter.PD_Client.UpdateTeamWithContext(pd_metrics.Method(adapter.Ctx, "RetainTeam"), id, PDTeam); err != nil {
+		adapter.Logger.Error(err, "API Failed to rename retained Team")
+		return err
+	}
+
+	adapter.Logger.Info("Team retained...", "name", PDTeam.Name)
+	return nil
+}
+
+func (adapter *TeamAdapter) UpdateTeam(k8sTeam *v1alpha1.Team) error {
+	adapter.Logger.Info("Updating team...")
+
+	_, err := adapter.PD_Client.UpdateTeamWithContext(pd_metrics.Method(adapter.Ctx, "UpdateTeam"), k8sTeam.Status.TeamID, convertSpec(&k8sTeam.Spec))
+	if err != nil {
+		adapter.Logger.Error(err, "API Failed to update Team")
+		return err
+	}
+
+	adapter.Logger.Info("Upstream Team updated...")
+	return nil
+}
+
+// UpstreamDrift returns the spec fields whose value differs from the upstream team
+func (adapter *TeamAdapter) UpstreamDrift(k8sTeam v1alpha1.Team) (observe.Diff, error) {
+	PDTeam, err := adapter.GetTeam(k8sTeam.Status.TeamID)
+	if err != nil {
+		adapter.Logger.Error(err, "Failed to get Team")
+		return nil, err
+	}
+
+	return teamDiff(&k8sTeam.Spec, PDTeam), nil
+}
+
+func (adapter *TeamAdapter) GetTeam(id string) (*pagerduty.Team, error) {
+	PDTeam, err := adapter.PD_Client.GetTeamWithContext(pd_metrics.Method(adapter.Ctx, "GetTeam"), id)
+	if err != nil {
+		adapter.Logger.Error(err, "Failed to get Team")
+		return nil, err
+	}
+
+	adapter.Logger.Info("Team retrieved", "PDTeam", PDTeam.ID)
+	return PDTeam, nil
+}
+
+func (adapter *TeamAdapter) GetMemberships(teamID string) ([]v1alpha1.TeamMembership, error) {
+	members, err := adapter.PD_Client.ListTeamMembersPaginated(pd_metrics.Method(adapter.Ctx, "GetMemberships"), teamID)
+	if err != nil {
+		adapter.Logger.Error(err, "Failed to list Team members")
+		return nil, err
+	}
+
+	memberships := make([]v1alpha1.TeamMembership, len(members))
+	for i, member := range members {
+		memberships[i] = v1alpha1.TeamMembership{UserID: member.User.ID, Role: member.Role}
+	}
+	return sortMemberships(memberships), nil
+}
+
+// AddMembership adds the user to the team, or changes its role if it already is a member
+func (adapter *TeamAdapter) AddMembership(teamID string, membership v1alpha1.TeamMembership) error {
+	adapter.Logger.Info("Adding Team membership...", "userID", membership.UserID, "role", membership.Role)
+
+	err := adapter.PD_Client.AddUserToTeamWithContext(pd_metrics.Method(adapter.Ctx, "AddMembership"), pagerduty.AddUserToTeamOptions{
+		TeamID: teamID,
+		UserID: membership.UserID,
+		Role:   pagerduty.TeamUserRole(membership.Role),
+	})
+	if err != nil {
+		adapter.Logger.Error(err, "API Failed to add Team membership")
+		return err
+	}
+
+	return nil
+}
+
+func (adapter *TeamAdapter) RemoveMembership(teamID string, userID string) error {
+	adapter.Logger.Info("Removing Team membership...", "userID", userID)
+
+	err := adapter.PD_Client.RemoveUserFromTeamWithContext(pd_metrics.Method(adapter.Ctx, "RemoveMembership"), teamID, userID)
+	if err != nil {
+		adapter.Logger.Error(err, "API Failed to remove Team membership")
+		return err
+	}
+
+	return nil
+}
+
+func teamMatches(spec *v1alpha1.TeamSpec, PDTeam *pagerduty.Team) bool {
+	return len(teamDiff(spec, PDTeam)) == 0
+}
+
+// teamDiff compares the spec with the upstream team field by field. The parent is reported by ID.
+func teamDiff(spec *v1alpha1.TeamSpec, PDTeam *pagerduty.Team) observe.Diff {
+	var diff observe.Diff
+
+	diff.Compare("name", spec.Name, PDTeam.Name)
+	diff.Compare("description", spec.Description, PDTeam.Description)
+
+	PDParentID := ""
+	if PDTeam.Parent != nil {
+		PDParentID = PDTeam.Parent.ID
+	}
+	diff.Compare("parent", spec.ParentID, PDParentID)
+
+	return diff
+}
+
+// diffMemberships returns the memberships missing upstream or with another role upstream,
+// and the IDs of the upstream members that are no longer part of the spec
+func diffMemberships(desired []v1alpha1.TeamMembership, observed []v1alpha1.TeamMembership) ([]v1alpha1.TeamMembership, []string) {
+	wanted := make(map[string]bool, len(desired))
+	for _, membership := range desired {
+		wanted[membership.UserID] = true
+	}
+
+	var toRemove []string
+	existing := make(map[string]string, len(observed))
+	for _, membership := range observed {
+		existing[membership.UserID] = membership.Role
+		if !wanted[membership.UserID] {
+			toRemove = append(toRemove, membership.UserID)
+		}
+	}
+
+	var toAdd []v1alpha1.TeamMembership
+	for _, membership := range desired {
+		if role, ok := existing[membership.UserID]; !ok || role != membership.Role {
+			toAdd = append(toAdd, membership)
+		}
+	}
+
+	return toAdd, toRemove
+}
+
+func sortMemberships(memberships []v1alpha1.TeamMembership) []v1alpha1.TeamMembership {
+	sort.Slice(memberships, func(i, j int) bool {
+		return memberships[i].UserID < memberships[j].UserID
+	})
+	return memberships
+}
+
+// teamSnapshot returns the managed fields of the upstream team
+func teamSnapshot(PDTeam *pagerduty.Team) *v1alpha1.UpstreamSnapshot {
+	snapshot := &v1alpha1.UpstreamSnapshot{Name: PDTeam.Name, Description: PDTeam.Description}
+	if PDTeam.Parent != nil {
+		snapshot.SetField("parent", PDTeam.Parent.ID)
+	}
+	return snapshot
+}
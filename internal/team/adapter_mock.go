@@ -0,0 +1,108 @@
+package team
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/PagerDuty/go-pagerduty"
+	"github.com/go-logr/logr"
+	"gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/observe"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_utils"
+)
+
+type TeamMockAdapter struct {
+	Logger logr.Logger
+}
+
+var Default_team_name = "default-team"
+var Default_team_description = "default_team_description"
+var Default_member_email = "mock.member@share-now.com"
+
+var teams map[string]pagerduty.Team = make(map[string]pagerduty.Team)
+var teamMemberships map[string][]v1alpha1.TeamMembership = make(map[string][]v1alpha1.TeamMembership)
+var userIDs map[string]string = map[string]string{Default_member_email: "MOCKMEMBERID"}
+
+func (adapter *TeamMockAdapter) CreateTeam(spec *v1alpha1.TeamSpec) (string, error) {
+	adapter.Logger.Info("Team created...")
+	teams[spec.Name] = *convertSpec(spec)
+
+	return spec.Name, nil
+}
+
+func (adapter *TeamMockAdapter) DeleteTeam(id string) error {
+	delete(teams, id)
+	delete(teamMemberships, id)
+
+	adapter.Logger.Info("Team deleted...")
+	return nil
+}
+
+func (adapter *TeamMockAdapter) RetainTeam(id string) error {
+	team, ok := teams[id]
+	if !ok {
+		return fmt.Errorf("team not found")
+	}
+
+	team.Name = pd_utils.RetainedName(team.Name)
+	teams[id] = team
+
+	adapter.Logger.Info("Team retained...")
+	return nil
+}
+
+func (adapter *TeamMockAdapter) UpdateTeam(k8sTeam *v1alpha1.Team) error {
+	teams[k8sTeam.Status.TeamID] = *convertSpec(&k8sTeam.Spec)
+	adapter.Logger.Info("Upstream Team updated...")
+	return nil
+}
+
+func (adapter *TeamMockAdapter) UpstreamDrift(k8sTeam v1alpha1.Team) (observe.Diff, error) {
+	PDTeam, err := adapter.GetTeam(k8sTeam.Status.TeamID)
+	if err != nil {
+		adapter.Logger.Error(err, "Failed to get Team")
+		return nil, err
+	}
+
+	return teamDiff(&k8sTeam.Spec, PDTeam), nil
+}
+
+func (adapter *TeamMockAdapter) GetTeam(id string) (*pagerduty.Team, error) {
+	team, ok := teams[id]
+	if !ok {
+		return nil, fmt.Errorf("team not found")
+	}
+
+	return &team, nil
+}
+
+func (adapter *TeamMockAdapter) GetMemberships(teamID string) ([]v1alpha1.TeamMembership, error) {
+	memberships := append([]v1alpha1.TeamMembership(nil), teamMemberships[teamID]...)
+	return sortMemberships(memberships), nil
+}
+
+func (adapter *TeamMockAdapter) AddMembership(teamID string, membership v1alpha1.TeamMembership) error {
+	adapter.RemoveMembership(teamID, membership.UserID)
+	teamMemberships[teamID] = append(teamMemberships[teamID], membership)
+
+	adapter.Logger.Info("Team membership added...")
+	return nil
+}
+
+func (adapter *TeamMockAdapter) RemoveMembership(teamID string, userID string) error {
+	remaining := []v1alpha1.TeamMembership{}
+	for _, membership := range teamMemberships[teamID] {
+		if membership.UserID != userID {
+			remaining = append(remaining, membership)
+		}
+	}
+	teamMemberships[teamID] = remaining
+
+	adapter.Logger.Info("Team membership removed...")
+	return nil
+}
+
+// mockUserLookup finds the users of userIDs instead of calling the PagerDuty API
+func mockUserLookup(ctx context.Context, pdClient *pagerduty.Client, email string) (string, error) {
+	return userIDs[email], nil
+}
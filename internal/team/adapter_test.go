@@ -0,0 +1,67 @@
+package team
+
+import (
+	"github.com/PagerDuty/go-pagerduty"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
+)
+
+var _ = Describe("Team adapter", func() {
+
+	Describe("Comparing a Team with upstream", func() {
+		var spec *v1alpha1.TeamSpec
+		var pdTeam *pagerduty.Team
+
+		BeforeEach(func() {
+			spec = &v1alpha1.TeamSpec{
+				Name:        "platform",
+				Description: "Platform engineering",
+				ParentID:    "PPARENT",
+			}
+			pdTeam = &pagerduty.Team{
+				Name:        "platform",
+				Description: "Platform engineering",
+				Parent:      &pagerduty.APIObject{ID: "PPARENT", Type: "team_reference"},
+			}
+		})
+
+		It("should match an identical team", func() {
+			Expect(teamMatches(spec, pdTeam)).To(BeTrue())
+			Expect(teamMatches(spec, convertSpec(spec))).To(BeTrue())
+		})
+
+		It("should detect a changed parent", func() {
+			pdTeam.Parent = nil
+			Expect(teamMatches(spec, pdTeam)).To(BeFalse())
+
+			spec.ParentID = ""
+			Expect(teamMatches(spec, pdTeam)).To(BeTrue())
+		})
+	})
+
+	Describe("Diffing memberships", func() {
+		It("should add missing members, change roles and remove extra members", func() {
+			desired := []v1alpha1.TeamMembership{
+				{UserID: "USERA", Role: "manager"},
+				{UserID: "USERB", Role: "responder"},
+			}
+			observed := []v1alpha1.TeamMembership{
+				{UserID: "USERA", Role: "responder"},
+				{UserID: "USERC", Role: "observer"},
+			}
+
+			toAdd, toRemove := diffMemberships(desired, observed)
+			Expect(toAdd).To(Equal(desired))
+			Expect(toRemove).To(Equal([]string{"USERC"}))
+		})
+
+		It("should not change matching memberships", func() {
+			memberships := []v1alpha1.TeamMembership{{UserID: "USERA", Role: "manager"}}
+
+			toAdd, toRemove := diffMemberships(memberships, memberships)
+			Expect(toAdd).To(BeEmpty())
+			Expect(toRemove).To(BeEmpty())
+		})
+	})
+})
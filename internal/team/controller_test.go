@@ -0,0 +1,182 @@
+package team
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	pagerdutyv1alpha1 "gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_utils"
+	core "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var timeout time.Duration = time.Second * 13
+var interval time.Duration = time.Millisecond * 250
+
+type TestTeamEnv struct {
+	TeamNamespace string
+	Team          *pagerdutyv1alpha1.Team
+}
+
+func setupTest() *TestTeamEnv {
+	teamNamespace := "test-" + pd_utils.RandStr(5)
+
+	err := k8sClient.Create(ctx, &core.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: teamNamespace},
+	})
+
+	Expect(err).NotTo(HaveOccurred(), "failed to create test namespace")
+
+	team := &pagerdutyv1alpha1.Team{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "pagerduty.platform.share-now.com/v1alpha1",
+			Kind:       "Team",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      Default_team_name,
+			Namespace: teamNamespace,
+		},
+		Spec: pagerdutyv1alpha1.TeamSpec{
+			Name:        Default_team_name,
+			Description: Default_team_description,
+			Members: []pagerdutyv1alpha1.TeamMember{
+				{User: "MOCKUSERID", Role: "manager"},
+				{User: "email:" + Default_member_email, Role: "responder"},
+			},
+		},
+	}
+
+	Expect(k8sClient.Create(ctx, team)).Should(Succeed())
+
+	return &TestTeamEnv{
+		TeamNamespace: teamNamespace,
+		Team:          team,
+	}
+}
+
+func cleanUp(testEnv *TestTeamEnv) {
+	err := k8sClient.Delete(ctx, &core.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: testEnv.TeamNamespace},
+	})
+	Expect(err).NotTo(HaveOccurred(), "failed to delete test namespace")
+}
+
+var _ = Describe("Team controller", func() {
+
+	var testEnv *TestTeamEnv
+
+	BeforeEach(func() {
+		testEnv = setupTest()
+	})
+
+	AfterEach(func() {
+		cleanUp(testEnv)
+	})
+
+	getTeamID := func() string {
+		err := k8sClient.Get(
+			context.Background(),
+			types.NamespacedName{Name: Default_team_name, Namespace: testEnv.TeamNamespace},
+			testEnv.Team,
+		)
+		if err != nil {
+			return ""
+		}
+		return testEnv.Team.Status.TeamID
+	}
+
+	getMemberships := func() []pagerdutyv1alpha1.TeamMembership {
+		if getTeamID() == "" {
+			return nil
+		}
+		return testEnv.Team.Status.Memberships
+	}
+
+	Context("When creating a Team", func() {
+		It("Should add the members with their roles", func() {
+			Eventually(getTeamID, timeout, interval).Should(Equal(Default_team_name))
+			Eventually(getMemberships, timeout, interval).Should(Equal([]pagerdutyv1alpha1.TeamMembership{
+				{UserID: "MOCKMEMBERID", Role: "responder"},
+				{UserID: "MOCKUSERID", Role: "manager"},
+			}))
+		})
+	})
+
+	Context("When updating the members of a Team", func() {
+		It("Should change roles and remove the members no longer listed", func() {
+			Eventually(getMemberships, timeout, interval).Should(HaveLen(2))
+
+			testEnv.Team.Spec.Members = []pagerdutyv1alpha1.TeamMember{
+				{User: "email:" + Default_member_email, Role: "observer"},
+			}
+			Expect(k8sClient.Update(ctx, testEnv.Team)).Should(Succeed())
+
+			Eventually(getMemberships, timeout, interval).Should(Equal([]pagerdutyv1alpha1.TeamMembership{
+				{UserID: "MOCKMEMBERID", Role: "observer"},
+			}))
+		})
+	})
+
+	Context("When referencing a parent Team", func() {
+		const childName = "child-team"
+
+		It("Should stay Pending until the parent is created upstream", func() {
+			child := &pagerdutyv1alpha1.Team{
+				ObjectMeta: metav1.ObjectMeta{Name: childName, Namespace: testEnv.TeamNamespace},
+				Spec: pagerdutyv1alpha1.TeamSpec{
+					Name:      childName,
+					ParentRef: "missing-team",
+				},
+			}
+			Expect(k8sClient.Create(ctx, child)).Should(Succeed())
+
+			childKey := types.NamespacedName{Name: childName, Namespace: testEnv.TeamNamespace}
+			Eventually(func() metav1.ConditionStatus {
+				if err := k8sClient.Get(ctx, childKey, child); err != nil {
+					return ""
+				}
+				for _, condition := range child.Status.Conditions {
+					if condition.Type == pagerdutyv1alpha1.ConditionPending.String() {
+						return condition.Status
+					}
+				}
+				return ""
+			}, timeout, interval).Should(Equal(metav1.ConditionTrue))
+			Expect(child.Status.TeamID).Should(BeEmpty())
+
+			child.Spec.ParentRef = Default_team_name
+			Expect(k8sClient.Update(ctx, child)).Should(Succeed())
+
+			Eventually(func() string {
+				upstream, ok := teams[childName]
+				if !ok || upstream.Parent == nil {
+					return ""
+				}
+				return upstream.Parent.ID
+			}, timeout, interval).Should(Equal(Default_team_name))
+		})
+	})
+
+	Context("When deleting a Team with the Retain policy", func() {
+		It("Should rename the upstream team instead of deleting it", func() {
+			Eventually(getTeamID, timeout, interval).Should(Equal(Default_team_name))
+
+			testEnv.Team.Spec.DeletionPolicy = pagerdutyv1alpha1.DeletionPolicyRetain
+			Expect(k8sClient.Update(ctx, testEnv.Team)).Should(Succeed())
+			Expect(k8sClient.Delete(ctx, testEnv.Team)).Should(Succeed())
+
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, types.NamespacedName{Name: Default_team_name, Namespace: testEnv.TeamNamespace}, testEnv.Team)
+				return apierrors.IsNotFound(err)
+			}, timeout, interval).Should(BeTrue())
+			Expect(teams).Should(HaveKey(Default_team_name))
+			Expect(teams[Default_team_name].Name).Should(Equal(Default_team_name + pd_utils.RetainedNameSuffix))
+			delete(teams, Default_team_name)
+			delete(teamMemberships, Default_team_name)
+		})
+	})
+})
@@ -0,0 +1,29 @@
+package team
+
+import (
+	"context"
+	"fmt"
+
+	"gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// TeamID returns the upstream ID of the referenced Team custom resource. The ID is empty while the Team
+// does not exist or has not been created in PagerDuty yet. Teams of another account cannot be referenced.
+func TeamID(ctx context.Context, k8sClient client.Client, key types.NamespacedName, accountRef string) (string, error) {
+	team := &v1alpha1.Team{}
+	if err := k8sClient.Get(ctx, key, team); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	if team.Spec.AccountRef != accountRef {
+		return "", fmt.Errorf("team %s uses account %q but the referencing resource uses account %q",
+			team.Name, team.Spec.AccountRef, accountRef)
+	}
+	return team.Status.TeamID, nil
+}
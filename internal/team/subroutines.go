@@ -0,0 +1,544 @@
+package team
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/PagerDuty/go-pagerduty"
+	"github.com/go-logr/logr"
+	"gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/condition"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/events"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_errors"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_metrics"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_utils"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/typeinfo"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const teamFinalizer = "pagerduty.platform.share-now.com/team"
+const teamReady = "PDTeamReady"
+const teamReferencesUnresolved = "ReferencesUnresolved"
+const teamReferencesResolved = "ReferencesResolved"
+const RequeWaitTime = time.Second * 10
+
+type SubroutineHandler struct {
+	Team      *v1alpha1.Team
+	Logger    logr.Logger
+	K8sClient client.Client
+	Adapter   Adapter
+	// PDClient looks users up by email through the UserResolver
+	PDClient       *pagerduty.Client
+	UserResolver   *typeinfo.UserResolver
+	Events         *events.Recorder
+	DeletionPolicy v1alpha1.DeletionPolicy
+	Paused         bool
+	Observe        bool
+	ResyncInterval time.Duration
+	// ctx is the context of the reconcile, it cancels the user lookups
+	ctx                 context.Context
+	conditionManager    condition.Conditions
+	credentialsErr      error
+	resolvedParentID    string
+	resolvedMemberships []v1alpha1.TeamMembership
+}
+
+func (e *SubroutineHandler) ReconcileCreation() (pd_utils.OperationResult, error) {
+	e.Logger.Info("Reconcile Team Creation...")
+
+	// no upstream team has been created yet
+	if !e.teamIDExists() {
+		e.Logger.Info("Upstream Team not found. Creating...")
+
+		teamID, err := e.Adapter.CreateTeam(&e.resolvedTeam().Spec)
+		if err != nil {
+			e.Logger.Error(err, "Failed to create PD Team")
+			e.Events.Failed("Create", err)
+			return e.SetTeamCondition(v1alpha1.ConditionReady, teamReady, err, err.Error())
+		}
+
+		e.Logger.Info("Updating Team status...", "teamID", teamID)
+		e.Team.Status.TeamID = teamID
+		e.Events.Created(teamID)
+		return e.SetTeamCondition(v1alpha1.ConditionReady, teamReady, nil, "Team created")
+	}
+
+	return pd_utils.ContinueProcessing()
+}
+
+func (e *SubroutineHandler) ReconcileDeletion() (pd_utils.OperationResult, error) {
+	e.Logger.Info("Reconcile Team Deletion...")
+
+	if e.deletionTimestampExists() {
+		e.Logger.Info("Deletion timestamp for Team found. Deleting...")
+
+		// a paused upstream object is only released untouched, any other policy waits for the pause to end
+		if e.Paused && e.DeletionPolicy != v1alpha1.DeletionPolicyOrphan && e.teamIDExists() {
+			return e.status().BlockPausedDeletion(e.DeletionPolicy)
+		}
+
+		if e.teamIDExists() {
+			err := e.deleteUpstream()
+			if pd_errors.IsNotFound(err) {
+				e.Logger.Info("Upstream Team already deleted...", "id", e.Team.Status.TeamID)
+				err = nil
+			}
+			if err != nil {
+				e.Logger.Error(err, "Failed to delete Team")
+				e.Events.Failed("Delete", err)
+				return e.SetTeamCondition(v1alpha1.ConditionReady, teamReady, err, err.Error())
+			}
+		}
+
+		err := e.removeFinalizer()
+		return pd_utils.RequeueOnErrorOrStop(err)
+	}
+
+	e.Logger.Info("No deletion timestamp found for Team. Skipping deletion...")
+	return pd_utils.ContinueProcessing()
+}
+
+// deleteUpstream applies the deletion policy to the upstream Team
+func (e *SubroutineHandler) deleteUpstream() error {
+	teamID := e.Team.Status.TeamID
+
+	switch e.DeletionPolicy {
+	case v1alpha1.DeletionPolicyOrphan:
+		e.Logger.Info("Deletion policy is Orphan, leaving upstream Team untouched...", "teamID", teamID)
+		e.Events.Orphaned(teamID)
+		return nil
+	case v1alpha1.DeletionPolicyRetain:
+		e.Logger.Info("Deletion policy is Retain, renaming upstream Team...", "teamID", teamID)
+		if err := e.Adapter.RetainTeam(teamID); err != nil {
+			return err
+		}
+		e.Events.Retained(teamID)
+		return nil
+	default:
+		e.Logger.Info("Upstream Team found, making API deletion call for Team ...")
+		if err := e.Adapter.DeleteTeam(teamID); err != nil {
+			return err
+		}
+		e.Events.Deleted(teamID)
+		return nil
+	}
+}
+
+func (e *SubroutineHandler) ReconcileUpdate() (pd_utils.OperationResult, error) {
+	e.Logger.Info("Reconcile Team Update...")
+
+	if !e.teamIDExists() {
+		e.Logger.Info("No upstream Team created yet. Skipping Update...")
+		return pd_utils.ContinueProcessing()
+	}
+
+	drifted, err := e.Adapter.UpstreamDrift(*e.resolvedTeam())
+	if pd_errors.IsNotFound(err) {
+		return e.ForgetUpstream()
+	}
+	if err != nil {
+		e.Logger.Error(err, "Failed to compare Team spec with upstream")
+		e.Events.Failed("Compare", err)
+		return e.SetTeamCondition(v1alpha1.ConditionReady, teamReady, err, err.Error())
+	}
+
+	if len(drifted) > 0 {
+		e.Logger.Info("Team spec does not match upstream team. Updating...", "driftedFields", drifted.Fields())
+		err := e.Adapter.UpdateTeam(e.resolvedTeam())
+		if pd_errors.IsNotFound(err) {
+			return e.ForgetUpstream()
+		}
+		if err != nil {
+			e.Logger.Error(err, "Failed to update Team")
+			e.Events.Failed("Update", err)
+			return e.SetTeamCondition(v1alpha1.ConditionReady, teamReady, err, err.Error())
+		}
+
+		e.Logger.Info("Team changed...")
+		e.Events.Updated(e.Team.Status.TeamID, drifted.Fields())
+		pd_metrics.DriftCorrected("Team", e.Team.Namespace)
+		// read again by MarkSynced
+		e.Team.Status.Upstream = nil
+		return e.SetTeamCondition(v1alpha1.ConditionReady, teamReady, nil, "PagerDuty Team matches upstream team")
+	}
+
+	e.Logger.Info("PagerDuty Team not changed, Reconcile Update PagerDuty Team done...")
+	return pd_utils.ContinueProcessing()
+}
+
+// ReconcileMemberships adds the members of the spec to the upstream Team, updates their roles
+// and removes the members that are no longer part of the spec
+func (e *SubroutineHandler) ReconcileMemberships() (pd_utils.OperationResult, error) {
+	e.Logger.Info("Reconcile Team memberships...")
+
+	if !e.teamIDExists() {
+		e.Logger.Info("No upstream Team created yet. Skipping memberships...")
+		return pd_utils.ContinueProcessing()
+	}
+
+	teamID := e.Team.Status.TeamID
+	observed, err := e.Adapter.GetMemberships(teamID)
+	if err != nil {
+		e.Logger.Error(err, "Failed to get upstream Team memberships")
+		return e.SetTeamCondition(v1alpha1.ConditionReady, teamReady, err, err.Error())
+	}
+
+	toAdd, toRemove := diffMemberships(e.resolvedMemberships, observed)
+	if len(toAdd) == 0 && len(toRemove) == 0 {
+		if reflect.DeepEqual(observed, e.Team.Status.Memberships) {
+			e.Logger.Info("Team memberships not changed...")
+			return pd_utils.ContinueProcessing()
+		}
+
+		e.Team.Status.Memberships = observed
+		err := e.StatusUpdate()
+		return pd_utils.RequeueOnErrorOrContinue(err)
+	}
+
+	for _, userID := range toRemove {
+		if err := e.Adapter.RemoveMembership(teamID, userID); err != nil {
+			e.Logger.Error(err, "Failed to remove Team membership", "userID", userID)
+			e.Events.Warning("MembershipFailed", "Failed to remove user %s from PagerDuty Team %s: %s", userID, teamID, events.DescribeError(err))
+			return e.SetTeamCondition(v1alpha1.ConditionReady, teamReady, err, err.Error())
+		}
+	}
+
+	for _, membership := range toAdd {
+		if err := e.Adapter.AddMembership(teamID, membership); err != nil {
+			e.Logger.Error(err, "Failed to add Team membership", "userID", membership.UserID)
+			e.Events.Warning("MembershipFailed", "Failed to add user %s to PagerDuty Team %s: %s", membership.UserID, teamID, events.DescribeError(err))
+			return e.SetTeamCondition(v1alpha1.ConditionReady, teamReady, err, err.Error())
+		}
+	}
+
+	e.Events.Normal("MembershipsUpdated", "Updated the members of PagerDuty Team %s: %d added or changed, %d removed", teamID, len(toAdd), len(toRemove))
+
+	observed, err = e.Adapter.GetMemberships(teamID)
+	if err != nil {
+		e.Logger.Error(err, "Failed to get upstream Team memberships")
+		return e.SetTeamCondition(v1alpha1.ConditionReady, teamReady, err, err.Error())
+	}
+
+	e.Team.Status.Memberships = observed
+	return e.SetTeamCondition(v1alpha1.ConditionReady, teamReady, nil, "Team memberships updated")
+}
+
+// ResolveReferences resolves the parent Team reference and the member emails to PagerDuty IDs.
+// The Team stays pending, and is not sent upstream, until all of them are resolved.
+func (e *SubroutineHandler) ResolveReferences() (pd_utils.OperationResult, error) {
+	e.Logger.Info("Resolving Team references...")
+
+	var unresolved []string
+
+	e.resolvedParentID = e.Team.Spec.ParentID
+	if e.Team.Spec.ParentRef != "" {
+		parentID, err := TeamID(context.TODO(), e.K8sClient, types.NamespacedName{
+			Name:      e.Team.Spec.ParentRef,
+			Namespace: e.Team.Namespace,
+		}, e.Team.Spec.AccountRef)
+		if err != nil {
+			e.Logger.Error(err, "Failed to resolve parent Team", "parentRef", e.Team.Spec.ParentRef)
+			return e.SetTeamCondition(v1alpha1.ConditionReady, teamReady, err, err.Error())
+		}
+		if parentID == "" {
+			unresolved = append(unresolved, "Team "+e.Team.Spec.ParentRef)
+		}
+		e.resolvedParentID = parentID
+	}
+
+	// a user listed more than once keeps the role of its last entry
+	roles := map[string]string{}
+	var userIDs []string
+	for _, member := range e.Team.Spec.Members {
+		userID := member.User
+		if email, ok := typeinfo.ParseEmailSelector(member.User); ok {
+			var err error
+			userID, err = e.UserResolver.Resolve(pd_metrics.Method(e.ctx, "FindUserByEmail"), e.PDClient, e.Team.Spec.AccountRef, email)
+			if err != nil {
+				e.Logger.Error(err, "Failed to look up Team member", "email", email)
+				return e.SetTeamCondition(v1alpha1.ConditionReady, teamReady, err, err.Error())
+			}
+			if userID == "" {
+				unresolved = append(unresolved, "user "+email)
+				continue
+			}
+		}
+
+		if _, ok := roles[userID]; !ok {
+			userIDs = append(userIDs, userID)
+		}
+		roles[userID] = member.Role
+	}
+
+	if len(unresolved) > 0 {
+		return e.SetPendingCondition(fmt.Sprintf("Waiting for Team references: %s", strings.Join(unresolved, ", ")))
+	}
+
+	e.resolvedMemberships = make([]v1alpha1.TeamMembership, len(userIDs))
+	for i, userID := range userIDs {
+		e.resolvedMemberships[i] = v1alpha1.TeamMembership{UserID: userID, Role: roles[userID]}
+	}
+	sortMemberships(e.resolvedMemberships)
+
+	return e.ClearPendingCondition()
+}
+
+// resolvedTeam returns a copy of the Team whose parent is referenced by its PagerDuty ID
+func (e *SubroutineHandler) resolvedTeam() *v1alpha1.Team {
+	team := e.Team.DeepCopy()
+	team.Spec.ParentID = e.resolvedParentID
+	return team
+}
+
+// SetPendingCondition marks the Team as waiting for its references and requeues it
+func (e *SubroutineHandler) SetPendingCondition(message string) (pd_utils.OperationResult, error) {
+	conditions := &e.Team.Status.Conditions
+
+	e.conditionManager.SetCondition(conditions, v1alpha1.ConditionPending, metav1.ConditionTrue, teamReferencesUnresolved, message)
+	err := e.StatusUpdate()
+	if err != nil {
+		e.Logger.Error(err, "Failed to update Team pending condition")
+	}
+
+	return pd_utils.RequeueAfter(RequeWaitTime, err)
+}
+
+// ClearPendingCondition flips the pending condition to false once every reference is resolved
+func (e *SubroutineHandler) ClearPendingCondition() (pd_utils.OperationResult, error) {
+	conditions := &e.Team.Status.Conditions
+
+	current := e.conditionManager.GetCondition(conditions, v1alpha1.ConditionPending)
+	if current == nil || current.Status == metav1.ConditionFalse {
+		return pd_utils.ContinueProcessing()
+	}
+
+	e.conditionManager.SetCondition(conditions, v1alpha1.ConditionPending, metav1.ConditionFalse, teamReferencesResolved, "All Team references resolved")
+	err := e.StatusUpdate()
+
+	return pd_utils.RequeueOnErrorOrContinue(err)
+}
+
+// CheckPaused ends the reconcile of a paused Team before any PagerDuty API call
+func (e *SubroutineHandler) CheckPaused() (pd_utils.OperationResult, error) {
+	return e.status().CheckPaused(e.Paused)
+}
+
+// ObserveUpstream compares the Team with upstream in observe mode, without changing PagerDuty.
+// The drift is reported in the DriftDetected condition, and the reconcile ends there.
+func (e *SubroutineHandler) ObserveUpstream() (pd_utils.OperationResult, error) {
+	if !e.Observe {
+		return pd_utils.ContinueProcessing()
+	}
+	e.Logger.Info("Observe mode, comparing Team with upstream...")
+
+	if !e.teamIDExists() {
+		return e.status().SetDrift(false, nil)
+	}
+
+	drifted, err := e.Adapter.UpstreamDrift(*e.resolvedTeam())
+	if pd_errors.IsNotFound(err) {
+		e.Logger.Info("Observed upstream Team not found...", "id", e.Team.Status.TeamID)
+		e.Team.Status.TeamID = ""
+		return e.status().SetDrift(false, nil)
+	}
+	if err != nil {
+		e.Logger.Error(err, "Failed to compare Team spec with upstream")
+		e.Events.Failed("Compare", err)
+		return e.SetTeamCondition(v1alpha1.ConditionReady, teamReady, err, err.Error())
+	}
+
+	observed, err := e.Adapter.GetMemberships(e.Team.Status.TeamID)
+	if err != nil {
+		e.Logger.Error(err, "Failed to get upstream Team memberships")
+		return e.SetTeamCondition(v1alpha1.ConditionReady, teamReady, err, err.Error())
+	}
+	if toAdd, toRemove := diffMemberships(e.resolvedMemberships, observed); len(toAdd) > 0 || len(toRemove) > 0 {
+		drifted.Add("members", e.resolvedMemberships, observed)
+	}
+
+	return e.status().SetDrift(true, drifted)
+}
+
+func (e *SubroutineHandler) teamIDExists() bool {
+	return e.Team.Status.TeamID != ""
+}
+
+func (e *SubroutineHandler) deletionTimestampExists() bool {
+	return !e.Team.GetDeletionTimestamp().IsZero()
+}
+
+func (e *SubroutineHandler) AddFinalizer() (pd_utils.OperationResult, error) {
+	if !controllerutil.ContainsFinalizer(e.Team, teamFinalizer) {
+		e.Logger.Info("Adding Finalizer for Team")
+		if ok := controllerutil.AddFinalizer(e.Team, teamFinalizer); !ok {
+			e.Logger.Info("Could not add finalizer for Team")
+			return pd_utils.Requeue()
+		}
+
+		err := e.K8sClient.Update(context.Background(), e.Team)
+		return pd_utils.RequeueOnErrorOrStop(err)
+	}
+
+	return pd_utils.ContinueProcessing()
+}
+
+func (e *SubroutineHandler) removeFinalizer() error {
+	if !controllerutil.ContainsFinalizer(e.Team, teamFinalizer) {
+		e.Logger.Info("No Finalizer present in Team, skipping finalizer removal...")
+		return nil
+	}
+
+	e.Logger.Info("Removing Finalizer for Team after successfully perform the operations")
+	if ok := controllerutil.RemoveFinalizer(e.Team, teamFinalizer); !ok {
+		e.Logger.Info("Failed to remove finalizer for Team")
+		return errors.New("failed to remove finalizer for Team")
+	}
+
+	return e.K8sClient.Update(context.TODO(), e.Team)
+}
+
+// status returns the condition subroutines shared with the other reconcilers, applied to the Team
+func (e *SubroutineHandler) status() *condition.Status {
+	return &condition.Status{
+		Kind:         "Team",
+		Object:       e.Team,
+		Conditions:   &e.Team.Status.Conditions,
+		Logger:       e.Logger,
+		Events:       e.Events,
+		Update:       e.StatusUpdate,
+		RequeueDelay: RequeWaitTime,
+	}
+}
+
+func (e *SubroutineHandler) StatusUpdate() error {
+	e.Logger.Info("Updating status...")
+	if err := e.K8sClient.Status().Update(context.TODO(), e.Team); err != nil {
+		e.Logger.Error(err, "Failed to update Team status")
+		return pd_errors.Wrap(err, fmt.Sprintf("failed to update Team state for %s", e.Team.Name))
+	}
+
+	e.Logger.Info("Status updated...")
+	return nil
+}
+
+func (e *SubroutineHandler) SetTeamCondition(conditionType v1alpha1.ConditionType, reason string, err error, message string) (pd_utils.OperationResult, error) {
+	conditions := &e.Team.Status.Conditions
+
+	if err != nil {
+		switch class := pd_errors.Classify(err); class {
+		case pd_errors.Unauthorized:
+			return e.status().SetCredentials(err)
+		case pd_errors.Invalid:
+			// the upstream object still has to be released on deletion, whatever the spec
+			if !e.deletionTimestampExists() {
+				return e.status().SetInvalidSpec(err)
+			}
+		case pd_errors.NotFound, pd_errors.Forbidden, pd_errors.RateLimited:
+			reason = string(class)
+		}
+
+		delay := RequeWaitTime
+		if backoff, ok := pd_errors.Backoff(err); ok {
+			delay = backoff
+		}
+
+		e.Logger.Info("Setting ready condition to false", "conditionType", conditionType, "status", metav1.ConditionFalse, "reason", reason, "message", message, "error", err.Error())
+		e.conditionManager.SetCondition(conditions, conditionType, metav1.ConditionFalse, reason, err.Error())
+		e.status().SetError(reason, err)
+
+		err := e.StatusUpdate()
+		if err != nil {
+			e.Logger.Error(err, "Failed to update Team to false ready condition, Requeing in 10 seconds... ")
+		}
+
+		return pd_utils.RequeueAfter(delay, err)
+	}
+
+	if current := e.conditionManager.GetCondition(conditions, conditionType); current != nil && current.Status == metav1.ConditionTrue && current.Message == message {
+		e.status().ClearError()
+		err = e.StatusUpdate()
+		if err != nil {
+			e.Logger.Error(err, "Failed to update Team to true ready condition ")
+			return pd_utils.RequeueAfter(RequeWaitTime, err)
+		}
+		return pd_utils.StopProcessing()
+	}
+
+	e.Logger.Info("Setting ready condition to true", "conditionType", conditionType, "status", metav1.ConditionTrue, "reason", reason, "message", message)
+	e.conditionManager.SetCondition(conditions, conditionType, metav1.ConditionTrue, reason, message)
+	e.status().ClearError()
+	err = e.StatusUpdate()
+
+	return pd_utils.RequeueOnErrorOrStop(err)
+}
+
+// CheckInvalidSpec ends the reconcile of a Team whose spec PagerDuty rejected, before any PagerDuty API call
+func (e *SubroutineHandler) CheckInvalidSpec() (pd_utils.OperationResult, error) {
+	return e.status().CheckInvalidSpec()
+}
+
+// ForgetUpstream drops the upstream Team deleted outside the operator from the status,
+// so the next reconcile creates it again
+func (e *SubroutineHandler) ForgetUpstream() (pd_utils.OperationResult, error) {
+	status := &e.Team.Status
+
+	return e.status().ForgetUpstream(status.TeamID, func() {
+		status.TeamID = ""
+		status.HTMLURL = ""
+		status.Upstream = nil
+		status.Memberships = nil
+	})
+}
+
+func (e *SubroutineHandler) Initialization() (pd_utils.OperationResult, error) {
+	e.Logger.Info("Starting Initialization...")
+	if e.Team.Status.Conditions == nil {
+		e.Team.Status.Conditions = []metav1.Condition{}
+
+		err := e.StatusUpdate()
+		return pd_utils.RequeueOnErrorOrStop(err)
+	}
+
+	e.Logger.Info("Initialization done...")
+	return pd_utils.ContinueProcessing()
+}
+
+func (e *SubroutineHandler) EnsureCredentials() (pd_utils.OperationResult, error) {
+	e.Logger.Info("Ensuring PagerDuty credentials...")
+	return e.status().SetCredentials(e.credentialsErr)
+}
+
+// MarkSynced records the successful comparison with upstream of the Team
+func (e *SubroutineHandler) MarkSynced() (pd_utils.OperationResult, error) {
+	if !e.teamIDExists() {
+		return pd_utils.ContinueProcessing()
+	}
+
+	status := &e.Team.Status
+
+	if e.status().SyncDue(status.Upstream != nil, status.ObservedGeneration, status.LastSyncedTime, e.ResyncInterval) {
+		e.Logger.Info("Recording Team sync with upstream...")
+		PDTeam, err := e.Adapter.GetTeam(status.TeamID)
+		if pd_errors.IsNotFound(err) {
+			return e.ForgetUpstream()
+		}
+		if err != nil {
+			e.Logger.Error(err, "Failed to read upstream Team")
+			e.Events.Failed("Get", err)
+			return e.SetTeamCondition(v1alpha1.ConditionReady, teamReady, err, err.Error())
+		}
+
+		status.HTMLURL = PDTeam.HTMLURL
+		status.Upstream = teamSnapshot(PDTeam)
+		return e.status().MarkSynced(&status.ObservedGeneration, &status.LastSyncedTime)
+	}
+
+	return pd_utils.ContinueProcessing()
+}
@@ -157,14 +157,22 @@ func (id UserID) compareAPIObject(apiObject pagerduty.APIObject) bool {
 	return string(id) == apiObject.ID && "user_reference" == apiObject.Type
 }
 
+// compareAPIObject ignores the order of the targets, since PagerDuty does not preserve it
 func (ids UserIDList) compareAPIObject(apiObject []pagerduty.APIObject) bool {
 	if len(ids) != len(apiObject) {
 		return false
 	}
-	for i, id := range ids {
-		if !id.compareAPIObject(apiObject[i]) {
+
+	pending := make(map[UserID]int, len(ids))
+	for _, id := range ids {
+		pending[id]++
+	}
+	for _, object := range apiObject {
+		id := UserID(object.ID)
+		if pending[id] == 0 || !id.compareAPIObject(object) {
 			return false
 		}
+		pending[id]--
 	}
 	return true
 }
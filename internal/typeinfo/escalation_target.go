@@ -0,0 +1,115 @@
+package typeinfo
+
+import (
+	"encoding/json"
+
+	"github.com/PagerDuty/go-pagerduty"
+)
+
+const (
+	userReferenceType     = "user_reference"
+	scheduleReferenceType = "schedule_reference"
+)
+
+// EscalationTarget is a target of an escalation rule. Exactly one of its fields must be set.
+// ScheduleRef and email selectors have to be resolved to a PagerDuty ID before the target can be sent upstream.
+// +kubebuilder:validation:MinProperties=1
+// +kubebuilder:validation:MaxProperties=1
+type EscalationTarget struct {
+	// UserID references a PagerDuty user by ID, or by email in the form email:<address>
+	UserID string `json:"user_id,omitempty"`
+
+	// ScheduleID references a PagerDuty schedule by ID
+	ScheduleID string `json:"schedule_id,omitempty"`
+
+	// ScheduleRef references a Schedule custom resource in the namespace of the policy by name
+	ScheduleRef string `json:"schedule_ref,omitempty"`
+}
+
+// EscalationTargetList holds the targets of an escalation rule
+type EscalationTargetList []EscalationTarget
+
+// escalationTarget has the fields of EscalationTarget without its UnmarshalJSON
+type escalationTarget EscalationTarget
+
+// UnmarshalJSON reads a target object, or a bare PagerDuty user ID as stored by older versions.
+// The schema only accepts objects, stored bare IDs are migrated by the conversion to v1beta1.
+func (target *EscalationTarget) UnmarshalJSON(data []byte) error {
+	var userID string
+	if err := json.Unmarshal(data, &userID); err == nil {
+		*target = EscalationTarget{UserID: userID}
+		return nil
+	}
+
+	return json.Unmarshal(data, (*escalationTarget)(target))
+}
+
+// Email returns the address of a user referenced by email
+func (target EscalationTarget) Email() (string, bool) {
+	return ParseEmailSelector(target.UserID)
+}
+
+// IsResolved returns true if the target references a PagerDuty object by ID
+func (target EscalationTarget) IsResolved() bool {
+	if _, ok := target.Email(); ok {
+		return false
+	}
+	return target.UserID != "" || target.ScheduleID != ""
+}
+
+// String describes the target for conditions and logs
+func (target EscalationTarget) String() string {
+	switch {
+	case target.UserID != "":
+		return "user " + target.UserID
+	case target.ScheduleID != "":
+		return "schedule " + target.ScheduleID
+	default:
+		return "Schedule " + target.ScheduleRef
+	}
+}
+
+// ToAPIObject converts a resolved target. Unresolved targets convert to an empty object.
+func (target EscalationTarget) ToAPIObject() pagerduty.APIObject {
+	switch {
+	case !target.IsResolved():
+		return pagerduty.APIObject{}
+	case target.UserID != "":
+		return UserID(target.UserID).ToAPIObject()
+	case target.ScheduleID != "":
+		return pagerduty.APIObject{
+			ID:   target.ScheduleID,
+			Type: scheduleReferenceType,
+		}
+	default:
+		return pagerduty.APIObject{}
+	}
+}
+
+func (targets EscalationTargetList) ToAPIObject() []pagerduty.APIObject {
+	objects := make([]pagerduty.APIObject, len(targets))
+	for i, target := range targets {
+		objects[i] = target.ToAPIObject()
+	}
+	return objects
+}
+
+// compareAPIObject ignores the order of the targets, since PagerDuty does not preserve it
+func (targets EscalationTargetList) compareAPIObject(apiObject []pagerduty.APIObject) bool {
+	if len(targets) != len(apiObject) {
+		return false
+	}
+
+	pending := make(map[pagerduty.APIObject]int, len(targets))
+	for _, target := range targets {
+		pending[target.ToAPIObject()]++
+	}
+	for _, object := range apiObject {
+		key := pagerduty.APIObject{ID: object.ID, Type: object.Type}
+		if pending[key] == 0 {
+			return false
+		}
+		pending[key]--
+	}
+	return true
+}
@@ -0,0 +1,61 @@
+package typeinfo
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/yaml"
+)
+
+var _ = Describe("Escalation targets", func() {
+	It("should decode the bare user IDs stored by older versions", func() {
+		rule := K8sEscalationRule{}
+		Expect(yaml.Unmarshal([]byte(`
+escalation_delay_in_minutes: 10
+targets: ["P1NKFZC", "P2MKSDF"]
+`), &rule)).To(Succeed())
+
+		Expect(rule.Targets).To(Equal([]EscalationTarget{{UserID: "P1NKFZC"}, {UserID: "P2MKSDF"}}))
+	})
+
+	It("should decode target objects and mixed lists", func() {
+		rule := K8sEscalationRule{}
+		Expect(yaml.Unmarshal([]byte(`
+targets:
+  - P1NKFZC
+  - schedule_id: PSCHED1
+  - schedule_ref: platform-on-call
+`), &rule)).To(Succeed())
+
+		Expect(rule.Targets).To(Equal([]EscalationTarget{
+			{UserID: "P1NKFZC"},
+			{ScheduleID: "PSCHED1"},
+			{ScheduleRef: "platform-on-call"},
+		}))
+	})
+
+	It("should only send users referenced by ID upstream", func() {
+		byEmail := EscalationTarget{UserID: "email:jane.doe@share-now.com"}
+		Expect(byEmail.IsResolved()).To(BeFalse())
+		Expect(byEmail.ToAPIObject().ID).To(BeEmpty())
+		email, ok := byEmail.Email()
+		Expect(ok).To(BeTrue())
+		Expect(email).To(Equal("jane.doe@share-now.com"))
+
+		byID := EscalationTarget{UserID: "P1NKFZC"}
+		Expect(byID.IsResolved()).To(BeTrue())
+		Expect(byID.ToAPIObject().ID).To(Equal("P1NKFZC"))
+	})
+
+	It("should encode targets as objects", func() {
+		data, err := json.Marshal(EscalationTarget{UserID: "P1NKFZC"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal(`{"user_id":"P1NKFZC"}`))
+	})
+
+	It("should reject targets that are neither a string nor an object", func() {
+		target := EscalationTarget{}
+		Expect(json.Unmarshal([]byte(`42`), &target)).NotTo(Succeed())
+	})
+})
@@ -5,8 +5,12 @@ import (
 )
 
 type K8sEscalationRule struct {
-	Delay   uint       `json:"escalation_delay_in_minutes,omitempty"`
-	Targets UserIDList `json:"targets"`
+	Delay uint `json:"escalation_delay_in_minutes,omitempty"`
+
+	// The targets an incident should be assigned to upon reaching this rule.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=10
+	Targets []EscalationTarget `json:"targets"`
 }
 
 type K8sEscalationRuleList []K8sEscalationRule
@@ -14,7 +18,7 @@ type K8sEscalationRuleList []K8sEscalationRule
 func (rule *K8sEscalationRule) ConvertToPagerDutyObj() pagerduty.EscalationRule {
 	return pagerduty.EscalationRule{
 		Delay:   rule.Delay,
-		Targets: rule.Targets.ToAPIObject(),
+		Targets: EscalationTargetList(rule.Targets).ToAPIObject(),
 	}
 }
 
@@ -28,7 +32,7 @@ func (rules K8sEscalationRuleList) ConvertToPagerDutyObj() []pagerduty.Escalatio
 
 func (rule *K8sEscalationRule) CompareAPIObject(apiObject pagerduty.EscalationRule) bool {
 	return rule.Delay == apiObject.Delay &&
-		rule.Targets.compareAPIObject(apiObject.Targets)
+		EscalationTargetList(rule.Targets).compareAPIObject(apiObject.Targets)
 }
 
 func (rule K8sEscalationRuleList) CompareAPIObject(apiObject []pagerduty.EscalationRule) bool {
@@ -48,7 +52,7 @@ func (in *K8sEscalationRule) DeepCopyInto(out *K8sEscalationRule) {
 	*out = *in
 	if in.Targets != nil {
 		in, out := &in.Targets, &out.Targets
-		*out = make(UserIDList, len(*in))
+		*out = make([]EscalationTarget, len(*in))
 		copy(*out, *in)
 	}
 }
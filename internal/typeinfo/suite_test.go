@@ -0,0 +1,14 @@
+package typeinfo
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestTypeinfo(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "Typeinfo Suite")
+}
@@ -0,0 +1,104 @@
+package typeinfo
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PagerDuty/go-pagerduty"
+)
+
+// EmailSelectorPrefix prefixes fields that reference a PagerDuty user by email, e.g. email:jane.doe@share-now.com
+const EmailSelectorPrefix = "email:"
+
+// ParseEmailSelector returns the email of a selector in the form email:<address>
+func ParseEmailSelector(value string) (string, bool) {
+	if !strings.HasPrefix(value, EmailSelectorPrefix) {
+		return "", false
+	}
+	return strings.TrimSpace(strings.TrimPrefix(value, EmailSelectorPrefix)), true
+}
+
+// FindUserByEmail looks a user up through the PagerDuty ListUsers API.
+// It returns an empty ID when no user has the given email.
+func FindUserByEmail(ctx context.Context, pdClient *pagerduty.Client, email string) (string, error) {
+	res, err := pdClient.ListUsersWithContext(ctx, pagerduty.ListUsersOptions{Query: email})
+	if err != nil {
+		return "", err
+	}
+
+	for _, user := range res.Users {
+		if strings.EqualFold(user.Email, email) {
+			return user.ID, nil
+		}
+	}
+	return "", nil
+}
+
+// UserLookup returns the ID of the user with the given email, or an empty ID if there is none
+type UserLookup func(ctx context.Context, pdClient *pagerduty.Client, email string) (string, error)
+
+type userKey struct {
+	account string
+	email   string
+}
+
+type cachedUser struct {
+	id      string
+	expires time.Time
+}
+
+// UserResolver caches the IDs of the users found by email, per PagerDuty account, for a given TTL.
+// Emails without a user are not cached, so a user created later is picked up on the next lookup.
+type UserResolver struct {
+	ttl    time.Duration
+	now    func() time.Time
+	lookup UserLookup
+
+	mu    sync.Mutex
+	users map[userKey]cachedUser
+}
+
+// NewUserResolver returns a UserResolver looking users up with FindUserByEmail
+func NewUserResolver(ttl time.Duration) *UserResolver {
+	return NewUserResolverWithLookup(ttl, FindUserByEmail)
+}
+
+// NewUserResolverWithLookup returns a UserResolver looking users up with the given lookup, e.g. a fake in tests
+func NewUserResolverWithLookup(ttl time.Duration, lookup UserLookup) *UserResolver {
+	return &UserResolver{
+		ttl:    ttl,
+		now:    time.Now,
+		lookup: lookup,
+		users:  map[userKey]cachedUser{},
+	}
+}
+
+// Resolve returns the ID of the user with the given email in the account of the client. The lookup only runs
+// when the email is not cached or its entry expired. A nil resolver always looks the user up with FindUserByEmail.
+func (r *UserResolver) Resolve(ctx context.Context, pdClient *pagerduty.Client, account string, email string) (string, error) {
+	if r == nil {
+		return FindUserByEmail(ctx, pdClient, email)
+	}
+
+	key := userKey{account: account, email: strings.ToLower(email)}
+
+	r.mu.Lock()
+	cached, ok := r.users[key]
+	r.mu.Unlock()
+	if ok && r.now().Before(cached.expires) {
+		return cached.id, nil
+	}
+
+	id, err := r.lookup(ctx, pdClient, email)
+	if err != nil || id == "" {
+		return id, err
+	}
+
+	r.mu.Lock()
+	r.users[key] = cachedUser{id: id, expires: r.now().Add(r.ttl)}
+	r.mu.Unlock()
+
+	return id, nil
+}
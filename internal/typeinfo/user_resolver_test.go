@@ -0,0 +1,111 @@
+package typeinfo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/PagerDuty/go-pagerduty"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("User resolver", func() {
+	const email = "jane.doe@share-now.com"
+
+	var (
+		resolver *UserResolver
+		now      time.Time
+		lookups  int
+		users    map[string]string
+		failure  error
+	)
+
+	lookup := func(ctx context.Context, pdClient *pagerduty.Client, email string) (string, error) {
+		lookups++
+		return users[email], failure
+	}
+
+	BeforeEach(func() {
+		now = time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+		resolver = NewUserResolverWithLookup(time.Minute, lookup)
+		resolver.now = func() time.Time { return now }
+		lookups = 0
+		users = map[string]string{email: "PJANEDOE"}
+		failure = nil
+	})
+
+	Describe("Parsing email selectors", func() {
+		It("should return the address of email selectors", func() {
+			address, ok := ParseEmailSelector("email:" + email)
+			Expect(ok).To(BeTrue())
+			Expect(address).To(Equal(email))
+		})
+
+		It("should ignore other values", func() {
+			_, ok := ParseEmailSelector("P1NKFZC")
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	It("should cache resolved users until the TTL expires", func() {
+		Expect(resolver.Resolve(context.TODO(), nil, "", email)).To(Equal("PJANEDOE"))
+		Expect(resolver.Resolve(context.TODO(), nil, "", "Jane.Doe@share-now.com")).To(Equal("PJANEDOE"))
+		Expect(lookups).To(Equal(1))
+
+		now = now.Add(2 * time.Minute)
+		Expect(resolver.Resolve(context.TODO(), nil, "", email)).To(Equal("PJANEDOE"))
+		Expect(lookups).To(Equal(2))
+	})
+
+	It("should cache users per account", func() {
+		Expect(resolver.Resolve(context.TODO(), nil, "", email)).To(Equal("PJANEDOE"))
+		Expect(resolver.Resolve(context.TODO(), nil, "other-account", email)).To(Equal("PJANEDOE"))
+		Expect(lookups).To(Equal(2))
+	})
+
+	It("should not cache unknown users or errors", func() {
+		Expect(resolver.Resolve(context.TODO(), nil, "", "nobody@share-now.com")).To(BeEmpty())
+		users["nobody@share-now.com"] = "PNOBODY"
+		Expect(resolver.Resolve(context.TODO(), nil, "", "nobody@share-now.com")).To(Equal("PNOBODY"))
+
+		failure = errors.New("boom")
+		_, err := resolver.Resolve(context.TODO(), nil, "", "broken@share-now.com")
+		Expect(err).To(HaveOccurred())
+		failure = nil
+		Expect(resolver.Resolve(context.TODO(), nil, "", "broken@share-now.com")).To(BeEmpty())
+		Expect(lookups).To(Equal(4))
+	})
+
+	It("should look users up through the API on every call without a resolver", func() {
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			fmt.Fprint(w, `{"users": [{"id": "PJANEDOE", "email": "jane.doe@share-now.com"}]}`)
+		}))
+		defer server.Close()
+
+		client := pagerduty.NewClient("token", pagerduty.WithAPIEndpoint(server.URL))
+		var noResolver *UserResolver
+		Expect(noResolver.Resolve(context.TODO(), client, "", email)).To(Equal("PJANEDOE"))
+		Expect(noResolver.Resolve(context.TODO(), client, "", email)).To(Equal("PJANEDOE"))
+		Expect(requests).To(Equal(2))
+	})
+
+	It("should only match users whose email equals the address", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.URL.Query().Get("query")).To(Equal(email))
+			fmt.Fprint(w, `{"users": [
+				{"id": "PJANEDOE2", "email": "jane.doe2@share-now.com"},
+				{"id": "PJANEDOE", "email": "Jane.Doe@share-now.com"}
+			]}`)
+		}))
+		defer server.Close()
+
+		client := pagerduty.NewClient("token", pagerduty.WithAPIEndpoint(server.URL))
+		Expect(FindUserByEmail(context.TODO(), client, email)).To(Equal("PJANEDOE"))
+	})
+})
@@ -0,0 +1,105 @@
+package webhooks
+
+import (
+	"context"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/typeinfo"
+)
+
+type businessServiceValidator struct {
+	client client.Reader
+}
+
+func (v *businessServiceValidator) newObject() client.Object {
+	return &v1alpha1.BusinessService{}
+}
+
+func (v *businessServiceValidator) spec(obj client.Object) interface{} {
+	return obj.(*v1alpha1.BusinessService).Spec
+}
+
+func (v *businessServiceValidator) validate(ctx context.Context, obj client.Object, old client.Object) (field.ErrorList, []string, error) {
+	businessService := obj.(*v1alpha1.BusinessService)
+
+	errs := validateBusinessService(businessService)
+	if err := validateDeletionPolicy(businessService.Spec.DeletionPolicy); err != nil {
+		errs = append(errs, err)
+	}
+
+	var warnings []string
+	if old != nil {
+		oldBusinessService := old.(*v1alpha1.BusinessService)
+		if err := validateAccountRef(oldBusinessService.Spec.AccountRef, businessService.Spec.AccountRef, businessService.Status.BusinessServiceID); err != nil {
+			errs = append(errs, err)
+		}
+		warnings = adoptWarning(oldBusinessService.Spec.Adopt, businessService.Spec.Adopt, businessService.Status.BusinessServiceID)
+	}
+
+	if businessService.Spec.Name != "" {
+		businessServices := &v1alpha1.BusinessServiceList{}
+		if err := v.client.List(ctx, businessServices); err != nil {
+			return nil, nil, err
+		}
+
+		var others []upstreamName
+		for i := range businessServices.Items {
+			other := &businessServices.Items[i]
+			others = append(others, upstreamName{object: other, account: other.Spec.AccountRef, name: other.Spec.Name})
+		}
+		if err := duplicateName(upstreamName{object: businessService, account: businessService.Spec.AccountRef, name: businessService.Spec.Name}, others); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs, warnings, nil
+}
+
+// validateBusinessService checks the point of contact and the supporting services of the Business Service
+func validateBusinessService(businessService *v1alpha1.BusinessService) field.ErrorList {
+	var errs field.ErrorList
+	spec := &businessService.Spec
+	specPath := field.NewPath("spec")
+
+	if spec.Name == "" {
+		errs = append(errs, field.Required(specPath.Child("name"), "the name of the upstream business service is required"))
+	}
+
+	if email, ok := typeinfo.ParseEmailSelector(spec.PointOfContact); ok && email == "" {
+		errs = append(errs, field.Invalid(specPath.Child("point_of_contact"), spec.PointOfContact, "the email of the user is missing"))
+	}
+
+	seen := make(map[v1alpha1.SupportingServiceReference]bool, len(spec.SupportingServices))
+	for i, ref := range spec.SupportingServices {
+		path := specPath.Child("supporting_services").Index(i)
+
+		namespace, name, found := strings.Cut(ref.Name, "/")
+		if !found {
+			namespace, name = businessService.Namespace, ref.Name
+		}
+		if namespace == "" || name == "" || strings.Contains(name, "/") {
+			errs = append(errs, field.Invalid(path.Child("name"), ref.Name, "must be in the form name or namespace/name"))
+			continue
+		}
+
+		kind := ref.Kind
+		if kind == "" {
+			kind = v1alpha1.DefaultSupportingServiceKind
+		}
+		if kind == v1alpha1.SupportingServiceKindBusinessService && namespace == businessService.Namespace && name == businessService.Name {
+			errs = append(errs, field.Invalid(path.Child("name"), ref.Name, "a Business Service cannot depend on itself"))
+		}
+
+		key := v1alpha1.SupportingServiceReference{Kind: kind, Name: namespace + "/" + name}
+		if seen[key] {
+			errs = append(errs, field.Duplicate(path, kind+" "+key.Name))
+		}
+		seen[key] = true
+	}
+
+	return errs
+}
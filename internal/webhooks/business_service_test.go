@@ -0,0 +1,74 @@
+package webhooks
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
+)
+
+var _ = Describe("BusinessService validation", func() {
+	var businessService *v1alpha1.BusinessService
+
+	BeforeEach(func() {
+		businessService = &v1alpha1.BusinessService{
+			ObjectMeta: metav1.ObjectMeta{Name: "shop", Namespace: "shop"},
+			Spec: v1alpha1.BusinessServiceSpec{
+				Name:           "Shop",
+				PointOfContact: "email:jane.doe@share-now.com",
+				SupportingServices: []v1alpha1.SupportingServiceReference{
+					{Kind: v1alpha1.SupportingServiceKindPagerdutyService, Name: "checkout"},
+					{Kind: v1alpha1.SupportingServiceKindBusinessService, Name: "payments/payments"},
+				},
+			},
+		}
+	})
+
+	validate := func(old client.Object, existing ...client.Object) (field.ErrorList, []string) {
+		validator := &businessServiceValidator{client: newFakeClient(existing...)}
+
+		errs, warnings, err := validator.validate(context.TODO(), businessService, old)
+		Expect(err).NotTo(HaveOccurred())
+		return errs, warnings
+	}
+
+	It("should accept a valid Business Service", func() {
+		errs, _ := validate(nil)
+		Expect(errs).To(BeEmpty())
+	})
+
+	It("should reject duplicate and self referencing supporting services", func() {
+		businessService.Spec.SupportingServices = append(businessService.Spec.SupportingServices,
+			v1alpha1.SupportingServiceReference{Name: "shop/checkout"},
+			v1alpha1.SupportingServiceReference{Kind: v1alpha1.SupportingServiceKindBusinessService, Name: "shop"},
+			v1alpha1.SupportingServiceReference{Name: "a/b/c"},
+		)
+
+		errs, _ := validate(nil)
+		Expect(fieldsOf(errs)).To(ConsistOf(
+			"spec.supporting_services[2]",
+			"spec.supporting_services[3].name",
+			"spec.supporting_services[4].name",
+		))
+	})
+
+	It("should reject a point of contact without email", func() {
+		businessService.Spec.PointOfContact = "email: "
+
+		errs, _ := validate(nil)
+		Expect(fieldsOf(errs)).To(ConsistOf("spec.point_of_contact"))
+	})
+
+	It("should reject a name used by another Business Service of the same account", func() {
+		other := businessService.DeepCopy()
+		other.Namespace = "payments"
+
+		errs, _ := validate(nil, other)
+		Expect(fieldsOf(errs)).To(ConsistOf("spec.name"))
+	})
+})
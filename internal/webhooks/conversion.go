@@ -0,0 +1,26 @@
+package webhooks
+
+import (
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"gitlab.share-now.com/platform/pagerduty-operator/api/v1beta1"
+)
+
+// SetupConversionWithManager registers the conversion webhook of the custom resources, served on /convert.
+// The objects are stored in v1beta1, the conversion hub, while the controllers and older manifests use v1alpha1.
+func SetupConversionWithManager(mgr ctrl.Manager) error {
+	for _, hub := range []client.Object{
+		&v1beta1.EscalationPolicy{},
+		&v1beta1.PagerdutyService{},
+		&v1beta1.BusinessService{},
+		&v1beta1.Schedule{},
+		&v1beta1.Team{},
+		&v1beta1.PagerDutyAccount{},
+	} {
+		if err := ctrl.NewWebhookManagedBy(mgr).For(hub).Complete(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
+	"gitlab.share-now.com/platform/pagerduty-operator/api/v1beta1"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/typeinfo"
+)
+
+type escalationPolicyValidator struct {
+	client client.Reader
+}
+
+func (v *escalationPolicyValidator) newObject() client.Object {
+	return &v1alpha1.EscalationPolicy{}
+}
+
+// escalationPolicySpec is the validated part of an EscalationPolicy. It includes the teams stashed by the
+// conversion from v1beta1, so adding teams through v1beta1 is validated even though the v1alpha1 spec is unchanged.
+type escalationPolicySpec struct {
+	Spec  v1alpha1.EscalationPolicySpec
+	Teams string
+}
+
+func (v *escalationPolicyValidator) spec(obj client.Object) interface{} {
+	policy := obj.(*v1alpha1.EscalationPolicy)
+	return escalationPolicySpec{Spec: policy.Spec, Teams: policy.Annotations[v1alpha1.TeamsAnnotation]}
+}
+
+func (v *escalationPolicyValidator) validate(ctx context.Context, obj client.Object, old client.Object) (field.ErrorList, []string, error) {
+	policy := obj.(*v1alpha1.EscalationPolicy)
+
+	errs := validateEscalationPolicySpec(&policy.Spec)
+	if err := validateTeams(policy); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateDeletionPolicy(policy.Spec.DeletionPolicy); err != nil {
+		errs = append(errs, err)
+	}
+
+	var warnings []string
+	if old != nil {
+		oldPolicy := old.(*v1alpha1.EscalationPolicy)
+		if err := validateAccountRef(oldPolicy.Spec.AccountRef, policy.Spec.AccountRef, policy.Status.PolicyID); err != nil {
+			errs = append(errs, err)
+		}
+		warnings = adoptWarning(oldPolicy.Spec.Adopt, policy.Spec.Adopt, policy.Status.PolicyID)
+	}
+
+	if policy.Spec.Name != "" {
+		policies := &v1alpha1.EscalationPolicyList{}
+		if err := v.client.List(ctx, policies); err != nil {
+			return nil, nil, err
+		}
+
+		var others []upstreamName
+		for i := range policies.Items {
+			other := &policies.Items[i]
+			others = append(others, upstreamName{object: other, account: other.Spec.AccountRef, name: other.Spec.Name})
+		}
+		if err := duplicateName(upstreamName{object: policy, account: policy.Spec.AccountRef, name: policy.Spec.Name}, others); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs, warnings, nil
+}
+
+// validateEscalationPolicySpec checks the escalation rules the way the PagerDuty API does
+func validateEscalationPolicySpec(spec *v1alpha1.EscalationPolicySpec) field.ErrorList {
+	var errs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	if spec.Name == "" {
+		errs = append(errs, field.Required(specPath.Child("name"), "the name of the upstream escalation policy is required"))
+	}
+
+	rulesPath := specPath.Child("escalation_rules")
+	if len(spec.EscalationRules) == 0 {
+		errs = append(errs, field.Required(rulesPath, "at least one escalation rule is required"))
+	}
+
+	for i, rule := range spec.EscalationRules {
+		rulePath := rulesPath.Index(i)
+
+		if rule.Delay < 1 {
+			errs = append(errs, field.Invalid(rulePath.Child("escalation_delay_in_minutes"), rule.Delay, "must be at least 1 minute"))
+		}
+
+		targetsPath := rulePath.Child("targets")
+		switch {
+		case len(rule.Targets) < typeinfo.MinimumEscalationRuleTargets:
+			errs = append(errs, field.Required(targetsPath, "at least one target is required"))
+		case len(rule.Targets) > typeinfo.MaximumEscalationRuleTargets:
+			errs = append(errs, field.TooMany(targetsPath, len(rule.Targets), typeinfo.MaximumEscalationRuleTargets))
+		}
+
+		seen := make(map[typeinfo.EscalationTarget]bool, len(rule.Targets))
+		for j, target := range rule.Targets {
+			targetPath := targetsPath.Index(j)
+			if err := validateEscalationTarget(targetPath, target); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if seen[target] {
+				errs = append(errs, field.Duplicate(targetPath, target.String()))
+			}
+			seen[target] = true
+		}
+	}
+
+	return errs
+}
+
+// validateTeams rejects the policies with several teams in v1beta1, since only one team is reconciled so far
+func validateTeams(policy *v1alpha1.EscalationPolicy) *field.Error {
+	teamsPath := field.NewPath("spec", "teams")
+
+	hub := &v1beta1.EscalationPolicy{}
+	if err := policy.ConvertTo(hub); err != nil {
+		return field.InternalError(teamsPath, err)
+	}
+	if len(hub.Spec.Teams) > 1 {
+		return field.TooMany(teamsPath, len(hub.Spec.Teams), 1)
+	}
+	return nil
+}
+
+// validateEscalationTarget checks that exactly one field of the target is set
+func validateEscalationTarget(path *field.Path, target typeinfo.EscalationTarget) *field.Error {
+	set := 0
+	for _, value := range []string{target.UserID, target.ScheduleID, target.ScheduleRef} {
+		if value != "" {
+			set++
+		}
+	}
+	if set == 1 {
+		return nil
+	}
+	return field.Invalid(path, target, fmt.Sprintf("exactly one of user_id, schedule_id and schedule_ref must be set, found %d", set))
+}
@@ -0,0 +1,110 @@
+package webhooks
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
+	"gitlab.share-now.com/platform/pagerduty-operator/api/v1beta1"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/typeinfo"
+)
+
+func fieldsOf(errs field.ErrorList) []string {
+	var fields []string
+	for _, err := range errs {
+		fields = append(fields, err.Field)
+	}
+	return fields
+}
+
+var _ = Describe("EscalationPolicy validation", func() {
+	var policy *v1alpha1.EscalationPolicy
+
+	BeforeEach(func() {
+		policy = newPolicy("oncall", "platform")
+	})
+
+	validate := func(old client.Object, existing ...client.Object) (field.ErrorList, []string) {
+		validator := &escalationPolicyValidator{client: newFakeClient(existing...)}
+
+		errs, warnings, err := validator.validate(context.TODO(), policy, old)
+		Expect(err).NotTo(HaveOccurred())
+		return errs, warnings
+	}
+
+	It("should accept a valid policy", func() {
+		errs, warnings := validate(nil)
+		Expect(errs).To(BeEmpty())
+		Expect(warnings).To(BeEmpty())
+	})
+
+	It("should reject rules with too many targets", func() {
+		for i := 0; i < typeinfo.MaximumEscalationRuleTargets; i++ {
+			policy.Spec.EscalationRules[0].Targets = append(policy.Spec.EscalationRules[0].Targets,
+				typeinfo.EscalationTarget{ScheduleRef: string(rune('a' + i))})
+		}
+
+		errs, _ := validate(nil)
+		Expect(fieldsOf(errs)).To(ConsistOf("spec.escalation_rules[0].targets"))
+		Expect(errs[0].Type).To(Equal(field.ErrorTypeTooMany))
+	})
+
+	It("should reject a zero delay, duplicate targets and targets with several fields", func() {
+		policy.Spec.EscalationRules = append(policy.Spec.EscalationRules, typeinfo.K8sEscalationRule{
+			Targets: []typeinfo.EscalationTarget{
+				{UserID: "email:jane.doe@share-now.com"},
+				{UserID: "email:jane.doe@share-now.com"},
+				{UserID: "PUSER1", ScheduleID: "PSCHEDULE"},
+			},
+		})
+
+		errs, _ := validate(nil)
+		Expect(fieldsOf(errs)).To(ConsistOf(
+			"spec.escalation_rules[1].escalation_delay_in_minutes",
+			"spec.escalation_rules[1].targets[1]",
+			"spec.escalation_rules[1].targets[2]",
+		))
+	})
+
+	It("should reject a name used by another policy of the same account", func() {
+		other := newPolicy("payments", "platform-copy")
+		other.Spec.Name = policy.Spec.Name
+
+		errs, _ := validate(nil, other)
+		Expect(fieldsOf(errs)).To(ConsistOf("spec.name"))
+
+		other.Spec.AccountRef = "sandbox"
+		errs, _ = validate(nil, other)
+		Expect(errs).To(BeEmpty())
+	})
+
+	It("should forbid moving the policy to another account once created", func() {
+		old := policy.DeepCopy()
+		policy.Status.PolicyID = "PPOLICY"
+		policy.Spec.AccountRef = "sandbox"
+
+		errs, _ := validate(old)
+		Expect(fieldsOf(errs)).To(ConsistOf("spec.account_ref"))
+		Expect(errs[0].Type).To(Equal(field.ErrorTypeForbidden))
+	})
+
+	It("should reject several teams set through v1beta1", func() {
+		hub := &v1beta1.EscalationPolicy{}
+		Expect(policy.ConvertTo(hub)).To(Succeed())
+		hub.Spec.Teams = []v1beta1.TeamReference{{ID: "PTEAM01"}, {Name: "platform"}}
+		Expect(policy.ConvertFrom(hub)).To(Succeed())
+
+		errs, _ := validate(nil)
+		Expect(fieldsOf(errs)).To(ConsistOf("spec.teams"))
+		Expect(errs[0].Type).To(Equal(field.ErrorTypeTooMany))
+
+		hub.Spec.Teams = hub.Spec.Teams[:1]
+		Expect(policy.ConvertFrom(hub)).To(Succeed())
+		errs, _ = validate(nil)
+		Expect(errs).To(BeEmpty())
+	})
+})
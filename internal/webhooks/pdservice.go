@@ -0,0 +1,158 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
+)
+
+type pagerdutyServiceValidator struct {
+	client client.Reader
+}
+
+func (v *pagerdutyServiceValidator) newObject() client.Object {
+	return &v1alpha1.PagerdutyService{}
+}
+
+func (v *pagerdutyServiceValidator) spec(obj client.Object) interface{} {
+	return obj.(*v1alpha1.PagerdutyService).Spec
+}
+
+func (v *pagerdutyServiceValidator) validate(ctx context.Context, obj client.Object, old client.Object) (field.ErrorList, []string, error) {
+	service := obj.(*v1alpha1.PagerdutyService)
+
+	errs := validatePagerdutyServiceSpec(&service.Spec)
+	if err := validateDeletionPolicy(service.Spec.DeletionPolicy); err != nil {
+		errs = append(errs, err)
+	}
+
+	var warnings []string
+	if old != nil {
+		oldService := old.(*v1alpha1.PagerdutyService)
+		if err := validateAccountRef(oldService.Spec.AccountRef, service.Spec.AccountRef, service.Status.ServiceID); err != nil {
+			errs = append(errs, err)
+		}
+		warnings = append(adoptWarning(oldService.Spec.Adopt, service.Spec.Adopt, service.Status.ServiceID),
+			integrationWarnings(service.Status.Integrations, service.Spec.Integrations)...)
+	}
+
+	if service.Spec.EscalationPolicyName != "" {
+		refErr, err := v.validateEscalationPolicyRef(ctx, service)
+		if err != nil {
+			return nil, nil, err
+		}
+		if refErr != nil {
+			errs = append(errs, refErr)
+		}
+	}
+
+	if service.Spec.Name != "" {
+		services := &v1alpha1.PagerdutyServiceList{}
+		if err := v.client.List(ctx, services); err != nil {
+			return nil, nil, err
+		}
+
+		var others []upstreamName
+		for i := range services.Items {
+			other := &services.Items[i]
+			others = append(others, upstreamName{object: other, account: other.Spec.AccountRef, name: other.Spec.Name})
+		}
+		if err := duplicateName(upstreamName{object: service, account: service.Spec.AccountRef, name: service.Spec.Name}, others); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs, warnings, nil
+}
+
+// validateEscalationPolicyRef checks that the referenced EscalationPolicy exists in the namespace of the service
+// and uses the same PagerDuty account
+func (v *pagerdutyServiceValidator) validateEscalationPolicyRef(ctx context.Context, service *v1alpha1.PagerdutyService) (*field.Error, error) {
+	path := field.NewPath("spec", "escalation_policy_ref")
+
+	policy := &v1alpha1.EscalationPolicy{}
+	err := v.client.Get(ctx, types.NamespacedName{Name: service.Spec.EscalationPolicyName, Namespace: service.Namespace}, policy)
+	if apierrors.IsNotFound(err) {
+		return field.NotFound(path, service.Spec.EscalationPolicyName), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if policy.Spec.AccountRef != service.Spec.AccountRef {
+		return field.Invalid(path, service.Spec.EscalationPolicyName,
+			fmt.Sprintf("the escalation policy uses account %q but the service uses account %q", policy.Spec.AccountRef, service.Spec.AccountRef)), nil
+	}
+	return nil, nil
+}
+
+// validatePagerdutyServiceSpec checks the required fields and the integrations of the service
+func validatePagerdutyServiceSpec(spec *v1alpha1.PagerdutyServiceSpec) field.ErrorList {
+	var errs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	if spec.Name == "" {
+		errs = append(errs, field.Required(specPath.Child("name"), "the name of the upstream service is required"))
+	}
+	if spec.EscalationPolicyName == "" {
+		errs = append(errs, field.Required(specPath.Child("escalation_policy_ref"), "the service needs an escalation policy"))
+	}
+
+	names := make(map[string]bool, len(spec.Integrations))
+	secrets := make(map[string]bool, len(spec.Integrations))
+	for i, integration := range spec.Integrations {
+		path := specPath.Child("integrations").Index(i)
+
+		if names[integration.Name] {
+			errs = append(errs, field.Duplicate(path.Child("name"), integration.Name))
+		}
+		names[integration.Name] = true
+
+		switch {
+		case integration.Type == v1alpha1.IntegrationTypeVendor && integration.VendorID == "":
+			errs = append(errs, field.Required(path.Child("vendor_id"), "the vendor type needs a vendor_id"))
+		case integration.Type != v1alpha1.IntegrationTypeVendor && integration.VendorID != "":
+			errs = append(errs, field.Forbidden(path.Child("vendor_id"), "only used by the vendor type"))
+		}
+
+		secretKey := integration.SecretKey
+		if secretKey == "" {
+			secretKey = v1alpha1.DefaultIntegrationSecretKey
+		}
+		secret := integration.SecretName + "/" + secretKey
+		if secrets[secret] {
+			errs = append(errs, field.Duplicate(path.Child("secret_name"),
+				fmt.Sprintf("key %s of Secret %s", secretKey, integration.SecretName)))
+		}
+		secrets[secret] = true
+	}
+
+	return errs
+}
+
+// integrationWarnings reports the integrations created upstream that the spec removes or replaces,
+// since their integration key stops working
+func integrationWarnings(created []v1alpha1.IntegrationStatus, integrations []v1alpha1.ServiceIntegration) []string {
+	desired := make(map[string]v1alpha1.ServiceIntegration, len(integrations))
+	for _, integration := range integrations {
+		desired[integration.Name] = integration
+	}
+
+	var warnings []string
+	for _, current := range created {
+		integration, found := desired[current.Name]
+		switch {
+		case !found:
+			warnings = append(warnings, fmt.Sprintf("integration %s is deleted upstream, its integration key stops working", current.Name))
+		case integration.Vendor() != current.Vendor:
+			warnings = append(warnings, fmt.Sprintf("integration %s changes type and is recreated upstream with a new integration key", current.Name))
+		}
+	}
+	return warnings
+}
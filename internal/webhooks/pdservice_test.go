@@ -0,0 +1,90 @@
+package webhooks
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
+)
+
+var _ = Describe("PagerdutyService validation", func() {
+	var service *v1alpha1.PagerdutyService
+	var policy *v1alpha1.EscalationPolicy
+
+	BeforeEach(func() {
+		policy = newPolicy("shop", "checkout-oncall")
+		service = &v1alpha1.PagerdutyService{
+			ObjectMeta: metav1.ObjectMeta{Name: "checkout", Namespace: "shop"},
+			Spec: v1alpha1.PagerdutyServiceSpec{
+				Name:                 "Checkout",
+				EscalationPolicyName: "checkout-oncall",
+				Integrations: []v1alpha1.ServiceIntegration{
+					{Name: "events", Type: v1alpha1.IntegrationTypeEventsAPIV2, SecretName: "checkout-events"},
+					{Name: "alertmanager", Type: v1alpha1.IntegrationTypePrometheus, SecretName: "checkout-alertmanager"},
+				},
+			},
+		}
+	})
+
+	validate := func(old client.Object, existing ...client.Object) (field.ErrorList, []string) {
+		validator := &pagerdutyServiceValidator{client: newFakeClient(existing...)}
+
+		errs, warnings, err := validator.validate(context.TODO(), service, old)
+		Expect(err).NotTo(HaveOccurred())
+		return errs, warnings
+	}
+
+	It("should accept a valid service", func() {
+		errs, _ := validate(nil, policy)
+		Expect(errs).To(BeEmpty())
+	})
+
+	It("should reject a reference to a missing escalation policy", func() {
+		errs, _ := validate(nil)
+		Expect(fieldsOf(errs)).To(ConsistOf("spec.escalation_policy_ref"))
+		Expect(errs[0].Type).To(Equal(field.ErrorTypeNotFound))
+	})
+
+	It("should reject an escalation policy of another account", func() {
+		policy.Spec.AccountRef = "sandbox"
+
+		errs, _ := validate(nil, policy)
+		Expect(fieldsOf(errs)).To(ConsistOf("spec.escalation_policy_ref"))
+	})
+
+	It("should reject duplicate integrations and a vendor integration without vendor", func() {
+		service.Spec.Integrations = append(service.Spec.Integrations,
+			v1alpha1.ServiceIntegration{Name: "events", Type: v1alpha1.IntegrationTypeVendor, SecretName: "checkout-events"})
+
+		errs, _ := validate(nil, policy)
+		Expect(fieldsOf(errs)).To(ConsistOf(
+			"spec.integrations[2].name",
+			"spec.integrations[2].vendor_id",
+			"spec.integrations[2].secret_name",
+		))
+	})
+
+	It("should warn when an integration is recreated or deleted upstream", func() {
+		service.Status.ServiceID = "PSERVICE"
+		service.Status.Integrations = []v1alpha1.IntegrationStatus{
+			{Name: "events", ID: "PEVENTS", SecretName: "checkout-events"},
+			{Name: "alertmanager", ID: "PALERTMANAGER", Vendor: v1alpha1.PrometheusVendorID, SecretName: "checkout-alertmanager"},
+		}
+		old := service.DeepCopy()
+		service.Spec.Integrations = []v1alpha1.ServiceIntegration{
+			{Name: "events", Type: v1alpha1.IntegrationTypePrometheus, SecretName: "checkout-events"},
+		}
+
+		errs, warnings := validate(old, policy)
+		Expect(errs).To(BeEmpty())
+		Expect(warnings).To(ConsistOf(
+			ContainSubstring("integration events changes type"),
+			ContainSubstring("integration alertmanager is deleted"),
+		))
+	})
+})
@@ -0,0 +1,187 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
+)
+
+// objectValidator validates a kind of custom resource. Validation errors reject the request, while warnings
+// are returned to the client, e.g. when a change replaces an upstream object.
+type objectValidator interface {
+	newObject() client.Object
+	spec(obj client.Object) interface{}
+	validate(ctx context.Context, obj client.Object, old client.Object) (field.ErrorList, []string, error)
+}
+
+// validatingHandler serves a validating webhook with an objectValidator.
+// The CustomValidator of controller-runtime is not used, since it cannot return warnings.
+type validatingHandler struct {
+	kind      schema.GroupKind
+	validator objectValidator
+	decoder   *admission.Decoder
+}
+
+func (h *validatingHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation == admissionv1.Delete {
+		return admission.Allowed("")
+	}
+
+	obj := h.validator.newObject()
+	if err := h.decoder.Decode(req, obj); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	var old client.Object
+	if req.Operation == admissionv1.Update {
+		old = h.validator.newObject()
+		if err := h.decoder.DecodeRaw(req.OldObject, old); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+
+		// resources created before the webhook must still get their finalizer and be deleted
+		if obj.GetDeletionTimestamp() != nil || equality.Semantic.DeepEqual(h.validator.spec(old), h.validator.spec(obj)) {
+			return admission.Allowed("")
+		}
+	}
+
+	errs, warnings, err := h.validator.validate(ctx, obj, old)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	if len(errs) > 0 {
+		invalid := apierrors.NewInvalid(h.kind, obj.GetName(), errs)
+		return admission.Response{AdmissionResponse: admissionv1.AdmissionResponse{
+			Allowed: false,
+			Result:  &invalid.ErrStatus,
+		}}.WithWarnings(warnings...)
+	}
+
+	return admission.Allowed("").WithWarnings(warnings...)
+}
+
+// defaulter applies the spec defaults of the custom resources
+type defaulter struct{}
+
+func (defaulter) Default(_ context.Context, obj runtime.Object) error {
+	switch obj := obj.(type) {
+	case *v1alpha1.EscalationPolicy:
+		obj.Spec.Default()
+	case *v1alpha1.PagerdutyService:
+		obj.Spec.Default()
+	case *v1alpha1.BusinessService:
+		obj.Spec.Default()
+	default:
+		return fmt.Errorf("unexpected object of type %T", obj)
+	}
+	return nil
+}
+
+//+kubebuilder:webhook:path=/mutate-pagerduty-platform-share-now-com-v1alpha1-escalationpolicy,mutating=true,failurePolicy=fail,sideEffects=None,groups=pagerduty.platform.share-now.com,resources=escalationpolicies,verbs=create;update,versions=v1alpha1,name=mescalationpolicy.pagerduty.platform.share-now.com,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-pagerduty-platform-share-now-com-v1alpha1-escalationpolicy,mutating=false,failurePolicy=fail,sideEffects=None,groups=pagerduty.platform.share-now.com,resources=escalationpolicies,verbs=create;update,versions=v1alpha1,name=vescalationpolicy.pagerduty.platform.share-now.com,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/mutate-pagerduty-platform-share-now-com-v1alpha1-pagerdutyservice,mutating=true,failurePolicy=fail,sideEffects=None,groups=pagerduty.platform.share-now.com,resources=pagerdutyservices,verbs=create;update,versions=v1alpha1,name=mpagerdutyservice.pagerduty.platform.share-now.com,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-pagerduty-platform-share-now-com-v1alpha1-pagerdutyservice,mutating=false,failurePolicy=fail,sideEffects=None,groups=pagerduty.platform.share-now.com,resources=pagerdutyservices,verbs=create;update,versions=v1alpha1,name=vpagerdutyservice.pagerduty.platform.share-now.com,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/mutate-pagerduty-platform-share-now-com-v1alpha1-businessservice,mutating=true,failurePolicy=fail,sideEffects=None,groups=pagerduty.platform.share-now.com,resources=businessservices,verbs=create;update,versions=v1alpha1,name=mbusinessservice.pagerduty.platform.share-now.com,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-pagerduty-platform-share-now-com-v1alpha1-businessservice,mutating=false,failurePolicy=fail,sideEffects=None,groups=pagerduty.platform.share-now.com,resources=businessservices,verbs=create;update,versions=v1alpha1,name=vbusinessservice.pagerduty.platform.share-now.com,admissionReviewVersions=v1
+
+// SetupWithManager registers the conversion webhook, and the defaulting and validating webhooks of the
+// EscalationPolicy, PagerdutyService and BusinessService resources
+func SetupWithManager(mgr ctrl.Manager) error {
+	if err := SetupConversionWithManager(mgr); err != nil {
+		return err
+	}
+
+	decoder, err := admission.NewDecoder(mgr.GetScheme())
+	if err != nil {
+		return err
+	}
+
+	for _, hook := range []struct {
+		resource  string
+		kind      string
+		object    client.Object
+		validator objectValidator
+	}{
+		{"escalationpolicy", "EscalationPolicy", &v1alpha1.EscalationPolicy{}, &escalationPolicyValidator{client: mgr.GetClient()}},
+		{"pagerdutyservice", "PagerdutyService", &v1alpha1.PagerdutyService{}, &pagerdutyServiceValidator{client: mgr.GetClient()}},
+		{"businessservice", "BusinessService", &v1alpha1.BusinessService{}, &businessServiceValidator{client: mgr.GetClient()}},
+	} {
+		if err := ctrl.NewWebhookManagedBy(mgr).For(hook.object).WithDefaulter(defaulter{}).Complete(); err != nil {
+			return err
+		}
+
+		mgr.GetWebhookServer().Register("/validate-pagerduty-platform-share-now-com-v1alpha1-"+hook.resource, &webhook.Admission{
+			Handler: &validatingHandler{
+				kind:      schema.GroupKind{Group: v1alpha1.GroupVersion.Group, Kind: hook.kind},
+				validator: hook.validator,
+				decoder:   decoder,
+			},
+		})
+	}
+
+	return nil
+}
+
+// validateDeletionPolicy rejects unknown deletion policies
+func validateDeletionPolicy(policy v1alpha1.DeletionPolicy) *field.Error {
+	if policy == "" || policy.IsValid() {
+		return nil
+	}
+	return field.NotSupported(field.NewPath("spec", "deletion_policy"), policy,
+		[]string{string(v1alpha1.DeletionPolicyDelete), string(v1alpha1.DeletionPolicyOrphan), string(v1alpha1.DeletionPolicyRetain)})
+}
+
+// validateAccountRef rejects moving a resource to another PagerDuty account once its upstream object exists,
+// since the operator would keep looking for the object in the new account
+func validateAccountRef(oldAccount, newAccount, upstreamID string) *field.Error {
+	if upstreamID == "" || oldAccount == newAccount {
+		return nil
+	}
+	return field.Forbidden(field.NewPath("spec", "account_ref"),
+		fmt.Sprintf("cannot be changed once the upstream object %s exists, recreate the resource instead", upstreamID))
+}
+
+// adoptWarning reports that changing adopt has no effect once the upstream object exists
+func adoptWarning(oldAdopt, newAdopt *v1alpha1.AdoptSpec, upstreamID string) []string {
+	if upstreamID == "" || equality.Semantic.DeepEqual(oldAdopt, newAdopt) {
+		return nil
+	}
+	return []string{fmt.Sprintf("spec.adopt is ignored, the resource already manages the upstream object %s", upstreamID)}
+}
+
+// upstreamName is the name of the upstream object of a resource, unique within a PagerDuty account
+type upstreamName struct {
+	object  client.Object
+	account string
+	name    string
+}
+
+// duplicateName reports the resource of the same PagerDuty account already using the name of the upstream object.
+// Resources being deleted are ignored, so a resource can be replaced.
+func duplicateName(self upstreamName, others []upstreamName) *field.Error {
+	for _, other := range others {
+		if other.object.GetNamespace() == self.object.GetNamespace() && other.object.GetName() == self.object.GetName() {
+			continue
+		}
+		if other.object.GetDeletionTimestamp() != nil || other.account != self.account || other.name != self.name {
+			continue
+		}
+		return field.Invalid(field.NewPath("spec", "name"), self.name,
+			fmt.Sprintf("already used by %s/%s in the same PagerDuty account", other.object.GetNamespace(), other.object.GetName()))
+	}
+	return nil
+}
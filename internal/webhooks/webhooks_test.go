@@ -0,0 +1,143 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/typeinfo"
+)
+
+func newScheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+	return scheme
+}
+
+func newFakeClient(objects ...client.Object) client.Client {
+	return fake.NewClientBuilder().WithScheme(newScheme()).WithObjects(objects...).Build()
+}
+
+func newRequest(operation admissionv1.Operation, obj client.Object, old client.Object) admission.Request {
+	req := admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{Operation: operation}}
+
+	raw, err := json.Marshal(obj)
+	Expect(err).NotTo(HaveOccurred())
+	req.Object = runtime.RawExtension{Raw: raw}
+
+	if old != nil {
+		raw, err := json.Marshal(old)
+		Expect(err).NotTo(HaveOccurred())
+		req.OldObject = runtime.RawExtension{Raw: raw}
+	}
+	return req
+}
+
+func newPolicy(namespace, name string) *v1alpha1.EscalationPolicy {
+	return &v1alpha1.EscalationPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: v1alpha1.EscalationPolicySpec{
+			Name: name,
+			EscalationRules: typeinfo.K8sEscalationRuleList{
+				{Delay: 10, Targets: []typeinfo.EscalationTarget{{UserID: "PUSER1"}}},
+			},
+		},
+	}
+}
+
+var _ = Describe("Validating webhook", func() {
+	var handler *validatingHandler
+
+	BeforeEach(func() {
+		decoder, err := admission.NewDecoder(newScheme())
+		Expect(err).NotTo(HaveOccurred())
+
+		handler = &validatingHandler{
+			kind:      schema.GroupKind{Group: v1alpha1.GroupVersion.Group, Kind: "EscalationPolicy"},
+			validator: &escalationPolicyValidator{client: newFakeClient()},
+			decoder:   decoder,
+		}
+	})
+
+	It("should reject an invalid spec with field errors", func() {
+		policy := newPolicy("oncall", "platform")
+		policy.Spec.EscalationRules[0].Delay = 0
+
+		res := handler.Handle(context.TODO(), newRequest(admissionv1.Create, policy, nil))
+		Expect(res.Allowed).To(BeFalse())
+		Expect(res.Result.Reason).To(Equal(metav1.StatusReasonInvalid))
+		Expect(res.Result.Details.Causes).To(ConsistOf(HaveField("Field", "spec.escalation_rules[0].escalation_delay_in_minutes")))
+	})
+
+	It("should allow a valid spec", func() {
+		res := handler.Handle(context.TODO(), newRequest(admissionv1.Create, newPolicy("oncall", "platform"), nil))
+		Expect(res.Allowed).To(BeTrue())
+	})
+
+	It("should allow updates leaving an invalid spec untouched", func() {
+		policy := newPolicy("oncall", "platform")
+		policy.Spec.EscalationRules[0].Delay = 0
+		updated := policy.DeepCopy()
+		updated.Finalizers = []string{"pagerduty.platform.share-now.com/policy"}
+
+		res := handler.Handle(context.TODO(), newRequest(admissionv1.Update, updated, policy))
+		Expect(res.Allowed).To(BeTrue())
+	})
+
+	It("should validate teams added through v1beta1, which leave the v1alpha1 spec untouched", func() {
+		policy := newPolicy("oncall", "platform")
+		policy.Spec.Team = "PTEAM01"
+		updated := policy.DeepCopy()
+		updated.Annotations = map[string]string{v1alpha1.TeamsAnnotation: `[{"id": "PTEAM01"}, {"id": "PTEAM02"}]`}
+
+		res := handler.Handle(context.TODO(), newRequest(admissionv1.Update, updated, policy))
+		Expect(res.Allowed).To(BeFalse())
+		Expect(res.Result.Details.Causes).To(ConsistOf(HaveField("Field", "spec.teams")))
+	})
+
+	It("should return warnings with the response", func() {
+		policy := newPolicy("oncall", "platform")
+		policy.Status.PolicyID = "PPOLICY"
+		updated := policy.DeepCopy()
+		updated.Spec.Adopt = &v1alpha1.AdoptSpec{ID: "POTHER"}
+
+		res := handler.Handle(context.TODO(), newRequest(admissionv1.Update, updated, policy))
+		Expect(res.Allowed).To(BeTrue())
+		Expect(res.Warnings).To(ConsistOf(ContainSubstring("PPOLICY")))
+	})
+})
+
+var _ = Describe("Defaulting webhook", func() {
+	It("should apply the defaults of the CRD schema", func() {
+		service := &v1alpha1.PagerdutyService{Spec: v1alpha1.PagerdutyServiceSpec{
+			Integrations: []v1alpha1.ServiceIntegration{{Name: "events", SecretName: "events"}},
+		}}
+		Expect(defaulter{}.Default(context.TODO(), service)).To(Succeed())
+
+		Expect(service.Spec.Status).To(Equal("active"))
+		Expect(service.Spec.AlertCreation).To(Equal("create_incidents"))
+		Expect(*service.Spec.AutoResolveTimeout).To(Equal(uint(14400)))
+		Expect(*service.Spec.AcknowledgementTimeout).To(Equal(uint(1800)))
+		Expect(service.Spec.Integrations[0].Type).To(Equal(v1alpha1.IntegrationTypeEventsAPIV2))
+		Expect(service.Spec.Integrations[0].SecretKey).To(Equal("integration_key"))
+	})
+
+	It("should keep the values that are set", func() {
+		disabled := uint(0)
+		service := &v1alpha1.PagerdutyService{Spec: v1alpha1.PagerdutyServiceSpec{Status: "disabled", AutoResolveTimeout: &disabled}}
+		Expect(defaulter{}.Default(context.TODO(), service)).To(Succeed())
+
+		Expect(service.Spec.Status).To(Equal("disabled"))
+		Expect(*service.Spec.AutoResolveTimeout).To(BeZero())
+	})
+})
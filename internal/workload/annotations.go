@@ -0,0 +1,51 @@
+package workload
+
+import (
+	"strings"
+
+	"gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// EscalationPolicyAnnotation enables the PagerdutyService of a workload and names the EscalationPolicy
+	// custom resource it references
+	EscalationPolicyAnnotation = "pagerduty.platform.share-now.com/escalation-policy"
+	// ServiceNameAnnotation overrides the name of the upstream service, <namespace>-<workload>-<kind> by default
+	ServiceNameAnnotation = "pagerduty.platform.share-now.com/service-name"
+	// ServiceDescriptionAnnotation sets the description of the upstream service
+	ServiceDescriptionAnnotation = "pagerduty.platform.share-now.com/service-description"
+	// AccountAnnotation sets the PagerDutyAccount of the service
+	AccountAnnotation = "pagerduty.platform.share-now.com/account"
+	// AutoProvisionAnnotation opts a workload out of auto-provisioning when set to "false"
+	AutoProvisionAnnotation = "pagerduty.platform.share-now.com/auto-provision"
+)
+
+// provisioned returns true if the workload asks for a PagerdutyService
+func provisioned(workload client.Object) bool {
+	annotations := workload.GetAnnotations()
+	if annotations[EscalationPolicyAnnotation] == "" {
+		return false
+	}
+	return !strings.EqualFold(annotations[AutoProvisionAnnotation], "false")
+}
+
+// serviceName returns the name of the PagerdutyService of the workload. It includes the kind, so a Deployment
+// and a StatefulSet of the same name get a service each.
+func serviceName(workload client.Object, kind string) string {
+	return workload.GetName() + "-" + strings.ToLower(kind)
+}
+
+// serviceSpec fills the fields of the PagerdutyService spec that are derived from the workload annotations.
+// Other fields keep their current or default value.
+func serviceSpec(workload client.Object, kind string, spec *v1alpha1.PagerdutyServiceSpec) {
+	annotations := workload.GetAnnotations()
+
+	spec.Name = annotations[ServiceNameAnnotation]
+	if spec.Name == "" {
+		spec.Name = workload.GetNamespace() + "-" + serviceName(workload, kind)
+	}
+	spec.Description = annotations[ServiceDescriptionAnnotation]
+	spec.EscalationPolicyName = annotations[EscalationPolicyAnnotation]
+	spec.AccountRef = annotations[AccountAnnotation]
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workload
+
+import (
+	"context"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	pagerdutyalpha1 "gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/k8s_utils"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_utils"
+)
+
+// WorkloadReconciler provisions a PagerdutyService for every workload of one kind carrying the
+// escalation policy annotation. The service is named after the workload and owned by it.
+type WorkloadReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// Kind is the kind of the reconciled workloads, Deployment or StatefulSet
+	Kind string
+	// NewObject returns an empty workload of the reconciled kind
+	NewObject func() client.Object
+}
+
+// NewDeploymentReconciler returns a WorkloadReconciler for Deployments
+func NewDeploymentReconciler(k8sClient client.Client, scheme *runtime.Scheme, recorder record.EventRecorder) *WorkloadReconciler {
+	return &WorkloadReconciler{
+		Client:    k8sClient,
+		Scheme:    scheme,
+		Recorder:  recorder,
+		Kind:      "Deployment",
+		NewObject: func() client.Object { return &appsv1.Deployment{} },
+	}
+}
+
+// NewStatefulSetReconciler returns a WorkloadReconciler for StatefulSets
+func NewStatefulSetReconciler(k8sClient client.Client, scheme *runtime.Scheme, recorder record.EventRecorder) *WorkloadReconciler {
+	return &WorkloadReconciler{
+		Client:    k8sClient,
+		Scheme:    scheme,
+		Recorder:  recorder,
+		Kind:      "StatefulSet",
+		NewObject: func() client.Object { return &appsv1.StatefulSet{} },
+	}
+}
+
+type Subroutines interface {
+	GetService() (pd_utils.OperationResult, error)
+	RemoveService() (pd_utils.OperationResult, error)
+	ReconcileService() (pd_utils.OperationResult, error)
+}
+
+//+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch
+//+kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch
+//+kubebuilder:rbac:groups=pagerduty.platform.share-now.com,resources=pagerdutyservices,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+
+// Reconcile creates, updates or deletes the PagerdutyService of a workload from its annotations.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.14.1/pkg/reconcile
+func (r *WorkloadReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+	log.Info("Starting " + r.Kind + " reconcile...")
+
+	workload := r.NewObject()
+	err := r.Get(ctx, req.NamespacedName, workload)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			// the PagerdutyService is garbage collected together with its owner
+			log.Info(r.Kind + " resource not found. Ignoring since object must be deleted")
+			return k8s_utils.DoNotRequeue()
+		}
+		log.Info("Failed to get " + r.Kind)
+
+		return k8s_utils.RequeueWithError(err)
+	}
+
+	subroutineHandler := &SubroutineHandler{
+		Workload:  workload,
+		Kind:      r.Kind,
+		Logger:    log.WithName(strings.ToLower(r.Kind) + " controller"),
+		K8sClient: r.Client,
+		Recorder:  r.Recorder,
+	}
+
+	return r.ReconcileHandler(subroutineHandler)
+}
+
+type ReconcileOperation func() (pd_utils.OperationResult, error)
+
+func (r *WorkloadReconciler) ReconcileHandler(subroutines Subroutines) (ctrl.Result, error) {
+	operations := []ReconcileOperation{
+		subroutines.GetService,
+		subroutines.RemoveService,
+		subroutines.ReconcileService,
+	}
+	for _, operation := range operations {
+		result, err := operation()
+		if err != nil || result.RequeueRequest {
+			return ctrl.Result{RequeueAfter: result.RequeueDelay}, err
+		}
+		if result.CancelRequest {
+			return ctrl.Result{}, nil
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+// Status updates of the workloads are filtered out, only changes of the annotations matter.
+func (r *WorkloadReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(strings.ToLower(r.Kind)+"-pagerdutyservice").
+		For(r.NewObject(), builder.WithPredicates(predicate.AnnotationChangedPredicate{})).
+		Owns(&pagerdutyalpha1.PagerdutyService{}).
+		Complete(r)
+}
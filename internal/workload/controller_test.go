@@ -0,0 +1,189 @@
+package workload
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
+)
+
+var _ = Describe("Workload controller", func() {
+	var (
+		scheme     *runtime.Scheme
+		k8sClient  client.Client
+		reconciler *WorkloadReconciler
+		deployment *appsv1.Deployment
+	)
+
+	reconcile := func(workload client.Object) (ctrl.Result, error) {
+		return reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(workload)})
+	}
+
+	getService := func(workload client.Object) (*v1alpha1.PagerdutyService, error) {
+		service := &v1alpha1.PagerdutyService{}
+		err := k8sClient.Get(context.TODO(), client.ObjectKey{Namespace: workload.GetNamespace(), Name: serviceName(workload, reconciler.Kind)}, service)
+		return service, err
+	}
+
+	BeforeEach(func() {
+		scheme = runtime.NewScheme()
+		Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+
+		deployment = &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "checkout",
+				Namespace: "shop",
+				UID:       "checkout-uid",
+				Annotations: map[string]string{
+					EscalationPolicyAnnotation:   "shop-oncall",
+					ServiceDescriptionAnnotation: "Checkout of the shop",
+				},
+			},
+		}
+
+		k8sClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(deployment).Build()
+		reconciler = NewDeploymentReconciler(k8sClient, scheme, record.NewFakeRecorder(10))
+	})
+
+	It("Should create a PagerdutyService owned by the Deployment", func() {
+		_, err := reconcile(deployment)
+		Expect(err).NotTo(HaveOccurred())
+
+		service, err := getService(deployment)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(metav1.IsControlledBy(service, deployment)).To(BeTrue())
+		Expect(service.Name).To(Equal("checkout-deployment"))
+		Expect(service.Spec.Name).To(Equal("shop-checkout-deployment"))
+		Expect(service.Spec.Description).To(Equal("Checkout of the shop"))
+		Expect(service.Spec.EscalationPolicyName).To(Equal("shop-oncall"))
+	})
+
+	It("Should update the PagerdutyService when the annotations change", func() {
+		_, err := reconcile(deployment)
+		Expect(err).NotTo(HaveOccurred())
+
+		deployment.Annotations[ServiceNameAnnotation] = "Checkout"
+		deployment.Annotations[EscalationPolicyAnnotation] = "payments-oncall"
+		Expect(k8sClient.Update(context.TODO(), deployment)).To(Succeed())
+
+		_, err = reconcile(deployment)
+		Expect(err).NotTo(HaveOccurred())
+
+		service, err := getService(deployment)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(service.Spec.Name).To(Equal("Checkout"))
+		Expect(service.Spec.EscalationPolicyName).To(Equal("payments-oncall"))
+	})
+
+	It("Should delete the PagerdutyService when the annotation is removed", func() {
+		_, err := reconcile(deployment)
+		Expect(err).NotTo(HaveOccurred())
+
+		delete(deployment.Annotations, EscalationPolicyAnnotation)
+		Expect(k8sClient.Update(context.TODO(), deployment)).To(Succeed())
+
+		_, err = reconcile(deployment)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = getService(deployment)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("Should delete the PagerdutyService when the workload opts out", func() {
+		_, err := reconcile(deployment)
+		Expect(err).NotTo(HaveOccurred())
+
+		deployment.Annotations[AutoProvisionAnnotation] = "false"
+		Expect(k8sClient.Update(context.TODO(), deployment)).To(Succeed())
+
+		_, err = reconcile(deployment)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = getService(deployment)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("Should leave a PagerdutyService it does not own untouched", func() {
+		existing := &v1alpha1.PagerdutyService{
+			ObjectMeta: metav1.ObjectMeta{Name: "checkout-deployment", Namespace: "shop"},
+			Spec:       v1alpha1.PagerdutyServiceSpec{Name: "Manual checkout", EscalationPolicyName: "manual"},
+		}
+		Expect(k8sClient.Create(context.TODO(), existing)).To(Succeed())
+
+		_, err := reconcile(deployment)
+		Expect(err).NotTo(HaveOccurred())
+
+		service, err := getService(deployment)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(service.OwnerReferences).To(BeEmpty())
+		Expect(service.Spec.Name).To(Equal("Manual checkout"))
+
+		delete(deployment.Annotations, EscalationPolicyAnnotation)
+		Expect(k8sClient.Update(context.TODO(), deployment)).To(Succeed())
+
+		_, err = reconcile(deployment)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = getService(deployment)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("Should provision StatefulSets too", func() {
+		statefulSet := &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "orders-db",
+				Namespace:   "shop",
+				UID:         "orders-db-uid",
+				Annotations: map[string]string{EscalationPolicyAnnotation: "shop-oncall", AccountAnnotation: "shop"},
+			},
+		}
+		Expect(k8sClient.Create(context.TODO(), statefulSet)).To(Succeed())
+		reconciler = NewStatefulSetReconciler(k8sClient, scheme, record.NewFakeRecorder(10))
+
+		_, err := reconcile(statefulSet)
+		Expect(err).NotTo(HaveOccurred())
+
+		service, err := getService(statefulSet)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(metav1.IsControlledBy(service, statefulSet)).To(BeTrue())
+		Expect(service.Spec.AccountRef).To(Equal("shop"))
+	})
+
+	It("Should give a Deployment and a StatefulSet of the same name a service each", func() {
+		_, err := reconcile(deployment)
+		Expect(err).NotTo(HaveOccurred())
+
+		statefulSet := &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        deployment.Name,
+				Namespace:   deployment.Namespace,
+				UID:         "checkout-statefulset-uid",
+				Annotations: map[string]string{EscalationPolicyAnnotation: "shop-oncall"},
+			},
+		}
+		Expect(k8sClient.Create(context.TODO(), statefulSet)).To(Succeed())
+		reconciler = NewStatefulSetReconciler(k8sClient, scheme, record.NewFakeRecorder(10))
+
+		_, err = reconcile(statefulSet)
+		Expect(err).NotTo(HaveOccurred())
+
+		service, err := getService(statefulSet)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(metav1.IsControlledBy(service, statefulSet)).To(BeTrue())
+		Expect(service.Spec.Name).To(Equal("shop-checkout-statefulset"))
+
+		services := &v1alpha1.PagerdutyServiceList{}
+		Expect(k8sClient.List(context.TODO(), services)).To(Succeed())
+		Expect(services.Items).To(HaveLen(2))
+	})
+})
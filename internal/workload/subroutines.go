@@ -0,0 +1,86 @@
+package workload
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"gitlab.share-now.com/platform/pagerduty-operator/api/v1alpha1"
+	"gitlab.share-now.com/platform/pagerduty-operator/internal/pd_utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+type SubroutineHandler struct {
+	Workload  client.Object
+	Kind      string
+	Logger    logr.Logger
+	K8sClient client.Client
+	Recorder  record.EventRecorder
+	service   *v1alpha1.PagerdutyService
+}
+
+// GetService looks up the PagerdutyService named after the workload
+func (e *SubroutineHandler) GetService() (pd_utils.OperationResult, error) {
+	service := &v1alpha1.PagerdutyService{}
+	key := client.ObjectKey{Namespace: e.Workload.GetNamespace(), Name: serviceName(e.Workload, e.Kind)}
+	err := e.K8sClient.Get(context.TODO(), key, service)
+	if err != nil {
+		return pd_utils.RequeueOnErrorOrContinue(client.IgnoreNotFound(err))
+	}
+
+	if !metav1.IsControlledBy(service, e.Workload) {
+		e.Logger.Info("PagerdutyService not owned by the workload, skipping...", "service", service.Name)
+		if provisioned(e.Workload) {
+			e.Recorder.Eventf(e.Workload, corev1.EventTypeWarning, "PagerdutyServiceExists",
+				"PagerdutyService %s already exists and is not managed by this %s", service.Name, e.Kind)
+		}
+		return pd_utils.StopProcessing()
+	}
+
+	e.service = service
+	return pd_utils.ContinueProcessing()
+}
+
+// RemoveService deletes the PagerdutyService of workloads that no longer ask for one
+func (e *SubroutineHandler) RemoveService() (pd_utils.OperationResult, error) {
+	if provisioned(e.Workload) && e.Workload.GetDeletionTimestamp().IsZero() {
+		return pd_utils.ContinueProcessing()
+	}
+
+	if e.service == nil {
+		return pd_utils.StopProcessing()
+	}
+
+	e.Logger.Info("Workload no longer provisions a PagerdutyService, deleting it...", "service", e.service.Name)
+	err := client.IgnoreNotFound(e.K8sClient.Delete(context.TODO(), e.service))
+	if err == nil {
+		e.Recorder.Eventf(e.Workload, corev1.EventTypeNormal, "PagerdutyServiceDeleted", "Deleted PagerdutyService %s", e.service.Name)
+	}
+	return pd_utils.RequeueOnErrorOrStop(err)
+}
+
+// ReconcileService creates or updates the PagerdutyService of the workload from its annotations
+func (e *SubroutineHandler) ReconcileService() (pd_utils.OperationResult, error) {
+	service := &v1alpha1.PagerdutyService{}
+	service.SetName(serviceName(e.Workload, e.Kind))
+	service.SetNamespace(e.Workload.GetNamespace())
+
+	result, err := controllerutil.CreateOrUpdate(context.TODO(), e.K8sClient, service, func() error {
+		serviceSpec(e.Workload, e.Kind, &service.Spec)
+		return controllerutil.SetControllerReference(e.Workload, service, e.K8sClient.Scheme())
+	})
+	if err != nil {
+		e.Logger.Error(err, "Failed to sync PagerdutyService", "service", service.Name)
+		return pd_utils.RequeueWithError(fmt.Errorf("failed to sync PagerdutyService %s: %w", service.Name, err))
+	}
+
+	if result != controllerutil.OperationResultNone {
+		e.Logger.Info("PagerdutyService synced", "service", service.Name, "result", result)
+		e.Recorder.Eventf(e.Workload, corev1.EventTypeNormal, "PagerdutyServiceSynced", "PagerdutyService %s %s", service.Name, result)
+	}
+	return pd_utils.StopProcessing()
+}